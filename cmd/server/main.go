@@ -44,7 +44,7 @@ func main() {
 	docService := services.NewService(docRepo, cfg, logger)
 
 	// Setup HTTP router
-	handler := router.NewRouter(docService, logger)
+	handler := router.NewRouter(docService, cfg, logger)
 
 	// Create HTTP server
 	srv := &http.Server{
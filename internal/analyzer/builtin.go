@@ -0,0 +1,528 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+)
+
+// BuiltinModel names the offline analyzer in results and in
+// LLM_FALLBACK_MODELS
+const BuiltinModel = "builtin"
+
+// builtinVersion is recorded as the prompt version of builtin results
+const builtinVersion = "builtin@1"
+
+// builtinSummarySentences is how many sentences a builtin summary has
+const builtinSummarySentences = 3
+
+// minTypeScore is how many distinct keywords of a type a document must
+// contain to be classified as that type
+const minTypeScore = 3
+
+// ErrTranslationUnsupported is returned by the builtin analyzer when asked
+// for a summary in another language or a translation
+var ErrTranslationUnsupported = errors.New("the builtin analyzer cannot translate")
+
+type builtinAnalyzer struct{}
+
+// NewBuiltinAnalyzer returns an analyzer that needs no LLM provider. It
+// summarizes with TextRank sentence extraction, classifies invoices, CVs and
+// contracts by keywords and finds metadata with regular expressions. Results
+// are rougher than a model's but always available.
+func NewBuiltinAnalyzer() Analyzer {
+	return &builtinAnalyzer{}
+}
+
+// IsOffline reports whether a runs entirely on this server, so no text is
+// sent to a provider
+func IsOffline(a Analyzer) bool {
+	_, ok := a.(*builtinAnalyzer)
+	return ok
+}
+
+// typeKeywords are the phrases that identify each document type with its
+// own metadata fields
+var typeKeywords = map[string][]string{
+	"invoice": {
+		"invoice", "invoice number", "bill to", "amount due", "balance due", "subtotal",
+		"due date", "payment terms", "vat", "tax", "total", "qty", "unit price",
+	},
+	"cv": {
+		"curriculum vitae", "resume", "work experience", "professional experience",
+		"employment history", "education", "skills", "references", "objective",
+		"profile", "certifications", "languages",
+	},
+	"contract": {
+		"agreement", "hereby", "whereas", "parties", "terms and conditions", "termination",
+		"terminate", "governing law", "governed by", "shall", "in witness whereof",
+		"effective date", "obligations", "indemnify",
+	},
+}
+
+var typePatterns = map[string][]*regexp.Regexp{}
+
+func init() {
+	for docType, keywords := range typeKeywords {
+		for _, keyword := range keywords {
+			typePatterns[docType] = append(typePatterns[docType],
+				regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword)+`\b`))
+		}
+	}
+}
+
+// classifyByKeywords returns the type whose keywords appear most often, or
+// "other" when no type reaches minTypeScore, with the types that matched
+// some keywords as alternatives. The confidence in a type is its share of
+// all keyword matches, scaled down until it has twice minTypeScore matches;
+// the confidence in other falls as the best type nears minTypeScore.
+func classifyByKeywords(text string) (models.TypeScore, []models.TypeScore) {
+	types := make([]string, 0, len(typePatterns))
+	for docType := range typePatterns {
+		types = append(types, docType)
+	}
+	sort.Strings(types)
+
+	scores := map[string]int{}
+	total := 0
+	for _, docType := range types {
+		for _, pattern := range typePatterns[docType] {
+			if pattern.MatchString(text) {
+				scores[docType]++
+				total++
+			}
+		}
+	}
+
+	confidence := func(docType string) float64 {
+		score := float64(scores[docType])
+		return score / float64(total) * min(1, score/(2*minTypeScore))
+	}
+
+	best := models.TypeScore{DocumentType: "other"}
+	bestScore := 0
+	for _, docType := range types {
+		if scores[docType] > bestScore {
+			best.DocumentType, bestScore = docType, scores[docType]
+		}
+	}
+	if bestScore < minTypeScore {
+		best = models.TypeScore{DocumentType: "other", Confidence: 1 - float64(bestScore)/minTypeScore}
+	} else {
+		best.Confidence = confidence(best.DocumentType)
+	}
+
+	var alternatives []models.TypeScore
+	for _, docType := range types {
+		if docType != best.DocumentType && scores[docType] > 0 {
+			alternatives = append(alternatives, models.TypeScore{DocumentType: docType, Confidence: confidence(docType)})
+		}
+	}
+	return best, alternatives
+}
+
+const monthPattern = `(?:January|February|March|April|May|June|July|August|September|October|November|December|Jan|Feb|Mar|Apr|Jun|Jul|Aug|Sep|Sept|Oct|Nov|Dec)\.?`
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	datePattern  = regexp.MustCompile(`\b(?:\d{4}-\d{2}-\d{2}|\d{1,2}[/-]\d{1,2}[/-]\d{4}|\d{1,2}(?:st|nd|rd|th)? ` +
+		monthPattern + `,? \d{4}|` + monthPattern + ` \d{1,2}(?:st|nd|rd|th)?,? \d{4})\b`)
+	// amountPattern matches numbers written with a currency symbol or code
+	// before or after them
+	amountPattern = regexp.MustCompile(`([$€£¥₹₦]|\b[A-Z]{3}\b|\bK[Ss]hs?\b\.?)\s?(\d[\d.,]*\d|\d)|(\d[\d.,]*\d|\d)\s?([€£$]|\b[A-Z]{3}\b)`)
+	numberPattern = regexp.MustCompile(`\d[\d.,]*\d|\d`)
+	// totalLabel marks the line holding a document's total amount
+	totalLabel         = regexp.MustCompile(`(?i)\b(?:grand total|total due|amount due|balance due|total amount|total)\b`)
+	dueLabel           = regexp.MustCompile(`(?i)\bdue\b`)
+	subtotalLabel      = regexp.MustCompile(`(?i)\bsub-?total\b`)
+	taxLabel           = regexp.MustCompile(`(?i)\b(?:tax|vat)\b`)
+	effectiveLabel     = regexp.MustCompile(`(?i)\beffective\b`)
+	invoiceNumberLabel = regexp.MustCompile(`(?i)\binvoice\s*(?:no\.?|number|#)\s*[:#]?\s*([A-Z0-9][A-Z0-9\-/]*)`)
+	governingLaw       = regexp.MustCompile(`(?i)governed by (?:and construed in accordance with )?the laws? of (?:the )?([A-Z][\w ]*?)(?:[.,;]|$)`)
+)
+
+// amountMatch is an amount found in the text with its currency
+type amountMatch struct {
+	amount   string
+	currency string
+}
+
+// findAmounts returns the amounts in text that have a recognised currency
+func findAmounts(text string) []amountMatch {
+	var found []amountMatch
+	for _, m := range amountPattern.FindAllStringSubmatch(text, -1) {
+		symbol, number := m[1], m[2]
+		if number == "" {
+			symbol, number = m[4], m[3]
+		}
+
+		currency, err := normalizeCurrency(symbol)
+		if err != nil {
+			continue
+		}
+		amount, err := normalizeAmount(number)
+		if err != nil {
+			continue
+		}
+		found = append(found, amountMatch{amount: amount, currency: currency})
+	}
+	return found
+}
+
+// labelledAmount finds the amount on the first line matching label. A bare
+// number is accepted there since the label says what it is.
+func labelledAmount(text string, label *regexp.Regexp) (amountMatch, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		loc := label.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		rest := line[loc[1]:]
+		if amounts := findAmounts(rest); len(amounts) > 0 {
+			return amounts[0], true
+		}
+		if number := numberPattern.FindString(rest); number != "" {
+			if amount, err := normalizeAmount(number); err == nil {
+				return amountMatch{amount: amount}, true
+			}
+		}
+	}
+	return amountMatch{}, false
+}
+
+// largestAmount returns the biggest amount, which is usually the total
+func largestAmount(amounts []amountMatch) (amountMatch, bool) {
+	var best amountMatch
+	var bestValue *big.Rat
+	for _, m := range amounts {
+		value, ok := new(big.Rat).SetString(m.amount)
+		if ok && (bestValue == nil || value.Cmp(bestValue) > 0) {
+			best, bestValue = m, value
+		}
+	}
+	return best, bestValue != nil
+}
+
+// findDates returns the dates in text as YYYY-MM-DD, skipping ambiguous ones
+func findDates(text string) []string {
+	var dates []string
+	for _, m := range datePattern.FindAllString(text, -1) {
+		if date, err := normalizeDate(strings.Replace(m, ".", "", 1)); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// labelledDate returns the first date on a line matching label
+func labelledDate(text string, label *regexp.Regexp) (string, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		if label.MatchString(line) {
+			if dates := findDates(line); len(dates) > 0 {
+				return dates[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+func uniqueEmails(text string) []interface{} {
+	seen := map[string]bool{}
+	emails := []interface{}{}
+	for _, email := range emailPattern.FindAllString(text, -1) {
+		email = strings.ToLower(email)
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// findMetadata fills in what the rules can find of the metadata fields for
+// docType. Other fields are null, so results have the same keys as model
+// output. Email addresses are added under "emails".
+func findMetadata(text, docType string) map[string]interface{} {
+	metadata := map[string]interface{}{}
+
+	if dates := findDates(text); len(dates) > 0 {
+		metadata["date"] = dates[0]
+	}
+
+	amounts := findAmounts(text)
+	total, ok := labelledAmount(text, totalLabel)
+	if !ok {
+		total, ok = largestAmount(amounts)
+	}
+	if ok {
+		metadata["amount"] = total.amount
+		if total.currency == "" && len(amounts) > 0 {
+			total.currency = amounts[0].currency
+		}
+		if total.currency != "" {
+			metadata["currency"] = total.currency
+		}
+	}
+
+	switch docType {
+	case "invoice":
+		if date, ok := labelledDate(text, dueLabel); ok {
+			metadata["due_date"] = date
+		}
+		if m := invoiceNumberLabel.FindStringSubmatch(text); m != nil {
+			metadata["invoice_number"] = m[1]
+		}
+		if subtotal, ok := labelledAmount(text, subtotalLabel); ok {
+			metadata["subtotal"] = subtotal.amount
+		}
+		if tax, ok := labelledAmount(text, taxLabel); ok {
+			metadata["tax"] = tax.amount
+		}
+	case "contract":
+		if date, ok := labelledDate(text, effectiveLabel); ok {
+			metadata["effective_date"] = date
+		}
+		if m := governingLaw.FindStringSubmatch(text); m != nil {
+			metadata["governing_law"] = strings.TrimSpace(m[1])
+		}
+	}
+
+	for _, field := range schemaFor(docType)[2].fields {
+		if _, ok := metadata[field.name]; !ok {
+			metadata[field.name] = nil
+		}
+	}
+	metadata["emails"] = uniqueEmails(text)
+
+	return metadata
+}
+
+func (a *builtinAnalyzer) Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error) {
+	if opts.TargetLanguage != "" && opts.TargetLanguage != opts.SourceLanguage {
+		return nil, ErrTranslationUnsupported
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("document has no text to analyze")
+	}
+
+	class, alternatives := classifyByKeywords(text)
+
+	return &models.LLMAnalysisResult{
+		Summary:       summarizeAs(text, opts.Style, opts.Length),
+		DocumentType:  class.DocumentType,
+		Metadata:      findMetadata(text, class.DocumentType),
+		Model:         BuiltinModel,
+		PromptVersion: builtinVersion,
+		Confidence:    class.Confidence,
+		Alternatives:  alternatives,
+	}, nil
+}
+
+// Extract fills each schema field from a "Field name: value" line when the
+// text has one, then by type: the first date, the total amount and its
+// currency, email addresses for fields named like email. Values that do not
+// validate are dropped, and missing required fields are an error.
+func (a *builtinAnalyzer) Extract(ctx context.Context, text string, schema *models.ExtractionSchema) (*models.ExtractionResult, error) {
+	fields := extractionFields(schema.Fields)
+	metadata := findMetadata(text, "")
+
+	data := make(map[string]interface{}, len(fields))
+	var missing []string
+	for _, field := range fields {
+		var candidates []interface{}
+		if value, ok := labelledValue(text, field.name); ok {
+			if field.kind == kindList {
+				candidates = append(candidates, splitList(value))
+			} else {
+				candidates = append(candidates, value)
+			}
+		}
+
+		isEmail := strings.Contains(field.name, "email")
+		switch {
+		case field.kind == kindList && isEmail:
+			candidates = append(candidates, metadata["emails"])
+		case field.kind == kindList && strings.Contains(field.name, "date"):
+			dates := []interface{}{}
+			for _, date := range findDates(text) {
+				dates = append(dates, date)
+			}
+			candidates = append(candidates, dates)
+		case field.kind == kindString && isEmail:
+			if emails := metadata["emails"].([]interface{}); len(emails) > 0 {
+				candidates = append(candidates, emails[0])
+			}
+		case field.kind == kindDate:
+			candidates = append(candidates, metadata["date"])
+		case field.kind == kindAmount:
+			candidates = append(candidates, metadata["amount"])
+		case field.kind == kindCurrency:
+			candidates = append(candidates, metadata["currency"])
+		}
+
+		data[field.name] = nil
+		for _, candidate := range candidates {
+			if isNull(candidate) {
+				continue
+			}
+			v := &validator{}
+			obj := map[string]interface{}{field.name: candidate}
+			v.object("", []fieldSchema{field}, obj)
+			if len(v.problems) == 0 {
+				data[field.name] = obj[field.name]
+				break
+			}
+		}
+
+		if field.required && data[field.name] == nil {
+			missing = append(missing, field.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("builtin analyzer could not find required fields: %s", strings.Join(missing, ", "))
+	}
+
+	return &models.ExtractionResult{
+		Data:          data,
+		Model:         BuiltinModel,
+		PromptVersion: builtinVersion,
+	}, nil
+}
+
+// labelledValue finds a "Field name: value" line for a field, matching the
+// name with underscores read as spaces
+func labelledValue(text, name string) (string, bool) {
+	label := strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), `[\s_]+`)
+	pattern := regexp.MustCompile(`(?im)^[ \t]*` + label + `[ \t]*[:=\-][ \t]*(\S.*?)[ \t]*$`)
+	if m := pattern.FindStringSubmatch(text); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// splitList reads a labelled value such as "Go, SQL; Docker" as a list
+func splitList(value string) []interface{} {
+	items := []interface{}{}
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Answer quotes the passage sentence that best matches the question by
+// BM25. The answer is the sentence itself, since nothing is generated.
+func (a *builtinAnalyzer) Answer(ctx context.Context, question string, passages []models.Passage) (*models.AnswerResult, error) {
+	var chunks []retrieval.Chunk
+	var passageOf []int
+	for _, passage := range passages {
+		for _, sentence := range splitSentences(passage.Text) {
+			chunks = append(chunks, retrieval.Chunk{Index: len(chunks), Text: sentence})
+			passageOf = append(passageOf, passage.Number)
+		}
+	}
+
+	result := &models.AnswerResult{
+		Answer:        "The document does not appear to answer this question.",
+		Quotes:        []models.Quote{},
+		Model:         BuiltinModel,
+		PromptVersion: builtinVersion,
+	}
+
+	// Stopwords would let any sentence match, so only content words count
+	var terms []string
+	for term := range contentWords(question) {
+		terms = append(terms, term)
+	}
+
+	best := retrieval.NewIndex(chunks).Search(strings.Join(terms, " "), 1)
+	if len(best) == 0 {
+		return result, nil
+	}
+
+	chunk := best[0].Chunk
+	result.Answer = chunk.Text
+	result.Found = true
+	result.Quotes = append(result.Quotes, models.Quote{
+		Passage: passageOf[chunk.Index],
+		Text:    chunk.Text,
+	})
+	return result, nil
+}
+
+// Entities finds entities by pattern, see findEntities
+func (a *builtinAnalyzer) Entities(ctx context.Context, text string) (*models.EntityResult, error) {
+	return &models.EntityResult{
+		Entities:      findEntities(text),
+		Model:         BuiltinModel,
+		PromptVersion: builtinVersion,
+	}, nil
+}
+
+// maxBuiltinChanges bounds how many differences the builtin analyzer lists
+const maxBuiltinChanges = 10
+
+// Compare counts the differences and lists the changed, added and removed
+// paragraphs by their opening words. It cannot tell which changes matter,
+// so changed and removed paragraphs are medium and the rest low.
+func (a *builtinAnalyzer) Compare(ctx context.Context, blocks []models.DiffBlock) (*models.ComparisonResult, error) {
+	counts := map[string]int{}
+	changes := []models.MaterialChange{}
+	for _, block := range blocks {
+		counts[block.Op]++
+
+		var description, significance string
+		switch block.Op {
+		case "changed":
+			description = fmt.Sprintf("Paragraph %d changed from %q to %q", *block.NewIndex+1, excerpt(block.Old), excerpt(block.New))
+			significance = "medium"
+		case "removed":
+			description = fmt.Sprintf("Paragraph %d of the original was removed: %q", *block.OldIndex+1, excerpt(block.Old))
+			significance = "medium"
+		case "added":
+			description = fmt.Sprintf("Paragraph %d was added: %q", *block.NewIndex+1, excerpt(block.New))
+			significance = "low"
+		default:
+			continue
+		}
+		if len(changes) < maxBuiltinChanges {
+			changes = append(changes, models.MaterialChange{Description: description, Significance: significance})
+		}
+	}
+
+	var parts []string
+	for _, op := range []string{"changed", "added", "removed", "moved"} {
+		if n := counts[op]; n > 0 {
+			noun := "paragraphs"
+			if n == 1 {
+				noun = "paragraph"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s %s", n, noun, op))
+		}
+	}
+	summary := "The documents have the same text."
+	if len(parts) > 0 {
+		summary = "The revision has " + joinList(parts) + "."
+	}
+
+	return &models.ComparisonResult{
+		Summary:       summary,
+		Changes:       changes,
+		Model:         BuiltinModel,
+		PromptVersion: builtinVersion,
+	}, nil
+}
+
+// Translate is not supported without an LLM provider
+func (a *builtinAnalyzer) Translate(ctx context.Context, paragraphs []string, opts Options) (*models.TranslationResult, error) {
+	return nil, ErrTranslationUnsupported
+}
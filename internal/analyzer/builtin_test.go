@@ -0,0 +1,227 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+const sampleInvoice = `INVOICE
+
+Invoice No: INV-2024-017
+Date: 5 March 2024
+Due Date: 2024-04-04
+
+Bill To: Beta Corp, accounts@beta.example
+
+Consulting services, 10 hours        USD 1,200.00
+Subtotal: 1,200.00
+VAT: 192.00
+Total Due: $1,392.00
+
+Payment terms: 30 days. Questions to billing@acme.example.`
+
+const sampleContract = `SERVICE AGREEMENT
+
+This agreement is made between Acme Ltd and Beta Corp. It is effective from January 1, 2024.
+
+Whereas Acme Ltd provides consulting services and Beta Corp wishes to engage those services, the parties agree as follows.
+
+Beta Corp shall pay Acme Ltd a monthly fee of €5,000 for the consulting services. Either party may terminate this agreement with 60 days written notice.
+
+This agreement is governed by the laws of Kenya.`
+
+func TestBuiltinAnalyzeInvoice(t *testing.T) {
+	result, err := NewBuiltinAnalyzer().Analyze(context.Background(), sampleInvoice, Options{SourceLanguage: "en"})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	if result.DocumentType != "invoice" {
+		t.Errorf("document_type = %q, want invoice", result.DocumentType)
+	}
+	if result.Model != BuiltinModel || result.PromptVersion != builtinVersion {
+		t.Errorf("model = %q, prompt_version = %q", result.Model, result.PromptVersion)
+	}
+
+	want := map[string]interface{}{
+		"date":           "2024-03-05",
+		"due_date":       "2024-04-04",
+		"invoice_number": "INV-2024-017",
+		"amount":         "1392.00",
+		"currency":       "USD",
+		"subtotal":       "1200.00",
+		"tax":            "192.00",
+		"sender":         nil,
+	}
+	for key, value := range want {
+		got, ok := result.Metadata[key]
+		if !ok || got != value {
+			t.Errorf("metadata[%q] = %v, want %v", key, got, value)
+		}
+	}
+
+	emails, _ := result.Metadata["emails"].([]interface{})
+	if len(emails) != 2 || emails[0] != "accounts@beta.example" {
+		t.Errorf("emails = %v, want both addresses", emails)
+	}
+}
+
+func TestBuiltinAnalyzeContract(t *testing.T) {
+	result, err := NewBuiltinAnalyzer().Analyze(context.Background(), sampleContract, Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	if result.DocumentType != "contract" {
+		t.Errorf("document_type = %q, want contract", result.DocumentType)
+	}
+	if got := result.Metadata["effective_date"]; got != "2024-01-01" {
+		t.Errorf("effective_date = %v, want 2024-01-01", got)
+	}
+	if got := result.Metadata["governing_law"]; got != "Kenya" {
+		t.Errorf("governing_law = %v, want Kenya", got)
+	}
+	if result.Metadata["amount"] != "5000.00" || result.Metadata["currency"] != "EUR" {
+		t.Errorf("amount = %v %v, want 5000.00 EUR", result.Metadata["amount"], result.Metadata["currency"])
+	}
+
+	// The summary is made of whole sentences of the document
+	sentences := splitSentences(result.Summary)
+	if len(sentences) != builtinSummarySentences {
+		t.Fatalf("summary has %d sentences, want %d: %q", len(sentences), builtinSummarySentences, result.Summary)
+	}
+	flat := strings.Join(strings.Fields(sampleContract), " ")
+	for _, sentence := range sentences {
+		if !strings.Contains(flat, sentence) {
+			t.Errorf("summary sentence %q is not in the document", sentence)
+		}
+	}
+}
+
+func TestBuiltinAnalyzeRejectsTranslation(t *testing.T) {
+	_, err := NewBuiltinAnalyzer().Analyze(context.Background(), sampleContract, Options{SourceLanguage: "en", TargetLanguage: "fr"})
+	if !errors.Is(err, ErrTranslationUnsupported) {
+		t.Errorf("error = %v, want ErrTranslationUnsupported", err)
+	}
+}
+
+func TestClassifyByKeywords(t *testing.T) {
+	cv := "Curriculum Vitae\n\nProfile: backend engineer.\n\nWork Experience\nAcme Ltd, 2019-2024\n\nEducation\nBSc Computer Science\n\nSkills: Go, SQL"
+
+	tests := map[string]string{
+		sampleInvoice:  "invoice",
+		sampleContract: "contract",
+		cv:             "cv",
+		"Dear Jane, see you at the meeting on Friday.": "other",
+	}
+	for text, want := range tests {
+		if got, _ := classifyByKeywords(text); got.DocumentType != want {
+			t.Errorf("classifyByKeywords(%.30q) = %q, want %q", text, got.DocumentType, want)
+		}
+	}
+
+	class, alternatives := classifyByKeywords(sampleInvoice)
+	if class.Confidence < 0.7 || class.Confidence > 1 {
+		t.Errorf("invoice confidence = %v, want a confident classification", class.Confidence)
+	}
+	for _, alt := range alternatives {
+		if alt.DocumentType == "invoice" || alt.Confidence >= class.Confidence {
+			t.Errorf("alternative %+v should rank below the invoice", alt)
+		}
+	}
+
+	// Two invoice keywords are not enough for a type, or for certainty
+	// that it is none
+	class, _ = classifyByKeywords("Please pay the total by the due date.")
+	if class.DocumentType != "other" || class.Confidence >= 0.5 {
+		t.Errorf("classification = %+v, want other with low confidence", class)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	text := "Dr. Smith met Mr. J. Doe of Acme Inc. and Beta Ltd. on 1. March. They work at Acme Inc. They signed the\nagreement! Did it work?\n\nNew paragraph without a stop"
+	want := []string{
+		"Dr. Smith met Mr. J. Doe of Acme Inc. and Beta Ltd. on 1. March.",
+		"They work at Acme Inc.",
+		"They signed the agreement!",
+		"Did it work?",
+		"New paragraph without a stop",
+	}
+
+	got := splitSentences(text)
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences %q, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sentence %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuiltinExtract(t *testing.T) {
+	schema := &models.ExtractionSchema{
+		Name: "invoice_basics",
+		Fields: []models.SchemaField{
+			{Name: "invoice_no", Type: models.FieldString, Required: true},
+			{Name: "due_date", Type: models.FieldDate},
+			{Name: "total", Type: models.FieldAmount},
+			{Name: "currency", Type: models.FieldCurrency},
+			{Name: "contact_emails", Type: models.FieldList},
+			{Name: "purchase_order", Type: models.FieldString},
+		},
+	}
+
+	result, err := NewBuiltinAnalyzer().Extract(context.Background(), sampleInvoice, schema)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"invoice_no":     "INV-2024-017",
+		"due_date":       "2024-04-04",
+		"total":          "1392.00",
+		"currency":       "USD",
+		"purchase_order": nil,
+	}
+	for key, value := range want {
+		if got := result.Data[key]; got != value {
+			t.Errorf("%s = %v, want %v", key, got, value)
+		}
+	}
+	if emails, _ := result.Data["contact_emails"].([]interface{}); len(emails) != 2 {
+		t.Errorf("contact_emails = %v, want two addresses", result.Data["contact_emails"])
+	}
+
+	schema.Fields = append(schema.Fields, models.SchemaField{Name: "iban", Type: models.FieldString, Required: true})
+	if _, err := NewBuiltinAnalyzer().Extract(context.Background(), sampleInvoice, schema); err == nil {
+		t.Error("Extract succeeded without a required field")
+	}
+}
+
+func TestBuiltinAnswer(t *testing.T) {
+	passages := []models.Passage{
+		{Number: 1, Text: "This agreement is made between Acme Ltd and Beta Corp."},
+		{Number: 2, Text: "Either party may terminate this agreement with 60 days written notice. Notice must be in writing."},
+	}
+
+	result, err := NewBuiltinAnalyzer().Answer(context.Background(), "How much notice is needed to terminate?", passages)
+	if err != nil {
+		t.Fatalf("Answer returned error: %v", err)
+	}
+	if !result.Found || len(result.Quotes) != 1 {
+		t.Fatalf("result = %+v, want one quote", result)
+	}
+	if q := result.Quotes[0]; q.Passage != 2 || !strings.Contains(q.Text, "60 days") {
+		t.Errorf("quote = %+v, want the termination sentence of passage 2", q)
+	}
+
+	result, err = NewBuiltinAnalyzer().Answer(context.Background(), "What colour is the logo?", passages)
+	if err != nil || result.Found || len(result.Quotes) != 0 {
+		t.Errorf("result = %+v, %v; want not found", result, err)
+	}
+}
@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// CacheStore keeps cached analyses; the repository implements it
+type CacheStore interface {
+	// GetCachedAnalysis returns the unexpired entry with key, or nil
+	GetCachedAnalysis(ctx context.Context, key string, now time.Time) (*models.CacheEntry, error)
+	// PutCachedAnalysis stores an entry, then drops expired entries and the
+	// least recently used ones beyond maxEntries or maxBytes of results
+	PutCachedAnalysis(ctx context.Context, entry *models.CacheEntry, maxEntries int, maxBytes int64) error
+}
+
+// CacheConfig configures the analysis cache. Model and PromptVersion
+// identify what produces results, so changing the model chain or a
+// template starts over with an empty cache.
+type CacheConfig struct {
+	Store         CacheStore
+	Model         string
+	PromptVersion string
+	TTL           time.Duration
+	MaxEntries    int
+	// MaxBytes bounds the total size of the stored results
+	MaxBytes int64
+}
+
+// CacheReporter is implemented by analyzers that cache results
+type CacheReporter interface {
+	// CacheStats returns the cache hits and misses since the server started
+	CacheStats() (hits, misses int64)
+}
+
+// cachingAnalyzer answers Analyze from the cache when the same text was
+// analyzed with the same model, templates and options, unless NoCache is
+// set. Every other method goes straight to the wrapped analyzer.
+type cachingAnalyzer struct {
+	Analyzer
+	cfg    CacheConfig
+	hits   atomic.Int64
+	misses atomic.Int64
+	logger *utils.Logger
+}
+
+// cachedResult is the stored form of an analysis result, which keeps the
+// fields LLMAnalysisResult leaves out of JSON
+type cachedResult struct {
+	Summary       string                 `json:"summary"`
+	DocumentType  string                 `json:"document_type"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	Model         string                 `json:"model"`
+	PromptVersion string                 `json:"prompt_version"`
+	Confidence    float64                `json:"confidence"`
+	Alternatives  []models.TypeScore     `json:"alternatives,omitempty"`
+}
+
+// NewCachingAnalyzer puts a cache in front of next's Analyze. Lookups and
+// stores that fail are logged and the analysis runs as if uncached.
+func NewCachingAnalyzer(next Analyzer, cfg CacheConfig, logger *utils.Logger) Analyzer {
+	return &cachingAnalyzer{Analyzer: next, cfg: cfg, logger: logger}
+}
+
+func (a *cachingAnalyzer) Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error) {
+	return a.cached(ctx, text, opts, func() (*models.LLMAnalysisResult, error) {
+		return a.Analyzer.Analyze(ctx, text, opts)
+	})
+}
+
+// AnalyzeStream streams misses; hits return at once without calling sink
+func (a *cachingAnalyzer) AnalyzeStream(ctx context.Context, text string, opts Options, sink TokenSink) (*models.LLMAnalysisResult, error) {
+	return a.cached(ctx, text, opts, func() (*models.LLMAnalysisResult, error) {
+		if streamer, ok := a.Analyzer.(StreamAnalyzer); ok {
+			return streamer.AnalyzeStream(ctx, text, opts, sink)
+		}
+		return a.Analyzer.Analyze(ctx, text, opts)
+	})
+}
+
+func (a *cachingAnalyzer) ProviderStatus() []models.ProviderStatus {
+	if reporter, ok := a.Analyzer.(StatusReporter); ok {
+		return reporter.ProviderStatus()
+	}
+	return nil
+}
+
+func (a *cachingAnalyzer) CacheStats() (int64, int64) {
+	return a.hits.Load(), a.misses.Load()
+}
+
+func (a *cachingAnalyzer) cached(ctx context.Context, text string, opts Options, analyze func() (*models.LLMAnalysisResult, error)) (*models.LLMAnalysisResult, error) {
+	key := a.key(text, opts)
+	now := time.Now()
+
+	if !opts.NoCache {
+		if result := a.lookup(ctx, key, now); result != nil {
+			a.hits.Add(1)
+			return result, nil
+		}
+		a.misses.Add(1)
+	}
+
+	result, err := analyze()
+	if err != nil {
+		return nil, err
+	}
+	// A builtin result means every model in the chain failed; caching it
+	// would keep serving the degraded analysis after the models recover
+	if result.Model == BuiltinModel {
+		return result, nil
+	}
+
+	data, err := json.Marshal(cachedResult{
+		Summary:       result.Summary,
+		DocumentType:  result.DocumentType,
+		Metadata:      result.Metadata,
+		Model:         result.Model,
+		PromptVersion: result.PromptVersion,
+		Confidence:    result.Confidence,
+		Alternatives:  result.Alternatives,
+	})
+	if err != nil {
+		a.logger.Warn("Failed to encode analysis for the cache", "error", err)
+		return result, nil
+	}
+
+	entry := &models.CacheEntry{
+		Key:           key,
+		Model:         a.cfg.Model,
+		PromptVersion: a.cfg.PromptVersion,
+		Result:        string(data),
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(a.cfg.TTL),
+	}
+	if err := a.cfg.Store.PutCachedAnalysis(ctx, entry, a.cfg.MaxEntries, a.cfg.MaxBytes); err != nil {
+		a.logger.Warn("Failed to write analysis cache", "error", err)
+	}
+	return result, nil
+}
+
+// lookup returns the cached result stored under key, or nil when there is
+// none or it cannot be read
+func (a *cachingAnalyzer) lookup(ctx context.Context, key string, now time.Time) *models.LLMAnalysisResult {
+	entry, err := a.cfg.Store.GetCachedAnalysis(ctx, key, now)
+	if err != nil {
+		a.logger.Warn("Failed to read analysis cache", "error", err)
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+
+	var stored cachedResult
+	if err := json.Unmarshal([]byte(entry.Result), &stored); err != nil {
+		a.logger.Warn("Failed to decode cached analysis", "error", err, "key", key)
+		return nil
+	}
+	if stored.Model == BuiltinModel {
+		return nil
+	}
+	return &models.LLMAnalysisResult{
+		Summary:       stored.Summary,
+		DocumentType:  stored.DocumentType,
+		Metadata:      stored.Metadata,
+		Model:         stored.Model,
+		PromptVersion: stored.PromptVersion,
+		Confidence:    stored.Confidence,
+		Alternatives:  stored.Alternatives,
+		CacheHit:      true,
+	}
+}
+
+// key hashes everything that decides an analysis result: the text, the
+// model chain, the templates and the options
+func (a *cachingAnalyzer) key(text string, opts Options) string {
+	h := sha256.New()
+	for _, part := range []string{
+		text, a.cfg.Model, a.cfg.PromptVersion,
+		opts.SourceLanguage, opts.TargetLanguage, opts.Style, opts.Length,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
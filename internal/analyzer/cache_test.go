@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// memoryCache is a CacheStore that keeps entries in a map
+type memoryCache struct {
+	entries map[string]*models.CacheEntry
+}
+
+func (c *memoryCache) GetCachedAnalysis(ctx context.Context, key string, now time.Time) (*models.CacheEntry, error) {
+	entry, ok := c.entries[key]
+	if !ok || !entry.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+func (c *memoryCache) PutCachedAnalysis(ctx context.Context, entry *models.CacheEntry, maxEntries int, maxBytes int64) error {
+	c.entries[entry.Key] = entry
+	return nil
+}
+
+// countingAnalyzer answers like the builtin analyzer under a model's name,
+// counting calls. With degraded set it answers as the builtin analyzer, as
+// a chain does when every model failed.
+type countingAnalyzer struct {
+	*builtinAnalyzer
+	calls    int
+	degraded bool
+}
+
+func (a *countingAnalyzer) Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error) {
+	a.calls++
+	result, err := a.builtinAnalyzer.Analyze(ctx, text, opts)
+	if err == nil && !a.degraded {
+		result.Model = "test/model"
+		result.Usage = models.TokenUsage{PromptTokens: 100, CompletionTokens: 20}
+	}
+	return result, err
+}
+
+func newTestCache(ttl time.Duration) (*countingAnalyzer, *memoryCache, Analyzer) {
+	next := &countingAnalyzer{builtinAnalyzer: NewBuiltinAnalyzer().(*builtinAnalyzer)}
+	store := &memoryCache{entries: map[string]*models.CacheEntry{}}
+	a := NewCachingAnalyzer(next, CacheConfig{
+		Store:         store,
+		Model:         "openrouter:test/model",
+		PromptVersion: "classify@1,default@1",
+		TTL:           ttl,
+		MaxEntries:    10,
+		MaxBytes:      1 << 20,
+	}, utils.NewLogger("error"))
+	return next, store, a
+}
+
+const cachedText = "INVOICE\nInvoice Number: INV-7\nTotal Due: $120.00"
+
+func TestCacheAnswersRepeatedAnalysis(t *testing.T) {
+	next, _, a := newTestCache(time.Hour)
+
+	first, err := a.Analyze(context.Background(), cachedText, Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if first.CacheHit {
+		t.Errorf("first analysis reported a cache hit")
+	}
+
+	second, err := a.Analyze(context.Background(), cachedText, Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("analyzer called %d times, want 1", next.calls)
+	}
+	if !second.CacheHit {
+		t.Errorf("second analysis was not a cache hit")
+	}
+	if second.Summary != first.Summary || second.DocumentType != first.DocumentType {
+		t.Errorf("cached result differs: %+v vs %+v", second, first)
+	}
+	if second.Usage != (models.TokenUsage{}) {
+		t.Errorf("cache hit used tokens: %+v", second.Usage)
+	}
+
+	hits, misses := a.(CacheReporter).CacheStats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("stats = %d hits, %d misses, want 1 and 1", hits, misses)
+	}
+}
+
+func TestCacheKeyCoversTextAndOptions(t *testing.T) {
+	next, _, a := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	a.Analyze(ctx, cachedText, Options{})
+	a.Analyze(ctx, cachedText+" ", Options{})
+	a.Analyze(ctx, cachedText, Options{Style: models.SummaryBullets})
+	a.Analyze(ctx, cachedText, Options{Length: models.SummaryLong})
+
+	if next.calls != 4 {
+		t.Errorf("analyzer called %d times, want 4", next.calls)
+	}
+}
+
+func TestCacheIgnoresExpiredEntries(t *testing.T) {
+	next, store, a := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	a.Analyze(ctx, cachedText, Options{})
+	for _, entry := range store.entries {
+		entry.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+	a.Analyze(ctx, cachedText, Options{})
+
+	if next.calls != 2 {
+		t.Errorf("analyzer called %d times, want 2", next.calls)
+	}
+}
+
+func TestCacheHitDoesNotStream(t *testing.T) {
+	_, _, a := newTestCache(time.Hour)
+	streamer := a.(StreamAnalyzer)
+
+	first := &recordingSink{}
+	if _, err := streamer.AnalyzeStream(context.Background(), cachedText, Options{}, first); err != nil {
+		t.Fatalf("AnalyzeStream returned error: %v", err)
+	}
+
+	second := &recordingSink{}
+	result, err := streamer.AnalyzeStream(context.Background(), cachedText, Options{}, second)
+	if err != nil {
+		t.Fatalf("AnalyzeStream returned error: %v", err)
+	}
+	if !result.CacheHit {
+		t.Errorf("second analysis was not a cache hit")
+	}
+	if len(second.tokens) != 0 || len(second.restarts) != 0 {
+		t.Errorf("cache hit streamed to the sink: %+v", second)
+	}
+}
+
+func TestCacheNoCacheRunsAndRefreshesEntry(t *testing.T) {
+	next, store, a := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	a.Analyze(ctx, cachedText, Options{})
+	for _, entry := range store.entries {
+		entry.Result = `{"summary":"stale","document_type":"memo"}`
+	}
+
+	forced, err := a.Analyze(ctx, cachedText, Options{NoCache: true})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if next.calls != 2 || forced.CacheHit {
+		t.Errorf("forced analysis used the cache: %d calls, hit %v", next.calls, forced.CacheHit)
+	}
+
+	cached, _ := a.Analyze(ctx, cachedText, Options{})
+	if !cached.CacheHit || cached.Summary != forced.Summary {
+		t.Errorf("forced result was not stored: %+v", cached)
+	}
+	if hits, misses := a.(CacheReporter).CacheStats(); hits != 1 || misses != 1 {
+		t.Errorf("stats = %d hits, %d misses, want 1 and 1", hits, misses)
+	}
+}
+
+func TestCacheSkipsBuiltinFallbackResults(t *testing.T) {
+	next, store, a := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	next.degraded = true
+	a.Analyze(ctx, cachedText, Options{})
+	if len(store.entries) != 0 {
+		t.Fatalf("builtin result was cached")
+	}
+
+	next.degraded = false
+	result, _ := a.Analyze(ctx, cachedText, Options{})
+	if result.CacheHit || result.Model != "test/model" {
+		t.Errorf("model result = %+v, want a fresh model analysis", result)
+	}
+	if next.calls != 2 {
+		t.Errorf("analyzer called %d times, want 2", next.calls)
+	}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
+)
+
+// maxAlternatives is how many runner-up types a classification keeps
+const maxAlternatives = 2
+
+// MapClassification maps a classification onto the taxonomy. A type the
+// taxonomy does not know becomes other with no confidence, since the
+// classifier was not sure of any allowed type, which routes the document
+// for review. Alternatives are mapped the same way, merged when they map to
+// the same type, and dropped when they are other or the chosen type.
+func MapClassification(t *taxonomy.Taxonomy, primary models.TypeScore, alternatives []models.TypeScore) (models.TypeScore, []models.TypeScore) {
+	docType, ok := t.Map(primary.DocumentType)
+	mapped := models.TypeScore{DocumentType: docType, Confidence: clampConfidence(primary.Confidence)}
+	if !ok {
+		mapped.Confidence = 0
+	}
+
+	best := map[string]float64{}
+	for _, alt := range alternatives {
+		altType, ok := t.Map(alt.DocumentType)
+		if !ok || altType == taxonomy.Other || altType == mapped.DocumentType {
+			continue
+		}
+		best[altType] = max(best[altType], clampConfidence(alt.Confidence))
+	}
+
+	kept := make([]models.TypeScore, 0, len(best))
+	for altType, confidence := range best {
+		kept = append(kept, models.TypeScore{DocumentType: altType, Confidence: confidence})
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Confidence != kept[j].Confidence {
+			return kept[i].Confidence > kept[j].Confidence
+		}
+		return kept[i].DocumentType < kept[j].DocumentType
+	})
+	if len(kept) > maxAlternatives {
+		kept = kept[:maxAlternatives]
+	}
+	return mapped, kept
+}
+
+// parseClassification reads the classifier's answer. Confidence is a
+// probability, but models sometimes answer in percent, so values above 1
+// are read as percentages. A missing confidence is 0.
+func parseClassification(content string) (models.TypeScore, []models.TypeScore, bool) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return models.TypeScore{}, nil, false
+	}
+
+	primary, ok := readTypeScore(raw)
+	if !ok {
+		return models.TypeScore{}, nil, false
+	}
+
+	var alternatives []models.TypeScore
+	items, _ := raw["alternatives"].([]interface{})
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			if alt, ok := readTypeScore(obj); ok {
+				alternatives = append(alternatives, alt)
+			}
+		}
+	}
+	return primary, alternatives, true
+}
+
+func readTypeScore(obj map[string]interface{}) (models.TypeScore, bool) {
+	docType, ok := toString(obj["document_type"])
+	if !ok || isNull(docType) {
+		return models.TypeScore{}, false
+	}
+
+	score := models.TypeScore{DocumentType: strings.ToLower(docType)}
+	if value, err := normalizeNumber(obj["confidence"], false); err == nil {
+		score.Confidence = value.(float64)
+		if score.Confidence > 1 {
+			score.Confidence /= 100
+		}
+	} else if s, ok := obj["confidence"].(string); ok && strings.HasSuffix(strings.TrimSpace(s), "%") {
+		if value, err := normalizeNumber(strings.TrimSuffix(strings.TrimSpace(s), "%"), false); err == nil {
+			score.Confidence = value.(float64) / 100
+		}
+	}
+	score.Confidence = clampConfidence(score.Confidence)
+	return score, true
+}
+
+func clampConfidence(c float64) float64 {
+	return min(max(c, 0), 1)
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
+)
+
+func TestParseClassification(t *testing.T) {
+	content := `{"document_type": "Tax Invoice", "confidence": "85%", "alternatives": [
+		{"document_type": "receipt", "confidence": 0.1},
+		{"document_type": null},
+		{"document_type": "Bill", "confidence": 9}
+	]}`
+
+	primary, alternatives, ok := parseClassification(content)
+	if !ok {
+		t.Fatal("parseClassification could not read the classification")
+	}
+	if primary.DocumentType != "tax invoice" || primary.Confidence != 0.85 {
+		t.Errorf("primary = %+v, want tax invoice at 0.85", primary)
+	}
+	if len(alternatives) != 2 || alternatives[1].Confidence != 0.09 {
+		t.Errorf("alternatives = %+v, want receipt and bill at 0.09", alternatives)
+	}
+
+	if _, _, ok := parseClassification(`{"type": "invoice"}`); ok {
+		t.Error("parseClassification accepted an answer without document_type")
+	}
+}
+
+func TestMapClassification(t *testing.T) {
+	tax := taxonomy.Default()
+
+	class, alternatives := MapClassification(tax,
+		models.TypeScore{DocumentType: "Tax Invoice", Confidence: 0.8},
+		[]models.TypeScore{
+			{DocumentType: "bill", Confidence: 0.5},
+			{DocumentType: "bank statement", Confidence: 0.4},
+			{DocumentType: "agreement", Confidence: 0.1},
+			{DocumentType: "contracts", Confidence: 0.15},
+			{DocumentType: "memo", Confidence: 0.05},
+		})
+	if class.DocumentType != "invoice" || class.Confidence != 0.8 {
+		t.Errorf("class = %+v, want invoice at 0.8", class)
+	}
+	want := []models.TypeScore{{DocumentType: "contract", Confidence: 0.15}, {DocumentType: "memo", Confidence: 0.05}}
+	if len(alternatives) != len(want) || alternatives[0] != want[0] || alternatives[1] != want[1] {
+		t.Errorf("alternatives = %+v, want %+v", alternatives, want)
+	}
+
+	class, _ = MapClassification(tax, models.TypeScore{DocumentType: "purchase order", Confidence: 0.95}, nil)
+	if class.DocumentType != taxonomy.Other || class.Confidence != 0 {
+		t.Errorf("class = %+v, want other with no confidence", class)
+	}
+}
+
+func TestAnalyzeMapsClassificationOntoTaxonomy(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[0].Content)
+
+		content := `{"summary":"A CV.","document_type":"approved","metadata":{}}`
+		if strings.HasPrefix(req.Messages[0].Content, "Classify") {
+			content = `{"document_type":"Resume","confidence":0.62,"alternatives":[{"document_type":"cover letter","confidence":0.3}]}`
+		}
+		reply, _ := json.Marshal(content)
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%s}}]}`, reply)
+	}))
+	t.Cleanup(server.Close)
+
+	a := newTestAnalyzer(server.URL, 0, 5)
+	result, err := a.Analyze(context.Background(), "Jane Doe. Work experience: clerk.", Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	if !strings.Contains(prompts[0], "invoice, contract, cv, report, letter, memo, email, form, other") {
+		t.Errorf("classification prompt does not list the taxonomy:\n%s", prompts[0])
+	}
+	if result.DocumentType != "cv" || result.Confidence != 0.62 {
+		t.Errorf("result = %q at %v, want cv at 0.62", result.DocumentType, result.Confidence)
+	}
+	if len(result.Alternatives) != 1 || result.Alternatives[0].DocumentType != "letter" {
+		t.Errorf("alternatives = %+v, want letter", result.Alternatives)
+	}
+	if !strings.HasPrefix(result.PromptVersion, "classify@3+cv@") {
+		t.Errorf("prompt_version = %q, want the cv template", result.PromptVersion)
+	}
+}
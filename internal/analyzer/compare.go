@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// maxDiffLength bounds the rendered diff sent to the model. It is longer
+// than the document limit since a change shows both versions.
+const maxDiffLength = 8000
+
+var comparisonSchema = []fieldSchema{
+	{name: "summary", kind: kindString, required: true},
+	{name: "changes", kind: kindList, fields: []fieldSchema{
+		{name: "description", kind: kindString, required: true},
+		{name: "significance", kind: kindString},
+	}},
+}
+
+var significances = map[string]bool{"high": true, "medium": true, "low": true}
+
+// parseComparison validates a comparison response. A missing or unknown
+// significance is read as medium.
+func parseComparison(content string) (*models.ComparisonResult, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	v := &validator{}
+	v.object("", comparisonSchema, raw)
+	if len(v.problems) > 0 {
+		return nil, &ValidationError{Problems: v.problems}
+	}
+
+	result := &models.ComparisonResult{
+		Summary: raw["summary"].(string),
+		Changes: []models.MaterialChange{},
+	}
+	changes, _ := raw["changes"].([]interface{})
+	for _, item := range changes {
+		change := item.(map[string]interface{})
+		significance, _ := change["significance"].(string)
+		significance = strings.ToLower(strings.TrimSpace(significance))
+		if !significances[significance] {
+			significance = "medium"
+		}
+		result.Changes = append(result.Changes, models.MaterialChange{
+			Description:  change["description"].(string),
+			Significance: significance,
+		})
+	}
+	return result, nil
+}
+
+// renderDiff writes the differing blocks of a comparison as numbered
+// entries, with paragraph numbers counted from 1
+func renderDiff(blocks []models.DiffBlock) string {
+	var b strings.Builder
+	n := 0
+	for _, block := range blocks {
+		if block.Op == "unchanged" {
+			continue
+		}
+		n++
+		switch block.Op {
+		case "added":
+			fmt.Fprintf(&b, "[%d] Added as paragraph %d of the revision\nRevised: %s\n\n", n, *block.NewIndex+1, block.New)
+		case "removed":
+			fmt.Fprintf(&b, "[%d] Removed paragraph %d of the original\nOriginal: %s\n\n", n, *block.OldIndex+1, block.Old)
+		case "changed":
+			fmt.Fprintf(&b, "[%d] Changed paragraph %d of the original, now paragraph %d\nOriginal: %s\nRevised: %s\n\n",
+				n, *block.OldIndex+1, *block.NewIndex+1, block.Old, block.New)
+		case "moved":
+			fmt.Fprintf(&b, "[%d] Moved paragraph %d of the original to paragraph %d\nText: %s\n\n",
+				n, *block.OldIndex+1, *block.NewIndex+1, block.New)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// excerpt shortens a paragraph to its first words
+func excerpt(text string) string {
+	words := strings.Fields(text)
+	if len(words) <= 12 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:12], " ") + "..."
+}
+
+func joinList(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
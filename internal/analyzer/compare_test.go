@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func index(i int) *int {
+	return &i
+}
+
+var sampleDiff = []models.DiffBlock{
+	{Op: "unchanged", Old: "Definitions.", New: "Definitions.", OldIndex: index(0), NewIndex: index(0)},
+	{Op: "changed", Old: "Payment is due within 30 days.", New: "Payment is due within 45 days.", OldIndex: index(1), NewIndex: index(1)},
+	{Op: "removed", Old: "The supplier keeps all intellectual property.", OldIndex: index(2)},
+	{Op: "moved", Old: "Notices must be in writing.", New: "Notices must be in writing.", OldIndex: index(3), NewIndex: index(2)},
+}
+
+func TestParseComparison(t *testing.T) {
+	result, err := parseComparison(`{"summary": "Payment terms were extended.", "changes": [
+		{"description": "Payment is due in 45 instead of 30 days.", "significance": "HIGH"},
+		{"description": "IP clause removed.", "significance": "critical"},
+		null
+	]}`)
+	if err != nil {
+		t.Fatalf("parseComparison returned %v", err)
+	}
+	if result.Summary != "Payment terms were extended." || len(result.Changes) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.Changes[0].Significance != "high" || result.Changes[1].Significance != "medium" {
+		t.Errorf("significances = %q, %q, want high, medium", result.Changes[0].Significance, result.Changes[1].Significance)
+	}
+
+	if _, err := parseComparison(`{"changes": []}`); err == nil {
+		t.Error("parseComparison accepted a response without a summary")
+	}
+}
+
+func TestRenderDiffSkipsUnchangedParagraphs(t *testing.T) {
+	text := renderDiff(sampleDiff)
+	if strings.Contains(text, "Definitions") {
+		t.Errorf("rendered diff includes an unchanged paragraph:\n%s", text)
+	}
+	for _, want := range []string{
+		"[1] Changed paragraph 2 of the original, now paragraph 2\nOriginal: Payment is due within 30 days.\nRevised: Payment is due within 45 days.",
+		"[2] Removed paragraph 3 of the original",
+		"[3] Moved paragraph 4 of the original to paragraph 3",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rendered diff is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuiltinCompare(t *testing.T) {
+	result, err := NewBuiltinAnalyzer().Compare(context.Background(), sampleDiff)
+	if err != nil {
+		t.Fatalf("Compare returned %v", err)
+	}
+	if want := "The revision has 1 paragraph changed, 1 paragraph removed and 1 paragraph moved."; result.Summary != want {
+		t.Errorf("Summary = %q, want %q", result.Summary, want)
+	}
+	if len(result.Changes) != 2 || !strings.Contains(result.Changes[0].Description, `"Payment is due within 45 days."`) {
+		t.Errorf("Changes = %+v", result.Changes)
+	}
+}
@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+var entitySchema = []fieldSchema{
+	{name: "entities", kind: kindList, required: true, fields: []fieldSchema{
+		{name: "type", kind: kindString, required: true},
+		{name: "text", kind: kindString, required: true},
+	}},
+}
+
+// entityTypeAliases maps the names models use for entity types onto ours
+var entityTypeAliases = map[string]string{
+	"people":       models.EntityPerson,
+	"per":          models.EntityPerson,
+	"org":          models.EntityOrganization,
+	"company":      models.EntityOrganization,
+	"loc":          models.EntityLocation,
+	"place":        models.EntityLocation,
+	"gpe":          models.EntityLocation,
+	"amount":       models.EntityMoney,
+	"monetary":     models.EntityMoney,
+	"money amount": models.EntityMoney,
+}
+
+// parseEntities validates an entity extraction response. Entities of other
+// types are dropped rather than repaired, since models often add extras
+// such as products or events.
+func parseEntities(content string) ([]models.EntityCandidate, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	v := &validator{}
+	v.object("", entitySchema, raw)
+	if len(v.problems) > 0 {
+		return nil, &ValidationError{Problems: v.problems}
+	}
+
+	entities := []models.EntityCandidate{}
+	items, _ := raw["entities"].([]interface{})
+	for _, item := range items {
+		obj := item.(map[string]interface{})
+		entityType := strings.ToLower(strings.TrimSpace(obj["type"].(string)))
+		if alias, ok := entityTypeAliases[entityType]; ok {
+			entityType = alias
+		}
+		if !slices.Contains(models.EntityTypes, entityType) {
+			continue
+		}
+		entities = append(entities, models.EntityCandidate{Type: entityType, Text: obj["text"].(string)})
+	}
+	return entities, nil
+}
+
+var (
+	honorificPattern = regexp.MustCompile(`(?i)^(?:mr|mrs|ms|miss|mx|dr|prof|sir|dame)\.?\s+`)
+	articlePattern   = regexp.MustCompile(`(?i)^the\s+`)
+	// legalSuffixPattern matches the legal form at the end of a company
+	// name, after punctuation has been reduced to spaces
+	legalSuffixPattern = regexp.MustCompile(`\s+(?:ltd|limited|inc|incorporated|corp|corporation|co|company|llc|llp|plc|gmbh|ag|sa|bv|nv|pty)$`)
+	entitySeparators   = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// NormalizeEntity returns the value an entity is stored and searched by,
+// or false when text is not a valid entity of that type. Names are
+// lowercased with punctuation removed; people lose their honorifics and
+// organizations their legal form, so "ACME Ltd." and "Acme" are the same
+// organization. Dates become YYYY-MM-DD and money a two place amount
+// followed by its currency code when one is given, e.g. "1392.00 USD".
+func NormalizeEntity(entityType, text string) (string, bool) {
+	text = strings.TrimSpace(text)
+
+	switch entityType {
+	case models.EntityPerson, models.EntityOrganization, models.EntityLocation:
+		if entityType == models.EntityPerson {
+			text = honorificPattern.ReplaceAllString(text, "")
+		}
+		value := strings.TrimSpace(entitySeparators.ReplaceAllString(strings.ToLower(text), " "))
+		value = articlePattern.ReplaceAllString(value, "")
+		if entityType == models.EntityOrganization {
+			for {
+				trimmed := legalSuffixPattern.ReplaceAllString(value, "")
+				if trimmed == value || trimmed == "" {
+					break
+				}
+				value = trimmed
+			}
+		}
+		return value, value != ""
+
+	case models.EntityDate:
+		date, err := normalizeDate(strings.Replace(text, ".", "", 1))
+		return date, err == nil
+
+	case models.EntityMoney:
+		if amounts := findAmounts(text); len(amounts) > 0 {
+			return strings.TrimSpace(amounts[0].amount + " " + amounts[0].currency), true
+		}
+		if number := numberPattern.FindString(text); number != "" {
+			amount, err := normalizeAmount(number)
+			return amount, err == nil
+		}
+	}
+	return "", false
+}
+
+var (
+	organizationPattern = regexp.MustCompile(`\b(?:[A-Z][\w&'\-]*\s+){1,4}(?:Ltd|Limited|Inc|Incorporated|Corp|Corporation|LLC|LLP|PLC|plc|GmbH|AG|Company|Bank|University|Group)\b\.?`)
+	personPattern       = regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Dr|Prof)\.?\s+(?:[A-Z]\.\s*)*[A-Z][a-z'\-]+(?:\s+[A-Z][a-z'\-]+)?`)
+	// personLinePattern matches a name on its own line after a label or a
+	// letter's sign-off
+	personLinePattern = regexp.MustCompile(`(?im)(?:^\s*(?:name|contact|attn|attention|prepared by|signed by)\s*:\s*|(?:sincerely|regards|faithfully),?\s*\n\s*)([A-Z][a-z'\-]+(?:\s+[A-Z]\.)?(?:\s+[A-Z][a-z'\-]+){1,2})[ \t]*$`)
+	locationPattern   = regexp.MustCompile(`\b(?:laws of(?: the)?|located in|based in|registered in|resident in|headquartered in)\s+([A-Z][a-z]+(?:\s+[A-Z][a-z]+){0,2})`)
+)
+
+// findEntities finds entities with regular expressions: dates and amounts
+// in the formats the metadata rules know, companies by their legal form,
+// people by their honorific or a name label, and locations after phrases
+// such as "based in"
+func findEntities(text string) []models.EntityCandidate {
+	var entities []models.EntityCandidate
+	add := func(entityType string, matches []string) {
+		for _, m := range matches {
+			entities = append(entities, models.EntityCandidate{Type: entityType, Text: strings.TrimSpace(m)})
+		}
+	}
+
+	add(models.EntityDate, datePattern.FindAllString(text, -1))
+	add(models.EntityMoney, amountPattern.FindAllString(text, -1))
+
+	var organizations []string
+	for _, m := range organizationPattern.FindAllString(text, -1) {
+		// Capitalized words that start a sentence are not part of the name
+		words := strings.Fields(m)
+		for len(words) > 2 && (stopwords[strings.ToLower(words[0])] || strings.EqualFold(words[0], "between")) {
+			words = words[1:]
+		}
+		organizations = append(organizations, strings.Join(words, " "))
+	}
+	add(models.EntityOrganization, organizations)
+
+	add(models.EntityPerson, personPattern.FindAllString(text, -1))
+	add(models.EntityPerson, submatches(personLinePattern, text))
+	add(models.EntityLocation, submatches(locationPattern, text))
+	return entities
+}
+
+func submatches(pattern *regexp.Regexp, text string) []string {
+	var found []string
+	for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+		found = append(found, m[1])
+	}
+	return found
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestParseEntities(t *testing.T) {
+	content := `{"entities": [
+		{"type": "Person", "text": "Jane Doe"},
+		{"type": "ORG", "text": "Acme Ltd"},
+		{"type": "product", "text": "Widget"},
+		null,
+		{"type": "place", "text": "Nairobi"}
+	]}`
+
+	entities, err := parseEntities(content)
+	if err != nil {
+		t.Fatalf("parseEntities returned %v", err)
+	}
+	want := []models.EntityCandidate{
+		{Type: models.EntityPerson, Text: "Jane Doe"},
+		{Type: models.EntityOrganization, Text: "Acme Ltd"},
+		{Type: models.EntityLocation, Text: "Nairobi"},
+	}
+	if len(entities) != len(want) {
+		t.Fatalf("entities = %+v, want %+v", entities, want)
+	}
+	for i := range want {
+		if entities[i] != want[i] {
+			t.Errorf("entities[%d] = %+v, want %+v", i, entities[i], want[i])
+		}
+	}
+
+	if _, err := parseEntities(`{"entities": [{"type": "person"}]}`); err == nil {
+		t.Error("parseEntities accepted an entity without text")
+	}
+}
+
+func TestNormalizeEntity(t *testing.T) {
+	tests := []struct {
+		entityType string
+		text       string
+		want       string
+		ok         bool
+	}{
+		{models.EntityOrganization, "ACME Ltd.", "acme", true},
+		{models.EntityOrganization, "The Acme Trading Co., Inc.", "acme trading", true},
+		{models.EntityOrganization, "Company", "company", true},
+		{models.EntityPerson, "Dr. Jane  Doe", "jane doe", true},
+		{models.EntityLocation, "New York", "new york", true},
+		{models.EntityDate, "5 March 2024", "2024-03-05", true},
+		{models.EntityDate, "Sep. 5, 2024", "2024-09-05", true},
+		{models.EntityDate, "next Tuesday", "", false},
+		{models.EntityMoney, "$1,392.00", "1392.00 USD", true},
+		{models.EntityMoney, "1.392,50", "1392.50", true},
+		{models.EntityMoney, "a lot", "", false},
+		{"product", "Widget", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeEntity(tt.entityType, tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeEntity(%q, %q) = %q, %v, want %q, %v", tt.entityType, tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuiltinEntities(t *testing.T) {
+	text := "This agreement is made on 1 March 2024 between Acme Trading Ltd, based in Nairobi, " +
+		"and Mr. John Smith. The fee is $2,500.00 per month.\n\n" +
+		"This agreement is governed by the laws of Kenya.\n\nYours sincerely,\nJane Doe\n"
+
+	result, err := NewBuiltinAnalyzer().Entities(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Entities returned %v", err)
+	}
+
+	found := map[models.EntityCandidate]bool{}
+	for _, entity := range result.Entities {
+		found[entity] = true
+	}
+	for _, want := range []models.EntityCandidate{
+		{Type: models.EntityDate, Text: "1 March 2024"},
+		{Type: models.EntityOrganization, Text: "Acme Trading Ltd"},
+		{Type: models.EntityLocation, Text: "Nairobi"},
+		{Type: models.EntityLocation, Text: "Kenya"},
+		{Type: models.EntityPerson, Text: "Mr. John Smith"},
+		{Type: models.EntityPerson, Text: "Jane Doe"},
+		{Type: models.EntityMoney, Text: "$2,500.00"},
+	} {
+		if !found[want] {
+			t.Errorf("Entities did not find %+v in %+v", want, result.Entities)
+		}
+	}
+	if result.Model != BuiltinModel {
+		t.Errorf("Model = %q, want %q", result.Model, BuiltinModel)
+	}
+}
@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+type fallbackAnalyzer struct {
+	analyzers []Analyzer
+	logger    *utils.Logger
+}
+
+// NewFallbackAnalyzer tries each analyzer in order until one returns a valid
+// result. Errors and output that still fails validation after repair both
+// move on to the next analyzer. The result's Model names the one that
+// answered.
+func NewFallbackAnalyzer(analyzers []Analyzer, logger *utils.Logger) Analyzer {
+	if len(analyzers) == 1 {
+		return analyzers[0]
+	}
+	return &fallbackAnalyzer{
+		analyzers: analyzers,
+		logger:    logger,
+	}
+}
+
+func (a *fallbackAnalyzer) Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.LLMAnalysisResult, error) {
+		return next.Analyze(ctx, text, opts)
+	})
+}
+
+func (a *fallbackAnalyzer) Extract(ctx context.Context, text string, schema *models.ExtractionSchema) (*models.ExtractionResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.ExtractionResult, error) {
+		return next.Extract(ctx, text, schema)
+	})
+}
+
+func (a *fallbackAnalyzer) Answer(ctx context.Context, question string, passages []models.Passage) (*models.AnswerResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.AnswerResult, error) {
+		return next.Answer(ctx, question, passages)
+	})
+}
+
+func (a *fallbackAnalyzer) Entities(ctx context.Context, text string) (*models.EntityResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.EntityResult, error) {
+		return next.Entities(ctx, text)
+	})
+}
+
+func (a *fallbackAnalyzer) Compare(ctx context.Context, blocks []models.DiffBlock) (*models.ComparisonResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.ComparisonResult, error) {
+		return next.Compare(ctx, blocks)
+	})
+}
+
+func (a *fallbackAnalyzer) Translate(ctx context.Context, paragraphs []string, opts Options) (*models.TranslationResult, error) {
+	return tryEach(ctx, a, func(next Analyzer) (*models.TranslationResult, error) {
+		return next.Translate(ctx, paragraphs, opts)
+	})
+}
+
+// tryEach calls call with each analyzer of the chain until one succeeds
+func tryEach[T any](ctx context.Context, a *fallbackAnalyzer, call func(Analyzer) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+
+	for i, next := range a.analyzers {
+		result, err := call(next)
+		if err == nil {
+			if i > 0 {
+				a.logger.Warn("Result produced by fallback model", "position", i+1)
+			}
+			return result, nil
+		}
+
+		if ctx.Err() != nil {
+			return zero, err
+		}
+
+		a.logger.Warn("Model failed, trying next in chain", "position", i+1, "error", err)
+		errs = append(errs, err)
+	}
+
+	// Only report the provider as unavailable when every circuit was open,
+	// so a mix of failures is not mistaken for a temporary outage
+	allOpen := true
+	for _, err := range errs {
+		if !errors.Is(err, ErrCircuitOpen) {
+			allOpen = false
+		}
+	}
+	if allOpen {
+		return zero, ErrCircuitOpen
+	}
+
+	// The errors stay matchable, e.g. for ErrTranslationUnsupported, except
+	// open circuits, which only mean an outage when every circuit is open
+	wrapped := make([]error, len(errs))
+	for i, err := range errs {
+		if errors.Is(err, ErrCircuitOpen) {
+			err = errors.New(err.Error())
+		}
+		wrapped[i] = err
+	}
+	return zero, fmt.Errorf("all %d models failed: %w", len(errs), errors.Join(wrapped...))
+}
+
+func (a *fallbackAnalyzer) ProviderStatus() []models.ProviderStatus {
+	var statuses []models.ProviderStatus
+	for _, next := range a.analyzers {
+		if reporter, ok := next.(StatusReporter); ok {
+			statuses = append(statuses, reporter.ProviderStatus()...)
+		}
+	}
+	return statuses
+}
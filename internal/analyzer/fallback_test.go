@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+func TestFallbackUsesNextModelOnFailure(t *testing.T) {
+	primary, primaryCalls := stubProvider(t, http.StatusTooManyRequests)
+	secondary, _ := stubProvider(t, http.StatusOK)
+
+	a := NewFallbackAnalyzer([]Analyzer{
+		newTestAnalyzer(primary.URL, 1, 5),
+		newTestAnalyzer(secondary.URL, 1, 5),
+	}, utils.NewLogger("error"))
+
+	if _, err := a.Analyze(context.Background(), "text", Options{}); err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if got := primaryCalls.Load(); got != 2 {
+		t.Errorf("primary called %d times, want 2", got)
+	}
+	if len(a.(StatusReporter).ProviderStatus()) != 2 {
+		t.Errorf("expected a status per model in the chain")
+	}
+}
+
+func TestFallbackUsesNextModelOnInvalidOutput(t *testing.T) {
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"I cannot help with that."}}]}`))
+	}))
+	t.Cleanup(invalid.Close)
+	valid, _ := stubProvider(t, http.StatusOK)
+
+	primary := newTestAnalyzer(invalid.URL, 0, 5)
+	primary.model = "primary/model"
+	secondary := newTestAnalyzer(valid.URL, 0, 5)
+	secondary.model = "cheap/model"
+
+	a := NewFallbackAnalyzer([]Analyzer{primary, secondary}, utils.NewLogger("error"))
+
+	result, err := a.Analyze(context.Background(), "text", Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if result.Model != "cheap/model" {
+		t.Errorf("model = %q, want %q", result.Model, "cheap/model")
+	}
+}
+
+func TestFallbackReportsUnavailableOnlyWhenAllCircuitsOpen(t *testing.T) {
+	down, _ := stubProvider(t, http.StatusServiceUnavailable)
+	unauthorized, _ := stubProvider(t, http.StatusUnauthorized)
+
+	first := newTestAnalyzer(down.URL, 0, 1)
+	second := newTestAnalyzer(down.URL, 0, 1)
+	a := NewFallbackAnalyzer([]Analyzer{first, second}, utils.NewLogger("error"))
+
+	// The first pass opens both circuits, the second fast-fails on both
+	a.Analyze(context.Background(), "text", Options{})
+	if _, err := a.Analyze(context.Background(), "text", Options{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+
+	mixed := NewFallbackAnalyzer([]Analyzer{first, newTestAnalyzer(unauthorized.URL, 0, 1)}, utils.NewLogger("error"))
+	if _, err := mixed.Analyze(context.Background(), "text", Options{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want a non circuit error", err)
+	}
+}
+
+func TestFallbackKeepsErrorsMatchable(t *testing.T) {
+	unauthorized, _ := stubProvider(t, http.StatusUnauthorized)
+	a := NewFallbackAnalyzer([]Analyzer{newTestAnalyzer(unauthorized.URL, 0, 5), NewBuiltinAnalyzer()}, utils.NewLogger("error"))
+
+	_, err := a.Translate(context.Background(), []string{"Hello"}, Options{SourceLanguage: "en", TargetLanguage: "fr"})
+	if !errors.Is(err, ErrTranslationUnsupported) {
+		t.Errorf("error = %v, want it to match ErrTranslationUnsupported", err)
+	}
+}
@@ -4,30 +4,97 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/BerylCAtieno/document-summarizer-api/internal/language"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
 	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
 )
 
+// maxRepairAttempts bounds how many times invalid output is sent back to the
+// model for correction
+const maxRepairAttempts = 2
+
 type Analyzer interface {
-	Analyze(ctx context.Context, text string) (*models.LLMAnalysisResult, error)
+	Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error)
+	// Extract fills in the fields of a user-defined schema
+	Extract(ctx context.Context, text string, schema *models.ExtractionSchema) (*models.ExtractionResult, error)
+	// Answer answers a question from numbered passages of a document
+	Answer(ctx context.Context, question string, passages []models.Passage) (*models.AnswerResult, error)
+	// Entities finds the people, organizations, locations, dates and
+	// amounts of money mentioned in a document
+	Entities(ctx context.Context, text string) (*models.EntityResult, error)
+	// Compare summarizes the material differences of a paragraph diff
+	Compare(ctx context.Context, blocks []models.DiffBlock) (*models.ComparisonResult, error)
+	// Translate translates paragraphs from opts.SourceLanguage into
+	// opts.TargetLanguage, returning one translation per paragraph
+	Translate(ctx context.Context, paragraphs []string, opts Options) (*models.TranslationResult, error)
+}
+
+// Options tunes a single analysis. Languages are ISO 639-1 codes; an empty
+// TargetLanguage means the summary follows the source language.
+type Options struct {
+	SourceLanguage string
+	TargetLanguage string
+	// Style and Length choose the form of the summary, see models.Summary*;
+	// empty means standard and medium
+	Style  string
+	Length string
+	// NoCache skips the analysis cache lookup; the fresh result is still
+	// stored
+	NoCache bool
+}
+
+// DefaultOpenRouterBaseURL is the OpenRouter API root
+const DefaultOpenRouterBaseURL = "https://openrouter.ai/api/v1"
+
+// OpenRouterConfig configures the OpenRouter client. BaseURL can point at a
+// compatible gateway or a test server.
+type OpenRouterConfig struct {
+	APIKey           string
+	Model            string
+	BaseURL          string
+	Retry            RetryPolicy
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Prompts defaults to the built-in templates
+	Prompts *Prompts
+	// Taxonomy is the set of types the classifier chooses from; it defaults
+	// to taxonomy.Default()
+	Taxonomy *taxonomy.Taxonomy
+}
+
+// StatusReporter is implemented by analyzers that can report the health of
+// the providers they call
+type StatusReporter interface {
+	ProviderStatus() []models.ProviderStatus
 }
 
 type openRouterAnalyzer struct {
-	apiKey string
-	model  string
-	logger *utils.Logger
-	client *http.Client
+	apiKey   string
+	model    string
+	baseURL  string
+	retry    RetryPolicy
+	breaker  *CircuitBreaker
+	prompts  *Prompts
+	taxonomy *taxonomy.Taxonomy
+	logger   *utils.Logger
+	client   *http.Client
 }
 
 type OpenRouterRequest struct {
 	Model    string    `json:"model"`
 	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream,omitempty"`
 }
 
 type Message struct {
@@ -36,71 +103,460 @@ type Message struct {
 }
 
 type OpenRouterResponse struct {
-	Choices []Choice `json:"choices"`
-	Error   *struct {
-		Message string `json:"message"`
-		Code    string `json:"code"`
-	} `json:"error,omitempty"`
+	Choices []Choice  `json:"choices"`
+	Usage   *Usage    `json:"usage,omitempty"`
+	Error   *APIError `json:"error,omitempty"`
+}
+
+// APIError is an error reported in the body of a response or stream chunk.
+// Code is usually the HTTP status of the upstream failure.
+type APIError struct {
+	Message string `json:"message"`
+	Code    any    `json:"code"`
+}
+
+func (e *APIError) providerError() *ProviderError {
+	status, _ := strconv.Atoi(fmt.Sprint(e.Code))
+	return &ProviderError{
+		StatusCode: status,
+		Message:    e.Message,
+		Retryable:  isRetryableStatus(status),
+	}
 }
 
 type Choice struct {
 	Message Message `json:"message"`
+	// Delta carries the new content of a streamed chunk
+	Delta Message `json:"delta"`
 }
 
-func NewOpenRouterAnalyzer(apiKey, model string, logger *utils.Logger) Analyzer {
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+func NewOpenRouterAnalyzer(cfg OpenRouterConfig, logger *utils.Logger) Analyzer {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOpenRouterBaseURL
+	}
+
+	prompts := cfg.Prompts
+	if prompts == nil {
+		// The built-in templates are compiled in and checked by tests
+		prompts, _ = LoadPrompts("")
+	}
+	types := cfg.Taxonomy
+	if types == nil {
+		types = taxonomy.Default()
+	}
+
 	return &openRouterAnalyzer{
-		apiKey: apiKey,
-		model:  model,
-		logger: logger,
+		apiKey:   cfg.APIKey,
+		model:    cfg.Model,
+		baseURL:  baseURL,
+		retry:    cfg.Retry,
+		breaker:  NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		prompts:  prompts,
+		taxonomy: types,
+		logger:   logger,
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 	}
 }
 
-func (a *openRouterAnalyzer) Analyze(ctx context.Context, text string) (*models.LLMAnalysisResult, error) {
-	// Truncate text if too long
-	if len(text) > 4000 {
-		text = text[:4000] + "..."
+func (a *openRouterAnalyzer) ProviderStatus() []models.ProviderStatus {
+	snapshot := a.breaker.Snapshot()
+	return []models.ProviderStatus{
+		{
+			Provider:            "openrouter",
+			Model:               a.model,
+			CircuitState:        string(snapshot.State),
+			ConsecutiveFailures: snapshot.ConsecutiveFailures,
+			RetryAt:             snapshot.RetryAt,
+			LastError:           snapshot.LastError,
+		},
+	}
+}
+
+// maxPromptText is how many bytes of a document are sent to the model
+const maxPromptText = 4000
+
+// truncateText cuts text to at most n bytes plus an ellipsis, backing off
+// to the start of a rune so a multi-byte character is not split
+func truncateText(text string, n int) string {
+	if len(text) <= n {
+		return text
 	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n] + "..."
+}
+
+func (a *openRouterAnalyzer) Analyze(ctx context.Context, text string, opts Options) (*models.LLMAnalysisResult, error) {
+	return a.analyze(ctx, text, opts, nil)
+}
+
+// analyze runs both stages; the analysis stage is streamed to sink when it
+// is not nil
+func (a *openRouterAnalyzer) analyze(ctx context.Context, text string, opts Options, sink TokenSink) (*models.LLMAnalysisResult, error) {
+	text = truncateText(text, maxPromptText)
 
-	prompt := fmt.Sprintf(`Analyze the following document and provide a structured response in JSON format only.
+	var usage models.TokenUsage
 
-Document text:
-%s
+	// Stage one picks the template, stage two runs it
+	if sink != nil {
+		sink.Stage(StageClassify)
+	}
+	class, alternatives, classifyUsage, err := a.classify(ctx, text)
+	usage.Add(classifyUsage)
+	if err != nil {
+		return nil, withUsage(err, a.model, usage)
+	}
+	docType := class.DocumentType
+
+	tmpl := a.prompts.forType(docType)
+	prompt, err := tmpl.render(promptData{
+		LanguageInstructions: languageInstructions(opts),
+		SummaryInstructions:  summaryInstructions(opts),
+		DocumentType:         docType,
+	})
+	if err != nil {
+		return nil, withUsage(err, a.model, usage)
+	}
+	schema := schemaFor(tmpl.Name)
+	promptVersion := a.prompts.classifier().ID() + "+" + tmpl.ID()
+
+	if sink != nil {
+		sink.Stage(StageAnalyze)
+	}
+	var result *models.LLMAnalysisResult
+	err = a.completeValid(ctx, documentMessages(prompt, text), &usage, sink, func(content string) error {
+		var err error
+		result, err = parseAnalysis(content, schema)
+		return err
+	})
+	if err != nil {
+		return nil, withUsage(err, a.model, usage)
+	}
 
-Respond ONLY with a valid JSON object (no markdown, no code blocks) with the following structure:
-{
-  "summary": "A concise 2-3 sentence summary of the document",
-  "document_type": "The type of document (invoice, cv, resume, report, letter, contract, memo, email, etc.)",
-  "metadata": {
-    "date": "Extracted date if found (format: YYYY-MM-DD) or null",
-    "sender": "Sender name if found or null",
-    "recipient": "Recipient name if found or null",
-    "amount": "Total amount if invoice/financial document or null",
-    "currency": "Currency code if amount found or null",
-    "company": "Company name if found or null"
-  }
-}`, text)
+	// The type was chosen by the classifier, so the second stage has no say
+	// in it; a document cannot talk its way into another one halfway
+	// through. Only when classification failed is the second stage's type
+	// used, with no confidence.
+	if docType != "" {
+		result.DocumentType = docType
+	} else {
+		class, alternatives = MapClassification(a.taxonomy, models.TypeScore{DocumentType: result.DocumentType}, nil)
+		result.DocumentType = class.DocumentType
+	}
+	result.Confidence = class.Confidence
+	result.Alternatives = alternatives
+
+	result.Model = a.model
+	result.PromptVersion = promptVersion
+	result.Usage = usage
+	return result, nil
+}
+
+func (a *openRouterAnalyzer) Extract(ctx context.Context, text string, schema *models.ExtractionSchema) (*models.ExtractionResult, error) {
+	text = truncateText(text, maxPromptText)
+
+	tmpl := a.prompts.extractor()
+	prompt, err := tmpl.render(promptData{
+		Schema: schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fields := extractionFields(schema.Fields)
+
+	var usage models.TokenUsage
+	var data map[string]interface{}
+	err = a.completeValid(ctx, documentMessages(prompt, text), &usage, nil, func(content string) error {
+		var err error
+		data, err = parseExtraction(content, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ExtractionResult{
+		Data:          data,
+		Model:         a.model,
+		PromptVersion: tmpl.ID(),
+		Usage:         usage,
+	}, nil
+}
+
+func (a *openRouterAnalyzer) Answer(ctx context.Context, question string, passages []models.Passage) (*models.AnswerResult, error) {
+	tmpl := a.prompts.answerer()
+	prompt, err := tmpl.render(promptData{
+		Question: question,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.TokenUsage
+	var result *models.AnswerResult
+	err = a.completeValid(ctx, documentMessages(prompt, passageDocument(passages)), &usage, nil, func(content string) error {
+		var err error
+		result, err = parseAnswer(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Model = a.model
+	result.PromptVersion = tmpl.ID()
+	result.Usage = usage
+	return result, nil
+}
+
+func (a *openRouterAnalyzer) Entities(ctx context.Context, text string) (*models.EntityResult, error) {
+	text = truncateText(text, maxPromptText)
+
+	tmpl := a.prompts.entityExtractor()
+	prompt, err := tmpl.render(promptData{})
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.TokenUsage
+	var entities []models.EntityCandidate
+	err = a.completeValid(ctx, documentMessages(prompt, text), &usage, nil, func(content string) error {
+		var err error
+		entities, err = parseEntities(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntityResult{
+		Entities:      entities,
+		Model:         a.model,
+		PromptVersion: tmpl.ID(),
+		Usage:         usage,
+	}, nil
+}
+
+func (a *openRouterAnalyzer) Compare(ctx context.Context, blocks []models.DiffBlock) (*models.ComparisonResult, error) {
+	text := truncateText(renderDiff(blocks), maxDiffLength)
+
+	tmpl := a.prompts.comparer()
+	prompt, err := tmpl.render(promptData{})
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.TokenUsage
+	var result *models.ComparisonResult
+	err = a.completeValid(ctx, documentMessages(prompt, text), &usage, nil, func(content string) error {
+		var err error
+		result, err = parseComparison(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Model = a.model
+	result.PromptVersion = tmpl.ID()
+	result.Usage = usage
+	return result, nil
+}
+
+func (a *openRouterAnalyzer) Translate(ctx context.Context, paragraphs []string, opts Options) (*models.TranslationResult, error) {
+	target, ok := language.Name(opts.TargetLanguage)
+	if !ok {
+		return nil, fmt.Errorf("unsupported target language %q", opts.TargetLanguage)
+	}
+	source, _ := language.Name(opts.SourceLanguage)
+
+	tmpl := a.prompts.translator()
+	prompt, err := tmpl.render(promptData{
+		SourceLanguage: source,
+		TargetLanguage: target,
+		Paragraphs:     len(paragraphs),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.TokenUsage
+	var translated []string
+	err = a.completeValid(ctx, documentMessages(prompt, paragraphDocument(paragraphs)), &usage, nil, func(content string) error {
+		var err error
+		translated, err = parseTranslation(content, len(paragraphs))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TranslationResult{
+		Paragraphs:    translated,
+		Model:         a.model,
+		PromptVersion: tmpl.ID(),
+		Usage:         usage,
+	}, nil
+}
+
+// completeValid sends messages and passes the answer to parse. Invalid output
+// gets a bounded number of repair round trips, each showing the model its
+// previous answer and what was wrong with it. Usage is summed over all of
+// them since each one is billed. Output is streamed to sink when it is not
+// nil, with a restart before each repair.
+func (a *openRouterAnalyzer) completeValid(ctx context.Context, messages []Message, usage *models.TokenUsage, sink TokenSink, parse func(content string) error) error {
+	for attempt := 0; ; attempt++ {
+		content, callUsage, err := a.complete(ctx, messages, sink)
+		usage.Add(callUsage)
+		if err != nil {
+			return err
+		}
+
+		err = parse(content)
+		if err == nil {
+			return nil
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || attempt >= maxRepairAttempts {
+			a.logger.Error("Failed to parse LLM response", "content", content, "error", err)
+			return fmt.Errorf("failed to parse LLM response: %w", err)
+		}
+
+		a.logger.Warn("LLM response failed validation, requesting repair",
+			"attempt", attempt+1,
+			"problems", validationErr.Problems)
+
+		if sink != nil {
+			sink.Restart("repair")
+		}
+		messages = append(messages,
+			Message{Role: "assistant", Content: content},
+			Message{Role: "user", Content: repairPrompt(validationErr)},
+		)
+	}
+}
+
+// classify runs the classification prompt and returns the document type
+// mapped onto the taxonomy, with its confidence and the alternatives.
+// Output that cannot be read leaves the type empty, which selects the
+// default template, rather than failing the analysis.
+func (a *openRouterAnalyzer) classify(ctx context.Context, text string) (models.TypeScore, []models.TypeScore, models.TokenUsage, error) {
+	prompt, err := a.prompts.classifier().render(promptData{
+		Types: a.taxonomy.Types(),
+	})
+	if err != nil {
+		return models.TypeScore{}, nil, models.TokenUsage{}, err
+	}
+
+	content, usage, err := a.complete(ctx, documentMessages(prompt, text), nil)
+	if err != nil {
+		return models.TypeScore{}, nil, usage, err
+	}
+
+	primary, alternatives, ok := parseClassification(content)
+	if !ok {
+		a.logger.Warn("Could not read document classification, using default prompt", "content", content)
+		return models.TypeScore{}, nil, usage, nil
+	}
+
+	class, alternatives := MapClassification(a.taxonomy, primary, alternatives)
+	if class.DocumentType != primary.DocumentType {
+		a.logger.Info("Mapped document type onto the taxonomy",
+			"label", primary.DocumentType,
+			"type", class.DocumentType)
+	}
+	return class, alternatives, usage, nil
+}
+
+// complete sends a chat completion request through the circuit breaker,
+// retrying transient failures, and returns the content of the first choice
+// with the token usage reported for it. A non-nil sink receives the content
+// as it is generated, with a restart before each retry.
+func (a *openRouterAnalyzer) complete(ctx context.Context, messages []Message, sink TokenSink) (string, models.TokenUsage, error) {
+	var usage models.TokenUsage
+	if !a.breaker.Allow() {
+		return "", usage, ErrCircuitOpen
+	}
+
+	for attempt := 0; ; attempt++ {
+		content, callUsage, err := a.send(ctx, messages, sink)
+		usage.Add(callUsage)
+		if err == nil {
+			a.breaker.Success()
+			return content, usage, nil
+		}
+
+		if !isRetryable(err) {
+			// The provider answered, so a permanent error says nothing
+			// about its availability. Context cancellation is the caller's.
+			if ctx.Err() != nil {
+				a.breaker.Release()
+			} else {
+				a.breaker.Success()
+			}
+			return "", usage, err
+		}
+
+		if attempt >= a.retry.MaxRetries {
+			a.breaker.Failure(err)
+			return "", usage, err
+		}
+
+		delay := a.retry.backoff(attempt + 1)
+		var providerErr *ProviderError
+		if errors.As(err, &providerErr) && providerErr.RetryAfter > 0 {
+			if a.retry.MaxDelay > 0 && providerErr.RetryAfter > a.retry.MaxDelay {
+				// Waiting that long would outlast the request; give up now
+				a.breaker.Failure(err)
+				return "", usage, err
+			}
+			delay = max(delay, providerErr.RetryAfter)
+		}
+
+		a.logger.Warn("LLM request failed, retrying",
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", err)
+
+		if err := sleep(ctx, delay); err != nil {
+			a.breaker.Release()
+			return "", usage, err
+		}
+		if sink != nil {
+			sink.Restart("retry")
+		}
+	}
+}
+
+// send makes a single chat completion request, streamed when sink is not nil
+func (a *openRouterAnalyzer) send(ctx context.Context, messages []Message, sink TokenSink) (string, models.TokenUsage, error) {
+	var usage models.TokenUsage
 
 	reqBody := OpenRouterRequest{
-		Model: a.model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:    a.model,
+		Messages: messages,
+		Stream:   sink != nil,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", usage, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", usage, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+a.apiKey)
@@ -109,45 +565,79 @@ Respond ONLY with a valid JSON object (no markdown, no code blocks) with the fol
 
 	resp, err := a.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return "", usage, &ProviderError{
+			Message:   "failed to send request",
+			Retryable: ctx.Err() == nil,
+			Err:       err,
+		}
 	}
 	defer resp.Body.Close()
 
+	if sink != nil && resp.StatusCode == http.StatusOK {
+		return readStream(ctx, resp.Body, sink)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return "", usage, &ProviderError{
+			Message:   "failed to read response",
+			Retryable: ctx.Err() == nil,
+			Err:       err,
+		}
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		a.logger.Error("OpenRouter API error", "status", resp.StatusCode, "body", string(body))
-		return nil, fmt.Errorf("OpenRouter API returned status %d", resp.StatusCode)
+		return "", usage, &ProviderError{
+			StatusCode: resp.StatusCode,
+			Message:    http.StatusText(resp.StatusCode),
+			Retryable:  isRetryableStatus(resp.StatusCode),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	var openRouterResp OpenRouterResponse
 	if err := json.Unmarshal(body, &openRouterResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", usage, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	if openRouterResp.Usage != nil {
+		usage.PromptTokens = openRouterResp.Usage.PromptTokens
+		usage.CompletionTokens = openRouterResp.Usage.CompletionTokens
+	}
+
+	// OpenRouter reports some upstream failures in the body of a 200
 	if openRouterResp.Error != nil {
-		return nil, fmt.Errorf("OpenRouter API error: %s", openRouterResp.Error.Message)
+		return "", usage, openRouterResp.Error.providerError()
 	}
 
 	if len(openRouterResp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in response")
+		return "", usage, fmt.Errorf("no choices in response")
 	}
 
-	content := openRouterResp.Choices[0].Message.Content
+	return openRouterResp.Choices[0].Message.Content, usage, nil
+}
 
-	var result models.LLMAnalysisResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		content = extractJSON(content)
-		if err := json.Unmarshal([]byte(content), &result); err != nil {
-			a.logger.Error("Failed to parse LLM response", "content", content)
-			return nil, fmt.Errorf("failed to parse LLM response as JSON: %w", err)
-		}
+// languageInstructions tells the model which language the document is in and
+// which language to write the summary in. document_type and metadata keys
+// stay in English so they can be filtered on regardless of language.
+func languageInstructions(opts Options) string {
+	var b strings.Builder
+
+	if name, ok := language.Name(opts.SourceLanguage); ok {
+		fmt.Fprintf(&b, "\nThe document is written in %s.", name)
 	}
 
-	return &result, nil
+	target := opts.TargetLanguage
+	if target == "" {
+		target = opts.SourceLanguage
+	}
+	if name, ok := language.Name(target); ok {
+		fmt.Fprintf(&b, "\nWrite the summary in %s, regardless of the language of the document.", name)
+	}
+	b.WriteString("\nAlways write document_type and metadata keys in English.\n")
+
+	return b.String()
 }
 
 func extractJSON(content string) string {
@@ -177,5 +667,45 @@ func extractJSON(content string) string {
 		}
 	}
 
+	// Drop any prose before or after the outermost JSON object
+	if object, ok := outermostObject(content); ok {
+		content = object
+	}
+
 	return content
 }
+
+// outermostObject returns the first balanced {...} span in s, skipping
+// braces that appear inside JSON strings
+func outermostObject(s string) (string, bool) {
+	start := strings.IndexByte(s, '{')
+	if start < 0 {
+		return "", false
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		n    int
+		want string
+	}{
+		{name: "short", text: "Bonjour", n: 10, want: "Bonjour"},
+		{name: "ascii", text: "Invoice total", n: 7, want: "Invoice..."},
+		// é is two bytes; cutting at 3 would keep only its first byte
+		{name: "inside a rune", text: "Fréquence", n: 3, want: "Fr..."},
+		{name: "rune boundary", text: "Fréquence", n: 4, want: "Fré..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.text, tt.n)
+			if got != tt.want {
+				t.Errorf("truncateText() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateText() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+
+	long := strings.Repeat("é", maxPromptText)
+	if got := truncateText(long, maxPromptText); !utf8.ValidString(got) || len(got) > maxPromptText+len("...") {
+		t.Errorf("truncated %d bytes of French text to invalid or oversized text", len(long))
+	}
+}
@@ -0,0 +1,253 @@
+package analyzer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+const (
+	// classifyPrompt names the template for the first, classification stage
+	classifyPrompt = "classify"
+	// defaultPrompt is used for document types without their own template
+	defaultPrompt = "default"
+	// extractPrompt fills in user-defined extraction schemas
+	extractPrompt = "extract"
+	// answerPrompt answers questions from retrieved passages
+	answerPrompt = "answer"
+	// entitiesPrompt lists the named entities of a document
+	entitiesPrompt = "entities"
+	// comparePrompt summarizes the differences between two documents
+	comparePrompt = "compare"
+	// translatePrompt translates the paragraphs of a document
+	translatePrompt = "translate"
+)
+
+// reservedPrompts are templates that are not document types
+var reservedPrompts = map[string]bool{
+	classifyPrompt:  true,
+	defaultPrompt:   true,
+	extractPrompt:   true,
+	answerPrompt:    true,
+	entitiesPrompt:  true,
+	comparePrompt:   true,
+	translatePrompt: true,
+}
+
+// typeAliases maps document types the classifier may return onto the name
+// of the template that handles them
+var typeAliases = map[string]string{
+	"resume":           "cv",
+	"curriculum vitae": "cv",
+	"agreement":        "contract",
+	"bill":             "invoice",
+}
+
+// versionPattern matches the version comment every template starts with,
+// e.g. {{/* version: 2 */ -}}
+var versionPattern = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*([\w.\-]+)\s*\*/`)
+
+// PromptTemplate is a named, versioned text/template
+type PromptTemplate struct {
+	Name    string
+	Version string
+	tmpl    *template.Template
+}
+
+// ID identifies the template and its version, e.g. invoice@1
+func (p *PromptTemplate) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// promptData is what templates are rendered with. Templates become the
+// system message, so document text is deliberately not part of it; the
+// document is sent in a delimited message of its own.
+type promptData struct {
+	LanguageInstructions string
+	// SummaryInstructions sets the style and length of the summary
+	SummaryInstructions string
+	DocumentType        string
+	// Types lists the document types of the taxonomy
+	Types []string
+	// Schema is set for extraction prompts
+	Schema *models.ExtractionSchema
+	// Question is set for question answering prompts
+	Question string
+	// SourceLanguage and TargetLanguage name the languages of translation
+	// prompts, and Paragraphs is how many paragraphs are sent
+	SourceLanguage string
+	TargetLanguage string
+	Paragraphs     int
+}
+
+// samplePromptData is used to check templates when they are loaded
+var samplePromptData = promptData{
+	LanguageInstructions: "Write the summary in English.",
+	SummaryInstructions:  "Write the summary as a concise paragraph of 2-3 sentences.",
+	DocumentType:         "invoice",
+	Types:                []string{"invoice"},
+	Schema: &models.ExtractionSchema{
+		Name:   "sample",
+		Fields: []models.SchemaField{{Name: "field", Type: models.FieldString}},
+	},
+	Question:       "What is this?",
+	SourceLanguage: "English",
+	TargetLanguage: "French",
+	Paragraphs:     2,
+}
+
+func (p *PromptTemplate) render(data promptData) (string, error) {
+	var b bytes.Buffer
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", p.ID(), err)
+	}
+	return b.String(), nil
+}
+
+// Prompts holds the classification template, the default analysis template
+// and one analysis template per document type
+type Prompts struct {
+	templates map[string]*PromptTemplate
+	types     []string
+}
+
+// LoadPrompts parses the templates compiled into the binary and then any
+// *.tmpl files in dir, which replace built-in templates of the same name or
+// add templates for new document types. An empty dir uses only the
+// built-in templates.
+func LoadPrompts(dir string) (*Prompts, error) {
+	p := &Prompts{templates: map[string]*PromptTemplate{}}
+
+	if err := p.loadFS(defaultPrompts, "prompts"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := p.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	for required := range reservedPrompts {
+		if _, ok := p.templates[required]; !ok {
+			return nil, fmt.Errorf("missing %s.tmpl prompt template", required)
+		}
+	}
+
+	for name := range p.templates {
+		if !reservedPrompts[name] {
+			p.types = append(p.types, name)
+		}
+	}
+	sort.Strings(p.types)
+
+	return p, nil
+}
+
+func (p *Prompts) loadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt %s: %w", file, err)
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(path.Base(file), ".tmpl"))
+		m := versionPattern.FindSubmatch(content)
+		if m == nil {
+			return fmt.Errorf("prompt %s must start with a version comment such as {{/* version: 1 */ -}}", file)
+		}
+
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse prompt %s: %w", file, err)
+		}
+		// Catch templates that fail to render, such as ones written for an
+		// older data shape, at startup rather than on the first request
+		if err := tmpl.Execute(io.Discard, samplePromptData); err != nil {
+			if strings.Contains(err.Error(), "can't evaluate field Text ") {
+				return fmt.Errorf("failed to render prompt %s; templates can no longer use .Text, the document is sent in a message of its own: %w", file, err)
+			}
+			return fmt.Errorf("failed to render prompt %s: %w", file, err)
+		}
+
+		p.templates[name] = &PromptTemplate{
+			Name:    name,
+			Version: string(m[1]),
+			tmpl:    tmpl,
+		}
+	}
+
+	return nil
+}
+
+// Types returns the document types that have their own template
+func (p *Prompts) Types() []string {
+	return p.types
+}
+
+// AnalysisVersion identifies the templates an analysis can use: the
+// classification and default templates and those of every document type,
+// e.g. "classify@2,contract@1,default@3"
+func (p *Prompts) AnalysisVersion() string {
+	names := append([]string{classifyPrompt, defaultPrompt}, p.types...)
+	sort.Strings(names)
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = p.templates[name].ID()
+	}
+	return strings.Join(ids, ",")
+}
+
+func (p *Prompts) classifier() *PromptTemplate {
+	return p.templates[classifyPrompt]
+}
+
+func (p *Prompts) extractor() *PromptTemplate {
+	return p.templates[extractPrompt]
+}
+
+func (p *Prompts) answerer() *PromptTemplate {
+	return p.templates[answerPrompt]
+}
+
+func (p *Prompts) entityExtractor() *PromptTemplate {
+	return p.templates[entitiesPrompt]
+}
+
+func (p *Prompts) comparer() *PromptTemplate {
+	return p.templates[comparePrompt]
+}
+
+func (p *Prompts) translator() *PromptTemplate {
+	return p.templates[translatePrompt]
+}
+
+// forType returns the analysis template for a document type, falling back
+// to the default template
+func (p *Prompts) forType(docType string) *PromptTemplate {
+	docType = strings.ToLower(strings.TrimSpace(docType))
+	if alias, ok := typeAliases[docType]; ok {
+		docType = alias
+	}
+	if tmpl, ok := p.templates[docType]; ok && !reservedPrompts[docType] {
+		return tmpl
+	}
+	return p.templates[defaultPrompt]
+}
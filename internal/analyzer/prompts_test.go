@@ -0,0 +1,170 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestLoadPromptsBuiltIn(t *testing.T) {
+	p, err := LoadPrompts("")
+	if err != nil {
+		t.Fatalf("LoadPrompts returned error: %v", err)
+	}
+
+	if got := strings.Join(p.types, ","); got != "contract,cv,invoice" {
+		t.Errorf("types = %q, want %q", got, "contract,cv,invoice")
+	}
+
+	tests := []struct {
+		docType string
+		want    string
+	}{
+		{"invoice", "invoice@3"},
+		{"Resume", "cv@3"},
+		{"agreement", "contract@3"},
+		{"letter", "default@3"},
+		{"", "default@3"},
+		{"classify", "default@3"},
+	}
+
+	for _, tt := range tests {
+		if got := p.forType(tt.docType).ID(); got != tt.want {
+			t.Errorf("forType(%q) = %s, want %s", tt.docType, got, tt.want)
+		}
+	}
+
+	prompt, err := p.forType("invoice").render(promptData{
+		LanguageInstructions: "\nWrite the summary in French.\n",
+		DocumentType:         "invoice",
+	})
+	if err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+	for _, want := range []string{"Write the summary in French.", `"line_items"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("rendered invoice prompt is missing %q", want)
+		}
+	}
+	if strings.HasPrefix(prompt, "\n") {
+		t.Error("rendered prompt starts with the version comment's newline")
+	}
+
+	classify, err := p.classifier().render(promptData{Types: p.types})
+	if err != nil {
+		t.Fatalf("render classify returned error: %v", err)
+	}
+	if !strings.Contains(classify, "contract, cv, invoice") {
+		t.Errorf("classify prompt does not list the types:\n%s", classify)
+	}
+
+	extract, err := p.extractor().render(promptData{
+		Schema: &models.ExtractionSchema{
+			Name: "purchase_order",
+			Fields: []models.SchemaField{
+				{Name: "po_number", Type: models.FieldString, Required: true, Description: "Purchase order number"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("render extract returned error: %v", err)
+	}
+	if !strings.Contains(extract, "- po_number (string, required): Purchase order number") {
+		t.Errorf("extract prompt does not describe the fields:\n%s", extract)
+	}
+}
+
+func TestLoadPromptsOverrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("invoice.tmpl", "{{/* version: 3 */ -}}\nCustom invoice prompt. {{.LanguageInstructions}}")
+	write("receipt.tmpl", "{{/* version: 1 */ -}}\nReceipt prompt.")
+
+	p, err := LoadPrompts(dir)
+	if err != nil {
+		t.Fatalf("LoadPrompts returned error: %v", err)
+	}
+
+	if got := p.forType("invoice").ID(); got != "invoice@3" {
+		t.Errorf("invoice template = %s, want invoice@3", got)
+	}
+	if got := p.forType("receipt").ID(); got != "receipt@1" {
+		t.Errorf("receipt template = %s, want receipt@1", got)
+	}
+	if got := p.forType("cv").ID(); got != "cv@3" {
+		t.Errorf("cv template = %s, want the built-in cv@3", got)
+	}
+
+	// Documents are sent in a message of their own, so a template written
+	// for the old data shape is rejected when it is loaded
+	write("receipt.tmpl", "{{/* version: 1 */ -}}\nReceipt prompt: {{.Text}}")
+	if _, err := LoadPrompts(dir); err == nil || !strings.Contains(err.Error(), "receipt.tmpl") || !strings.Contains(err.Error(), "no longer use .Text") {
+		t.Errorf("error = %v, want receipt.tmpl rejected for using .Text", err)
+	}
+
+	// Other render failures are reported as they are, without the hint
+	write("receipt.tmpl", "{{/* version: 1 */ -}}\nReceipt prompt: {{.Vendor}}")
+	_, err = LoadPrompts(dir)
+	if err == nil || !strings.Contains(err.Error(), "Vendor") || strings.Contains(err.Error(), ".Text") {
+		t.Errorf("error = %v, want the missing Vendor field reported", err)
+	}
+	write("receipt.tmpl", "{{/* version: 1 */ -}}\nReceipt prompt: {{index .Types 5}}")
+	_, err = LoadPrompts(dir)
+	if err == nil || !strings.Contains(err.Error(), "index out of range") || strings.Contains(err.Error(), ".Text") {
+		t.Errorf("error = %v, want the index error reported", err)
+	}
+	write("receipt.tmpl", "{{/* version: 1 */ -}}\nReceipt prompt.")
+
+	write("memo.tmpl", "Memo prompt without a version")
+	if _, err := LoadPrompts(dir); err == nil {
+		t.Error("LoadPrompts accepted a template without a version comment")
+	}
+}
+
+func TestParseAnalysisTypeSchema(t *testing.T) {
+	content := `{
+  "summary": "Invoice for two items.",
+  "document_type": "invoice",
+  "metadata": {
+    "due_date": "April 1, 2024",
+    "tax": "$16",
+    "line_items": [
+      {"description": "Widget", "quantity": 2, "unit_price": "50", "amount": "100"},
+      null
+    ]
+  }
+}`
+
+	result, err := parseAnalysis(content, schemaFor("invoice"))
+	if err != nil {
+		t.Fatalf("parseAnalysis returned error: %v", err)
+	}
+
+	if got := result.Metadata["due_date"]; got != "2024-04-01" {
+		t.Errorf("due_date = %v, want 2024-04-01", got)
+	}
+	if got := result.Metadata["tax"]; got != "16.00" {
+		t.Errorf("tax = %v, want 16.00", got)
+	}
+
+	items, ok := result.Metadata["line_items"].([]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatalf("line_items = %#v, want one item", result.Metadata["line_items"])
+	}
+	item := items[0].(map[string]interface{})
+	if item["quantity"] != "2" || item["unit_price"] != "50.00" {
+		t.Errorf("line item = %v, want quantity 2 and unit_price 50.00", item)
+	}
+
+	_, err = parseAnalysis(`{"summary": "s", "document_type": "cv", "metadata": {"skills": "Go, SQL"}}`, schemaFor("cv"))
+	if err == nil || !strings.Contains(err.Error(), "metadata.skills must be an array") {
+		t.Errorf("error = %v, want metadata.skills must be an array", err)
+	}
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+const validCompletion = `{"choices":[{"message":{"role":"assistant","content":"{\"summary\":\"A short letter.\",\"document_type\":\"letter\",\"metadata\":{}}"}}],"usage":{"prompt_tokens":120,"completion_tokens":30,"total_tokens":150}}`
+
+// stubProvider answers each request with the next status in statuses,
+// repeating the last one, and replies with a valid completion on 200
+func stubProvider(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(validCompletion))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func newTestAnalyzer(baseURL string, maxRetries, threshold int) *openRouterAnalyzer {
+	return NewOpenRouterAnalyzer(OpenRouterConfig{
+		APIKey:  "test-key",
+		Model:   "test/model",
+		BaseURL: baseURL,
+		Retry: RetryPolicy{
+			MaxRetries: maxRetries,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   5 * time.Millisecond,
+		},
+		BreakerThreshold: threshold,
+		BreakerCooldown:  time.Minute,
+	}, utils.NewLogger("error")).(*openRouterAnalyzer)
+}
+
+func TestAnalyzeRetriesTransientErrors(t *testing.T) {
+	server, calls := stubProvider(t, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK)
+	a := newTestAnalyzer(server.URL, 3, 5)
+
+	result, err := a.Analyze(context.Background(), "Dear Sir, thank you.", Options{})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if result.DocumentType != "letter" {
+		t.Errorf("document_type = %q, want %q", result.DocumentType, "letter")
+	}
+	// Two failures and the classification call, then the analysis call
+	if got := calls.Load(); got != 4 {
+		t.Errorf("provider called %d times, want 4", got)
+	}
+	if result.Usage.PromptTokens != 240 || result.Usage.CompletionTokens != 60 {
+		t.Errorf("usage = %+v, want both calls summed to 240 prompt and 60 completion tokens", result.Usage)
+	}
+	if state := a.breaker.Snapshot().State; state != CircuitClosed {
+		t.Errorf("breaker state = %q, want %q", state, CircuitClosed)
+	}
+}
+
+func TestAnalyzeDoesNotRetryPermanentErrors(t *testing.T) {
+	server, calls := stubProvider(t, http.StatusUnauthorized)
+	a := newTestAnalyzer(server.URL, 3, 1)
+
+	_, err := a.Analyze(context.Background(), "text", Options{})
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("error = %v, want provider error with status 401", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+	if state := a.breaker.Snapshot().State; state != CircuitClosed {
+		t.Errorf("breaker state = %q, want %q", state, CircuitClosed)
+	}
+}
+
+func TestAnalyzeOpensCircuitWhenProviderIsDown(t *testing.T) {
+	server, calls := stubProvider(t, http.StatusServiceUnavailable)
+	a := newTestAnalyzer(server.URL, 1, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Analyze(context.Background(), "text", Options{}); err == nil {
+			t.Fatal("Analyze succeeded against a failing provider")
+		}
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("provider called %d times, want 4", got)
+	}
+
+	_, err := a.Analyze(context.Background(), "text", Options{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("provider called while circuit open, %d calls", got)
+	}
+
+	status := a.ProviderStatus()[0]
+	if status.CircuitState != string(CircuitOpen) || status.RetryAt == nil {
+		t.Errorf("status = %+v, want open circuit with retry time", status)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	b := NewCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	if !b.Allow() {
+		t.Fatal("closed breaker rejected a call")
+	}
+	b.Failure(errors.New("boom"))
+	if b.Allow() {
+		t.Fatal("open breaker allowed a call before cooldown")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker did not allow a probe after cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("half open breaker allowed a second concurrent probe")
+	}
+
+	b.Failure(errors.New("still down"))
+	if got := b.Snapshot().State; got != CircuitOpen {
+		t.Fatalf("state after failed probe = %q, want %q", got, CircuitOpen)
+	}
+
+	now = now.Add(time.Minute)
+	b.Allow()
+	b.Success()
+	if got := b.Snapshot(); got.State != CircuitClosed || got.ConsecutiveFailures != 0 {
+		t.Errorf("snapshot after successful probe = %+v, want closed with no failures", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-time.Second).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		ceiling := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < 0 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, d, ceiling)
+			}
+		}
+	}
+}
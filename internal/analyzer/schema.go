@@ -0,0 +1,658 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindDate
+	kindAmount
+	kindCurrency
+	kindObject
+	kindList // array of strings, or of objects when fields is set
+	kindNumber
+	kindInteger
+	kindBoolean
+)
+
+type fieldSchema struct {
+	name     string
+	kind     fieldKind
+	required bool // must be present and non-null
+	fields   []fieldSchema
+}
+
+// metadataFields are the metadata keys every analysis prompt asks for
+var metadataFields = []fieldSchema{
+	{name: "date", kind: kindDate},
+	{name: "sender", kind: kindString},
+	{name: "recipient", kind: kindString},
+	{name: "amount", kind: kindAmount},
+	{name: "currency", kind: kindCurrency},
+	{name: "company", kind: kindString},
+}
+
+// typeMetadataFields are the extra metadata keys asked for by the built-in
+// per-type templates
+var typeMetadataFields = map[string][]fieldSchema{
+	"invoice": {
+		{name: "due_date", kind: kindDate},
+		{name: "invoice_number", kind: kindString},
+		{name: "subtotal", kind: kindAmount},
+		{name: "tax", kind: kindAmount},
+		{name: "line_items", kind: kindList, fields: []fieldSchema{
+			{name: "description", kind: kindString},
+			{name: "quantity", kind: kindString},
+			{name: "unit_price", kind: kindAmount},
+			{name: "amount", kind: kindAmount},
+		}},
+	},
+	"cv": {
+		{name: "skills", kind: kindList},
+		{name: "experience", kind: kindList, fields: []fieldSchema{
+			{name: "title", kind: kindString},
+			{name: "organization", kind: kindString},
+			{name: "start_date", kind: kindString},
+			{name: "end_date", kind: kindString},
+		}},
+		{name: "education", kind: kindList, fields: []fieldSchema{
+			{name: "institution", kind: kindString},
+			{name: "qualification", kind: kindString},
+			{name: "year", kind: kindString},
+		}},
+	},
+	"contract": {
+		{name: "parties", kind: kindList},
+		{name: "effective_date", kind: kindDate},
+		{name: "term", kind: kindString},
+		{name: "termination_clauses", kind: kindList},
+		{name: "governing_law", kind: kindString},
+	},
+}
+
+// analysisSchema declares the JSON the default analysis prompt asks for.
+// Metadata keys not listed here are passed through untouched.
+var analysisSchema = schemaFor("")
+
+// schemaFor returns the schema for the template of a document type, which
+// adds that type's metadata fields to the common ones
+func schemaFor(templateName string) []fieldSchema {
+	metadata := append(append([]fieldSchema{}, metadataFields...), typeMetadataFields[templateName]...)
+
+	return []fieldSchema{
+		{name: "summary", kind: kindString, required: true},
+		{name: "document_type", kind: kindString, required: true},
+		{name: "metadata", kind: kindObject, required: true, fields: metadata},
+	}
+}
+
+// ValidationError lists every problem found in a model response so they
+// can be sent back to the model in a single repair request
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid LLM response: " + strings.Join(e.Problems, "; ")
+}
+
+// parseAnalysis extracts the JSON object from a model response, validates it
+// against schema and coerces values to their canonical form: dates to
+// YYYY-MM-DD, amounts to decimal strings with two places and currencies to
+// ISO 4217 codes.
+func parseAnalysis(content string, schema []fieldSchema) (*models.LLMAnalysisResult, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	// A currency symbol in the amount fills in a missing currency
+	if metadata, ok := raw["metadata"].(map[string]interface{}); ok {
+		inferCurrency(metadata)
+	}
+
+	v := &validator{}
+	v.object("", schema, raw)
+
+	if len(v.problems) > 0 {
+		return nil, &ValidationError{Problems: v.problems}
+	}
+
+	result := &models.LLMAnalysisResult{
+		Summary:      raw["summary"].(string),
+		DocumentType: strings.ToLower(raw["document_type"].(string)),
+		Metadata:     raw["metadata"].(map[string]interface{}),
+	}
+
+	return result, nil
+}
+
+// fieldKinds maps user-facing field types onto validator kinds
+var fieldKinds = map[models.FieldType]fieldKind{
+	models.FieldString:   kindString,
+	models.FieldNumber:   kindNumber,
+	models.FieldInteger:  kindInteger,
+	models.FieldBoolean:  kindBoolean,
+	models.FieldDate:     kindDate,
+	models.FieldAmount:   kindAmount,
+	models.FieldCurrency: kindCurrency,
+	models.FieldList:     kindList,
+}
+
+// extractionFields converts the fields of a user-defined schema
+func extractionFields(fields []models.SchemaField) []fieldSchema {
+	schema := make([]fieldSchema, 0, len(fields))
+	for _, field := range fields {
+		schema = append(schema, fieldSchema{
+			name:     field.Name,
+			kind:     fieldKinds[field.Type],
+			required: field.Required,
+		})
+	}
+	return schema
+}
+
+// parseExtraction validates a model response against the fields of a
+// user-defined schema. Keys outside the schema are dropped and missing
+// optional fields are set to null, so every result has the same shape.
+func parseExtraction(content string, fields []fieldSchema) (map[string]interface{}, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	v := &validator{}
+	v.object("", fields, raw)
+
+	if len(v.problems) > 0 {
+		return nil, &ValidationError{Problems: v.problems}
+	}
+
+	data := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		data[field.name] = raw[field.name]
+	}
+
+	return data, nil
+}
+
+// answerSchema declares the JSON the question answering prompt asks for
+var answerSchema = []fieldSchema{
+	{name: "answer", kind: kindString, required: true},
+	{name: "found", kind: kindBoolean},
+	{name: "quotes", kind: kindList, fields: []fieldSchema{
+		{name: "passage", kind: kindInteger},
+		{name: "text", kind: kindString, required: true},
+	}},
+}
+
+// parseAnswer validates a question answering response
+func parseAnswer(content string) (*models.AnswerResult, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	v := &validator{}
+	v.object("", answerSchema, raw)
+
+	if len(v.problems) > 0 {
+		return nil, &ValidationError{Problems: v.problems}
+	}
+
+	result := &models.AnswerResult{
+		Answer: raw["answer"].(string),
+		Quotes: []models.Quote{},
+	}
+	if found, ok := raw["found"].(bool); ok {
+		result.Found = found
+	}
+
+	quotes, _ := raw["quotes"].([]interface{})
+	for _, item := range quotes {
+		quote := item.(map[string]interface{})
+		passage, _ := quote["passage"].(int64)
+		result.Quotes = append(result.Quotes, models.Quote{
+			Passage: int(passage),
+			Text:    quote["text"].(string),
+		})
+	}
+
+	return result, nil
+}
+
+// decodeObject parses the first JSON object in content, ignoring code fences
+// and any prose the model wrapped around it
+func decodeObject(content string) (map[string]interface{}, error) {
+	content = extractJSON(content)
+
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("response is not a valid JSON object: %v", err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("response is not a JSON object")
+	}
+
+	return raw, nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// object validates fields in place, replacing values with their coerced form
+func (v *validator) object(path string, fields []fieldSchema, obj map[string]interface{}) {
+	for _, field := range fields {
+		name := path + field.name
+		value, present := obj[field.name]
+
+		if isNull(value) {
+			if field.required {
+				if present {
+					v.addf("%s must not be null", name)
+				} else {
+					v.addf("%s is missing", name)
+				}
+				continue
+			}
+			if field.kind != kindObject {
+				obj[field.name] = nil
+			}
+			continue
+		}
+
+		switch field.kind {
+		case kindString:
+			s, ok := toString(value)
+			if !ok {
+				v.addf("%s must be a string", name)
+				continue
+			}
+			obj[field.name] = s
+		case kindDate:
+			date, err := normalizeDate(value)
+			if err != nil {
+				v.addf("%s %v", name, err)
+				continue
+			}
+			obj[field.name] = date
+		case kindAmount:
+			amount, err := normalizeAmount(value)
+			if err != nil {
+				v.addf("%s %v", name, err)
+				continue
+			}
+			obj[field.name] = amount
+		case kindCurrency:
+			code, err := normalizeCurrency(value)
+			if err != nil {
+				v.addf("%s %v", name, err)
+				continue
+			}
+			obj[field.name] = code
+		case kindObject:
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				v.addf("%s must be an object", name)
+				continue
+			}
+			v.object(name+".", field.fields, nested)
+		case kindNumber, kindInteger:
+			number, err := normalizeNumber(value, field.kind == kindInteger)
+			if err != nil {
+				v.addf("%s %v", name, err)
+				continue
+			}
+			obj[field.name] = number
+		case kindBoolean:
+			b, err := normalizeBoolean(value)
+			if err != nil {
+				v.addf("%s %v", name, err)
+				continue
+			}
+			obj[field.name] = b
+		case kindList:
+			items, ok := value.([]interface{})
+			if !ok {
+				v.addf("%s must be an array", name)
+				continue
+			}
+			obj[field.name] = v.list(name, field.fields, items)
+		}
+	}
+}
+
+// list validates the items of an array, dropping null items. Items are
+// strings unless fields describes them as objects.
+func (v *validator) list(name string, fields []fieldSchema, items []interface{}) []interface{} {
+	kept := []interface{}{}
+	for i, item := range items {
+		if isNull(item) {
+			continue
+		}
+
+		itemName := fmt.Sprintf("%s[%d]", name, i)
+		if fields == nil {
+			s, ok := toString(item)
+			if !ok {
+				v.addf("%s must be a string", itemName)
+				continue
+			}
+			kept = append(kept, s)
+			continue
+		}
+
+		nested, ok := item.(map[string]interface{})
+		if !ok {
+			v.addf("%s must be an object", itemName)
+			continue
+		}
+		v.object(itemName+".", fields, nested)
+		kept = append(kept, nested)
+	}
+	return kept
+}
+
+// isNull treats missing values, JSON null and the strings models use for
+// "nothing found" alike
+func isNull(value interface{}) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "", "null", "none", "n/a", "unknown":
+			return true
+		}
+	}
+	return false
+}
+
+func toString(value interface{}) (string, bool) {
+	switch val := value.(type) {
+	case string:
+		return strings.TrimSpace(val), true
+	case json.Number:
+		return val.String(), true
+	case bool:
+		return fmt.Sprint(val), true
+	}
+	return "", false
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"02-Jan-2006",
+	"Monday, January 2, 2006",
+	"02.01.2006",
+}
+
+var slashDatePattern = regexp.MustCompile(`^(\d{1,2})[/-](\d{1,2})[/-](\d{4})$`)
+var ordinalPattern = regexp.MustCompile(`(\d)(st|nd|rd|th)\b`)
+
+// normalizeDate converts common date spellings to YYYY-MM-DD. Numeric
+// day/month dates are only accepted when the order is unambiguous.
+func normalizeDate(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("must be a date string in YYYY-MM-DD format")
+	}
+	s = ordinalPattern.ReplaceAllString(strings.TrimSpace(s), "$1")
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+
+	if m := slashDatePattern.FindStringSubmatch(s); m != nil {
+		var first, second int
+		fmt.Sscan(m[1], &first)
+		fmt.Sscan(m[2], &second)
+
+		var layout string
+		switch {
+		case first > 12 && second <= 12:
+			layout = "2/1/2006"
+		case second > 12 && first <= 12:
+			layout = "1/2/2006"
+		default:
+			return "", fmt.Errorf("%q is ambiguous; use YYYY-MM-DD format", s)
+		}
+
+		normalized := strings.ReplaceAll(s, "-", "/")
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+
+	return "", fmt.Errorf("%q is not a recognised date; use YYYY-MM-DD format", s)
+}
+
+// normalizeNumber converts JSON numbers and numeric strings such as "1,200"
+// to float64, or int64 when integer is set
+func normalizeNumber(value interface{}, integer bool) (interface{}, error) {
+	var s string
+	switch val := value.(type) {
+	case json.Number:
+		s = val.String()
+	case string:
+		s = normalizeSeparators(strings.ReplaceAll(strings.TrimSpace(val), " ", ""))
+	default:
+		return nil, fmt.Errorf("must be a number")
+	}
+
+	if integer {
+		n, ok := new(big.Rat).SetString(s)
+		if !ok || !n.IsInt() || !n.Num().IsInt64() {
+			return nil, fmt.Errorf("%v is not a whole number", value)
+		}
+		return n.Num().Int64(), nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+	return f, nil
+}
+
+// normalizeBoolean accepts JSON booleans and yes/no style strings
+func normalizeBoolean(value interface{}) (bool, error) {
+	switch val := value.(type) {
+	case bool:
+		return val, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "yes", "y":
+			return true, nil
+		case "false", "no", "n":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("must be true or false")
+}
+
+var amountCleanPattern = regexp.MustCompile(`[^\d.,\-]`)
+
+// normalizeAmount converts numbers and formatted amounts such as "$1,500" or
+// "1.500,00 €" to a decimal string with two places
+func normalizeAmount(value interface{}) (string, error) {
+	var s string
+	switch val := value.(type) {
+	case json.Number:
+		s = val.String()
+	case string:
+		s = amountCleanPattern.ReplaceAllString(val, "")
+		s = normalizeSeparators(s)
+	default:
+		return "", fmt.Errorf("must be a number or numeric string")
+	}
+
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok || s == "" {
+		return "", fmt.Errorf("%v is not a valid amount; use a decimal number such as 1500.00", value)
+	}
+
+	return amount.FloatString(2), nil
+}
+
+// normalizeSeparators resolves thousands and decimal separators. The last
+// separator is the decimal point when both kinds appear; a lone comma is a
+// decimal point only when followed by one or two digits.
+func normalizeSeparators(s string) string {
+	lastComma := strings.LastIndex(s, ",")
+	lastDot := strings.LastIndex(s, ".")
+
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(s, ",") == 1 && len(s)-lastComma-1 <= 2 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case strings.Count(s, ".") > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+
+	return s
+}
+
+// currencyAliases maps symbols and names models commonly return to ISO 4217
+var currencyAliases = map[string]string{
+	"$":         "USD",
+	"us$":       "USD",
+	"dollar":    "USD",
+	"dollars":   "USD",
+	"€":         "EUR",
+	"euro":      "EUR",
+	"euros":     "EUR",
+	"£":         "GBP",
+	"pound":     "GBP",
+	"pounds":    "GBP",
+	"¥":         "JPY",
+	"yen":       "JPY",
+	"₹":         "INR",
+	"rupee":     "INR",
+	"rupees":    "INR",
+	"₦":         "NGN",
+	"naira":     "NGN",
+	"ksh":       "KES",
+	"kshs":      "KES",
+	"shilling":  "KES",
+	"shillings": "KES",
+	"rand":      "ZAR",
+	"cfa":       "XOF",
+}
+
+// iso4217 lists the active ISO 4217 currency codes
+var iso4217 = map[string]bool{}
+
+func init() {
+	codes := `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL
+BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP
+ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL
+LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD
+SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX
+USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL`
+	for _, code := range strings.Fields(codes) {
+		iso4217[code] = true
+	}
+}
+
+// normalizeCurrency maps a currency symbol, name or code to its ISO 4217 code
+func normalizeCurrency(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("must be an ISO 4217 currency code")
+	}
+
+	s = strings.TrimSpace(s)
+	if code := strings.ToUpper(s); iso4217[code] {
+		return code, nil
+	}
+	if code, ok := currencyAliases[strings.ToLower(strings.TrimSuffix(s, "."))]; ok {
+		return code, nil
+	}
+
+	return "", fmt.Errorf("%q is not an ISO 4217 currency code such as USD, EUR or KES", s)
+}
+
+var currencyPrefixPattern = regexp.MustCompile(`^\s*([^\d\s.,\-]+)`)
+var currencySuffixPattern = regexp.MustCompile(`([^\d\s.,\-]+)\s*$`)
+
+// inferCurrency fills a missing currency from a symbol or code written in
+// the raw amount, before the amount is coerced
+func inferCurrency(metadata map[string]interface{}) {
+	if !isNull(metadata["currency"]) {
+		return
+	}
+
+	amount, ok := metadata["amount"].(string)
+	if !ok {
+		return
+	}
+
+	for _, pattern := range []*regexp.Regexp{currencyPrefixPattern, currencySuffixPattern} {
+		if m := pattern.FindStringSubmatch(amount); m != nil {
+			if code, err := normalizeCurrency(m[1]); err == nil {
+				metadata["currency"] = code
+				return
+			}
+		}
+	}
+}
+
+// repairPrompt asks the model to fix the problems found in its last answer
+func repairPrompt(err *ValidationError) string {
+	var b bytes.Buffer
+	b.WriteString("Your previous response did not match the required JSON structure:\n")
+	for _, problem := range err.Problems {
+		b.WriteString("- ")
+		b.WriteString(problem)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nRespond again with ONLY the corrected JSON object (no markdown, no code blocks, no commentary). ")
+	b.WriteString("Use YYYY-MM-DD for dates, a decimal string such as \"1500.00\" for amounts, an ISO 4217 code for currency and null for values that are not found.")
+	return b.String()
+}
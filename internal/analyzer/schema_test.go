@@ -0,0 +1,218 @@
+package analyzer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestParseAnalysisCoercesValues(t *testing.T) {
+	content := "Here is the analysis:\n```json\n" + `{
+  "summary": "Invoice for consulting {services}.",
+  "document_type": "Invoice",
+  "metadata": {
+    "date": "March 5th, 2024",
+    "sender": "Jane Doe",
+    "recipient": null,
+    "amount": 1500,
+    "currency": "$",
+    "company": "N/A",
+    "po_number": "PO-77"
+  }
+}` + "\n```\nLet me know if you need anything else."
+
+	result, err := parseAnalysis(content, analysisSchema)
+	if err != nil {
+		t.Fatalf("parseAnalysis returned error: %v", err)
+	}
+
+	if result.DocumentType != "invoice" {
+		t.Errorf("document_type = %q, want %q", result.DocumentType, "invoice")
+	}
+
+	want := map[string]interface{}{
+		"date":      "2024-03-05",
+		"sender":    "Jane Doe",
+		"recipient": nil,
+		"amount":    "1500.00",
+		"currency":  "USD",
+		"company":   nil,
+		"po_number": "PO-77",
+	}
+	for key, value := range want {
+		if result.Metadata[key] != value {
+			t.Errorf("metadata[%s] = %#v, want %#v", key, result.Metadata[key], value)
+		}
+	}
+}
+
+func TestParseAnalysisReportsProblems(t *testing.T) {
+	content := `{"document_type": "letter", "metadata": {"date": "04/05/2024", "currency": "dollars-ish"}}`
+
+	_, err := parseAnalysis(content, analysisSchema)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("got %d problems, want 3: %v", len(validationErr.Problems), validationErr.Problems)
+	}
+}
+
+func TestParseAnalysisRejectsNonJSON(t *testing.T) {
+	_, err := parseAnalysis("I could not analyze this document.", analysisSchema)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1500", "1500.00"},
+		{"$1,500.5", "1500.50"},
+		{"1.500,00 €", "1500.00"},
+		{"KES 25,000", "25000.00"},
+		{"12,5", "12.50"},
+		{"1.234.567", "1234567.00"},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeAmount(tt.in)
+		if err != nil {
+			t.Errorf("normalizeAmount(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeAmount(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "2024-01-31", want: "2024-01-31"},
+		{in: "31/01/2024", want: "2024-01-31"},
+		{in: "01/31/2024", want: "2024-01-31"},
+		{in: "2 January 2024", want: "2024-01-02"},
+		{in: "2024-01-31T10:00:00Z", want: "2024-01-31"},
+		{in: "03/04/2024", wantErr: true},
+		{in: "sometime in spring", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeDate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeDate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := map[string]string{
+		"usd":   "USD",
+		"€":     "EUR",
+		"Ksh":   "KES",
+		"naira": "NGN",
+	}
+
+	for in, want := range tests {
+		got, err := normalizeCurrency(in)
+		if err != nil || got != want {
+			t.Errorf("normalizeCurrency(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	if _, err := normalizeCurrency("ABC"); err == nil {
+		t.Errorf("normalizeCurrency(%q) succeeded, want error", "ABC")
+	}
+}
+
+func TestParseExtraction(t *testing.T) {
+	fields := extractionFields([]models.SchemaField{
+		{Name: "po_number", Type: models.FieldString, Required: true},
+		{Name: "quantity", Type: models.FieldInteger},
+		{Name: "weight", Type: models.FieldNumber},
+		{Name: "urgent", Type: models.FieldBoolean},
+		{Name: "delivery_date", Type: models.FieldDate},
+		{Name: "total", Type: models.FieldAmount},
+		{Name: "currency", Type: models.FieldCurrency},
+		{Name: "items", Type: models.FieldList},
+		{Name: "notes", Type: models.FieldString},
+	})
+
+	content := `{
+  "po_number": "PO-1001",
+  "quantity": "1,200",
+  "weight": 12.5,
+  "urgent": "yes",
+  "delivery_date": "May 3rd, 2024",
+  "total": "€2.400,50",
+  "currency": "euro",
+  "items": ["bolts", null, 42],
+  "supplier": "not in the schema"
+}`
+
+	data, err := parseExtraction(content, fields)
+	if err != nil {
+		t.Fatalf("parseExtraction returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"po_number":     "PO-1001",
+		"quantity":      int64(1200),
+		"weight":        12.5,
+		"urgent":        true,
+		"delivery_date": "2024-05-03",
+		"total":         "2400.50",
+		"currency":      "EUR",
+		"items":         []interface{}{"bolts", "42"},
+		"notes":         nil,
+	}
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %#v\nwant %#v", data, want)
+	}
+
+	_, err = parseExtraction(`{"po_number": null, "quantity": 2.5}`, fields)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 2 {
+		t.Fatalf("error = %v, want two validation problems", err)
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	content := `{"answer": "Invoices are due within 30 days.", "found": true,
+  "quotes": [{"passage": "2", "text": "payable within 30 days"}, null]}`
+
+	result, err := parseAnswer(content)
+	if err != nil {
+		t.Fatalf("parseAnswer returned error: %v", err)
+	}
+	if !result.Found || result.Answer != "Invoices are due within 30 days." {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Quotes) != 1 || result.Quotes[0].Passage != 2 || result.Quotes[0].Text != "payable within 30 days" {
+		t.Errorf("quotes = %+v, want passage 2 quote", result.Quotes)
+	}
+
+	if _, err := parseAnswer(`{"found": false, "quotes": []}`); err == nil {
+		t.Error("parseAnswer accepted a response without an answer")
+	}
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// TokenSink receives model output as it is generated. Restart means the
+// output so far is discarded, because the request is being retried, the
+// answer is being repaired or the next model in a fallback chain is used.
+// Stage is called as each stage of the analysis starts, so a client hears
+// from the server while the classification, which is not streamed, runs.
+type TokenSink interface {
+	Token(text string)
+	Restart(reason string)
+	Stage(name string)
+}
+
+// Analysis stages reported to a TokenSink
+const (
+	StageClassify = "classify"
+	StageAnalyze  = "analyze"
+)
+
+// StreamAnalyzer is implemented by analyzers that can stream the analysis
+// stage to a TokenSink while it runs. The result is the same as Analyze.
+type StreamAnalyzer interface {
+	AnalyzeStream(ctx context.Context, text string, opts Options, sink TokenSink) (*models.LLMAnalysisResult, error)
+}
+
+func (a *openRouterAnalyzer) AnalyzeStream(ctx context.Context, text string, opts Options, sink TokenSink) (*models.LLMAnalysisResult, error) {
+	return a.analyze(ctx, text, opts, sink)
+}
+
+func (a *fallbackAnalyzer) AnalyzeStream(ctx context.Context, text string, opts Options, sink TokenSink) (*models.LLMAnalysisResult, error) {
+	first := true
+	return tryEach(ctx, a, func(next Analyzer) (*models.LLMAnalysisResult, error) {
+		if !first {
+			sink.Restart("fallback")
+		}
+		first = false
+
+		if streamer, ok := next.(StreamAnalyzer); ok {
+			return streamer.AnalyzeStream(ctx, text, opts, sink)
+		}
+		return next.Analyze(ctx, text, opts)
+	})
+}
+
+// readStream reads a server-sent events completion stream, passing content
+// deltas to sink, and returns the full content. Comment lines such as
+// OpenRouter's ": OPENROUTER PROCESSING" keep-alives are skipped.
+func readStream(ctx context.Context, body io.Reader, sink TokenSink) (string, models.TokenUsage, error) {
+	var content strings.Builder
+	var usage models.TokenUsage
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk OpenRouterResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", usage, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+
+		if chunk.Error != nil {
+			return "", usage, chunk.Error.providerError()
+		}
+		if chunk.Usage != nil {
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			delta := chunk.Choices[0].Delta.Content
+			content.WriteString(delta)
+			sink.Token(delta)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", usage, &ProviderError{
+			Message:   "failed to read stream",
+			Retryable: ctx.Err() == nil,
+			Err:       err,
+		}
+	}
+
+	if content.Len() == 0 {
+		return "", usage, fmt.Errorf("no content in stream")
+	}
+
+	return content.String(), usage, nil
+}
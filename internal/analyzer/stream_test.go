@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type recordingSink struct {
+	tokens   []string
+	restarts []string
+	stages   []string
+}
+
+func (s *recordingSink) Token(text string)     { s.tokens = append(s.tokens, text) }
+func (s *recordingSink) Restart(reason string) { s.restarts = append(s.restarts, reason) }
+func (s *recordingSink) Stage(name string)     { s.stages = append(s.stages, name) }
+
+// streamingProvider answers the classification call with a plain completion
+// and streams the analysis in small pieces. With breakFirst, the first
+// stream reports an upstream error part way through.
+func streamingProvider(t *testing.T, breakFirst bool) *httptest.Server {
+	t.Helper()
+
+	analysis := `{"summary":"A short letter.","document_type":"letter","metadata":{}}`
+	var streams atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			w.Write([]byte(validCompletion))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": OPENROUTER PROCESSING\n\n")
+
+		n := streams.Add(1)
+		for i := 0; i < len(analysis); i += 10 {
+			if breakFirst && n == 1 && i >= 20 {
+				fmt.Fprint(w, "data: {\"error\":{\"message\":\"upstream overloaded\",\"code\":502}}\n\n")
+				return
+			}
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", analysis[i:min(i+10, len(analysis))])
+		}
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":80,\"completion_tokens\":20}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAnalyzeStreamRelaysTokens(t *testing.T) {
+	a := newTestAnalyzer(streamingProvider(t, false).URL, 3, 5)
+	sink := &recordingSink{}
+
+	result, err := a.AnalyzeStream(context.Background(), "Dear Sir, thank you.", Options{}, sink)
+	if err != nil {
+		t.Fatalf("AnalyzeStream returned error: %v", err)
+	}
+	if result.DocumentType != "letter" || result.Summary != "A short letter." {
+		t.Errorf("result = %+v, want the streamed letter analysis", result)
+	}
+	if len(sink.tokens) < 2 {
+		t.Errorf("got %d tokens, want the analysis in several pieces", len(sink.tokens))
+	}
+	if got := strings.Join(sink.tokens, ""); !strings.HasPrefix(got, `{"summary"`) {
+		t.Errorf("tokens = %q, want the streamed analysis", got)
+	}
+	if len(sink.restarts) != 0 {
+		t.Errorf("restarts = %v, want none", sink.restarts)
+	}
+	if got := strings.Join(sink.stages, ","); got != "classify,analyze" {
+		t.Errorf("stages = %s, want classify,analyze", got)
+	}
+	// Classification usage plus the usage reported at the end of the stream
+	if result.Usage.PromptTokens != 200 || result.Usage.CompletionTokens != 50 {
+		t.Errorf("usage = %+v, want 200 prompt and 50 completion tokens", result.Usage)
+	}
+}
+
+func TestAnalyzeStreamRestartsAfterStreamError(t *testing.T) {
+	a := newTestAnalyzer(streamingProvider(t, true).URL, 3, 5)
+	sink := &recordingSink{}
+
+	result, err := a.AnalyzeStream(context.Background(), "Dear Sir, thank you.", Options{}, sink)
+	if err != nil {
+		t.Fatalf("AnalyzeStream returned error: %v", err)
+	}
+	if result.DocumentType != "letter" {
+		t.Errorf("document_type = %q, want %q", result.DocumentType, "letter")
+	}
+	if len(sink.restarts) != 1 || sink.restarts[0] != "retry" {
+		t.Fatalf("restarts = %v, want a single retry", sink.restarts)
+	}
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// summaryForms tells the model how to write each style of summary, with a
+// size for each length
+var summaryForms = map[string]struct {
+	form  string
+	sizes [3]string
+}{
+	models.SummaryStandard: {
+		form:  "Write the summary as a concise paragraph of %s.",
+		sizes: [3]string{"one or two sentences", "2-3 sentences", "4-6 sentences"},
+	},
+	models.SummaryHeadline: {
+		form:  "Write the summary as a single headline of at most %s, without a final period.",
+		sizes: [3]string{"8 words", "15 words", "25 words"},
+	},
+	models.SummaryExecutive: {
+		form:  "Write the summary as an executive paragraph of %s for a decision maker: the purpose of the document, the key facts and figures, and anything that needs to be decided or done.",
+		sizes: [3]string{"2-3 sentences", "4-6 sentences", "7-10 sentences"},
+	},
+	models.SummaryBullets: {
+		form:  "Write the summary as %s, one per line, each line starting with \"- \".",
+		sizes: [3]string{"3 key takeaways", "5 key takeaways", "8 key takeaways"},
+	},
+	models.SummaryDetailed: {
+		form:  "Write the summary section by section in document order, one line per section, each line starting with the section's name and a colon followed by %s.",
+		sizes: [3]string{"one sentence", "two or three sentences", "up to five sentences"},
+	},
+}
+
+// lengthIndex maps a summary length onto the sizes of summaryForms and
+// builtinSizes; an empty length is medium
+func lengthIndex(length string) int {
+	switch length {
+	case models.SummaryShort:
+		return 0
+	case models.SummaryLong:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// summaryInstructions describes the summary style and length of opts to the
+// model; an empty style is standard
+func summaryInstructions(opts Options) string {
+	form, ok := summaryForms[opts.Style]
+	if !ok {
+		form = summaryForms[models.SummaryStandard]
+	}
+	return fmt.Sprintf(form.form, form.sizes[lengthIndex(opts.Length)])
+}
+
+// builtinSizes is how many sentences the builtin analyzer picks for each
+// style and length; headlines count words and detailed summaries count
+// sentences per section
+var builtinSizes = map[string][3]int{
+	models.SummaryStandard:  {2, builtinSummarySentences, 5},
+	models.SummaryHeadline:  {8, 15, 25},
+	models.SummaryExecutive: {3, 5, 8},
+	models.SummaryBullets:   {3, 5, 8},
+	models.SummaryDetailed:  {1, 2, 3},
+}
+
+// maxBuiltinSections bounds the lines of a builtin detailed summary
+const maxBuiltinSections = 12
+
+// summarizeAs writes an extractive summary in a style: sentences chosen
+// with TextRank, as a paragraph, a list or per section, or the most central
+// sentence cut to a headline
+func summarizeAs(text, style, length string) string {
+	sizes, ok := builtinSizes[style]
+	if !ok {
+		style, sizes = models.SummaryStandard, builtinSizes[models.SummaryStandard]
+	}
+	n := sizes[lengthIndex(length)]
+
+	switch style {
+	case models.SummaryHeadline:
+		sentences := rankSentences(text, 1)
+		if len(sentences) == 0 {
+			return ""
+		}
+		words := strings.Fields(strings.TrimRight(sentences[0], ".!?"))
+		if len(words) > n {
+			return strings.Join(words[:n], " ") + "..."
+		}
+		return strings.Join(words, " ")
+
+	case models.SummaryBullets:
+		sentences := rankSentences(text, n)
+		for i := range sentences {
+			sentences[i] = "- " + sentences[i]
+		}
+		return strings.Join(sentences, "\n")
+
+	case models.SummaryDetailed:
+		var lines []string
+		for i, section := range splitSections(text) {
+			if len(lines) == maxBuiltinSections {
+				break
+			}
+			sentences := rankSentences(section.body, n)
+			if len(sentences) == 0 {
+				continue
+			}
+			title := section.title
+			if title == "" {
+				title = fmt.Sprintf("Section %d", i+1)
+			}
+			lines = append(lines, title+": "+strings.Join(sentences, " "))
+		}
+		return strings.Join(lines, "\n")
+
+	default:
+		return strings.Join(rankSentences(text, n), " ")
+	}
+}
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+type section struct {
+	title string
+	body  string
+}
+
+// splitSections splits text into paragraphs, taking a short line without
+// a final period, such as "2. Payment Terms", as the title of the
+// paragraphs that follow it
+func splitSections(text string) []section {
+	var sections []section
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		title, body, _ := strings.Cut(paragraph, "\n")
+		if isHeading(title) {
+			title = strings.TrimSuffix(strings.TrimSpace(title), ":")
+			if strings.TrimSpace(body) == "" {
+				sections = append(sections, section{title: title})
+				continue
+			}
+			sections = append(sections, section{title: title, body: body})
+			continue
+		}
+
+		if n := len(sections); n > 0 && sections[n-1].body == "" {
+			sections[n-1].body = paragraph
+			continue
+		}
+		sections = append(sections, section{body: paragraph})
+	}
+	return sections
+}
+
+func isHeading(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || len(strings.Fields(line)) > 8 {
+		return false
+	}
+	last := []rune(line)[len([]rune(line))-1]
+	return !unicode.IsPunct(last) || last == ':'
+}
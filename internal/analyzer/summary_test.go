@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+const sampleReport = `1. Background
+
+The warehouse project started in January to replace the old storage site. The old site could no longer hold the volume of orders from the northern region. Rent at the old site also rose by a third last year.
+
+2. Progress
+
+Construction of the warehouse finished in May, two weeks ahead of schedule. The loading docks and shelving were installed in June. Staff moved the first orders through the new warehouse in July.
+
+3. Next Steps
+
+The board must approve the budget for the second phase of the warehouse project. The second phase adds cold storage for food orders from the northern region.`
+
+func TestSummaryInstructions(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "Write the summary as a concise paragraph of 2-3 sentences."},
+		{Options{Style: models.SummaryHeadline, Length: models.SummaryShort}, "single headline of at most 8 words"},
+		{Options{Style: models.SummaryBullets, Length: models.SummaryLong}, "8 key takeaways, one per line"},
+		{Options{Style: models.SummaryDetailed}, "followed by two or three sentences"},
+	}
+
+	for _, tt := range tests {
+		if got := summaryInstructions(tt.opts); !strings.Contains(got, tt.want) {
+			t.Errorf("summaryInstructions(%+v) = %q, want it to contain %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeAs(t *testing.T) {
+	headline := summarizeAs(sampleReport, models.SummaryHeadline, models.SummaryShort)
+	if n := len(strings.Fields(strings.TrimSuffix(headline, "..."))); n == 0 || n > 8 || strings.Contains(headline, "\n") {
+		t.Errorf("short headline = %q, want one line of at most 8 words", headline)
+	}
+
+	bullets := strings.Split(summarizeAs(sampleReport, models.SummaryBullets, models.SummaryShort), "\n")
+	if len(bullets) != 3 {
+		t.Fatalf("short bullets = %q, want 3 lines", bullets)
+	}
+	for _, bullet := range bullets {
+		if !strings.HasPrefix(bullet, "- ") {
+			t.Errorf("bullet %q does not start with \"- \"", bullet)
+		}
+	}
+
+	detailed := strings.Split(summarizeAs(sampleReport, models.SummaryDetailed, models.SummaryShort), "\n")
+	if len(detailed) != 3 {
+		t.Fatalf("detailed summary = %q, want one line per section", detailed)
+	}
+	for i, title := range []string{"1. Background: ", "2. Progress: ", "3. Next Steps: "} {
+		if !strings.HasPrefix(detailed[i], title) {
+			t.Errorf("line %d = %q, want it to start with %q", i, detailed[i], title)
+		}
+	}
+
+	if standard := summarizeAs(sampleReport, "", ""); standard != strings.Join(rankSentences(sampleReport, builtinSummarySentences), " ") {
+		t.Errorf("default summary = %q, want the standard medium summary", standard)
+	}
+}
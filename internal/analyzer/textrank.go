@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+)
+
+// TextRank parameters: the damping factor of the ranking walk, when to stop
+// iterating and how many sentences are ranked, since the similarity graph
+// grows quadratically
+const (
+	textRankDamping    = 0.85
+	textRankTolerance  = 1e-4
+	textRankIterations = 100
+	maxRankedSentences = 300
+)
+
+// minSentenceTokens is the number of content words a sentence needs to be
+// chosen for a summary, which keeps out headings and table rows
+const minSentenceTokens = 4
+
+// stopwords are left out when comparing sentences. The list is English
+// only; other languages still rank, with slightly noisier similarity.
+var stopwords = map[string]bool{}
+
+func init() {
+	words := `a about above after again against all am an and any are as at be because been before
+being below between both but by can could did do does doing down during each few for from further
+had has have having he her here hers herself him himself his how i if in into is it its itself
+just me more most my myself no nor not now of off on once only or other our ours ourselves out over
+own same she should so some such than that the their theirs them themselves then there these they
+this those through to too under until up very was we were what when where which while who whom why
+will with would you your yours yourself yourselves shall may must also per`
+	for _, word := range strings.Fields(words) {
+		stopwords[word] = true
+	}
+}
+
+// abbreviations end in a period without ending the sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true,
+	"no": true, "vs": true, "e.g": true, "i.e": true, "jan": true, "feb": true,
+	"mar": true, "apr": true, "jun": true, "jul": true, "aug": true, "sep": true,
+	"sept": true, "oct": true, "nov": true, "dec": true,
+}
+
+// trailingAbbreviations often end a sentence too, so they do when the next
+// word is capitalized
+var trailingAbbreviations = map[string]bool{
+	"inc": true, "ltd": true, "co": true, "corp": true, "etc": true,
+}
+
+var sentenceParagraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+// splitSentences splits text into sentences. Paragraphs never share a
+// sentence, and line breaks inside a paragraph are treated as spaces.
+func splitSentences(text string) []string {
+	var sentences []string
+	for _, paragraph := range sentenceParagraphBreak.Split(text, -1) {
+		words := strings.Fields(paragraph)
+		start := 0
+		for i, word := range words {
+			if i < len(words)-1 && !endsSentence(word, words[i+1]) {
+				continue
+			}
+			sentences = append(sentences, strings.Join(words[start:i+1], " "))
+			start = i + 1
+		}
+	}
+	return sentences
+}
+
+// endsSentence reports whether word ends with sentence punctuation that is
+// not part of an abbreviation or initial
+func endsSentence(word, next string) bool {
+	trimmed := strings.TrimRight(word, `"')]`)
+	if trimmed == "" {
+		return false
+	}
+
+	switch trimmed[len(trimmed)-1] {
+	case '!', '?':
+		return true
+	case '.':
+		stem := strings.ToLower(strings.TrimLeft(strings.TrimSuffix(trimmed, "."), `"'([`))
+		if abbreviations[stem] {
+			return false
+		}
+		if trailingAbbreviations[stem] {
+			first, _ := utf8.DecodeRuneInString(next)
+			return unicode.IsUpper(first)
+		}
+		// Initials such as "J." and numbers such as "1."
+		if len([]rune(stem)) == 1 || strings.IndexFunc(stem, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// contentWords returns the distinct non-stopword tokens of a sentence
+func contentWords(sentence string) map[string]bool {
+	words := map[string]bool{}
+	for _, token := range retrieval.Tokenize(sentence) {
+		if !stopwords[token] && len([]rune(token)) > 1 {
+			words[token] = true
+		}
+	}
+	return words
+}
+
+// rankSentences picks the n most central sentences with TextRank and
+// returns them in document order. Sentences are linked by the number of words they
+// share, normalized by their lengths (Mihalcea and Tarau, 2004).
+func rankSentences(text string, n int) []string {
+	var sentences []string
+	var words []map[string]bool
+	for _, sentence := range splitSentences(text) {
+		w := contentWords(sentence)
+		if len(w) < minSentenceTokens {
+			continue
+		}
+		sentences = append(sentences, sentence)
+		words = append(words, w)
+		if len(sentences) == maxRankedSentences {
+			break
+		}
+	}
+
+	if len(sentences) == 0 {
+		// Nothing sentence-like, e.g. a form or a table; use the opening text
+		all := splitSentences(text)
+		return all[:min(n, len(all))]
+	}
+	if len(sentences) <= n {
+		return sentences
+	}
+
+	count := len(sentences)
+	weights := make([][]float64, count)
+	outWeight := make([]float64, count)
+	for i := range weights {
+		weights[i] = make([]float64, count)
+	}
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			shared := 0
+			for word := range words[i] {
+				if words[j][word] {
+					shared++
+				}
+			}
+			if shared == 0 {
+				continue
+			}
+			w := float64(shared) / (math.Log(float64(len(words[i]))) + math.Log(float64(len(words[j]))))
+			weights[i][j], weights[j][i] = w, w
+			outWeight[i] += w
+			outWeight[j] += w
+		}
+	}
+
+	scores := make([]float64, count)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iter := 0; iter < textRankIterations; iter++ {
+		next := make([]float64, count)
+		delta := 0.0
+		for i := 0; i < count; i++ {
+			sum := 0.0
+			for j := 0; j < count; j++ {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / outWeight[j] * scores[j]
+				}
+			}
+			next[i] = 1 - textRankDamping + textRankDamping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if delta < textRankTolerance {
+			break
+		}
+	}
+
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	chosen := order[:n]
+	sort.Ints(chosen)
+
+	summary := make([]string, 0, n)
+	for _, i := range chosen {
+		summary = append(summary, sentences[i])
+	}
+	return summary
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseTranslation validates a translation response, which must hold
+// exactly one string per paragraph sent. The list is checked here rather
+// than by the validator, which drops values such as "N/A" that are valid
+// paragraphs of a document.
+func parseTranslation(content string, paragraphs int) ([]string, error) {
+	raw, err := decodeObject(content)
+	if err != nil {
+		return nil, &ValidationError{Problems: []string{err.Error()}}
+	}
+
+	items, ok := raw["paragraphs"].([]interface{})
+	if !ok {
+		return nil, &ValidationError{Problems: []string{"paragraphs must be a list of strings"}}
+	}
+
+	var problems []string
+	if len(items) != paragraphs {
+		problems = append(problems, fmt.Sprintf("paragraphs has %d items, expected one for each of the %d paragraphs", len(items), paragraphs))
+	}
+	translated := make([]string, 0, len(items))
+	for i, item := range items {
+		text, ok := item.(string)
+		if !ok || strings.TrimSpace(text) == "" {
+			problems = append(problems, fmt.Sprintf("paragraphs[%d] must be a non-empty string", i))
+			continue
+		}
+		translated = append(translated, strings.TrimSpace(text))
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return translated, nil
+}
+
+// paragraphDocument numbers paragraphs from 1 so the model can keep them
+// apart
+func paragraphDocument(paragraphs []string) string {
+	var b strings.Builder
+	for i, p := range paragraphs {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "[Paragraph %d]\n%s", i+1, p)
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTranslation(t *testing.T) {
+	paragraphs, err := parseTranslation(`{"paragraphs": ["Bonjour.", " N/A "]}`, 2)
+	if err != nil {
+		t.Fatalf("parseTranslation returned %v", err)
+	}
+	if len(paragraphs) != 2 || paragraphs[0] != "Bonjour." || paragraphs[1] != "N/A" {
+		t.Errorf("paragraphs = %q", paragraphs)
+	}
+
+	for _, content := range []string{
+		`{"paragraphs": ["Bonjour."]}`,
+		`{"paragraphs": ["Bonjour.", ""]}`,
+		`{"paragraphs": ["Bonjour.", 2]}`,
+		`{"translation": "Bonjour."}`,
+	} {
+		var validationErr *ValidationError
+		if _, err := parseTranslation(content, 2); !errors.As(err, &validationErr) {
+			t.Errorf("parseTranslation(%s) = %v, want a validation error", content, err)
+		}
+	}
+}
+
+func TestTranslateRepairsParagraphCount(t *testing.T) {
+	var requests []OpenRouterRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenRouterRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		content := `{"paragraphs":["Bonjour. Au revoir."]}`
+		if len(requests) > 1 {
+			content = `{"paragraphs":["Bonjour.","Au revoir."]}`
+		}
+		reply, _ := json.Marshal(content)
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%s}}],"usage":{"prompt_tokens":10,"completion_tokens":5}}`, reply)
+	}))
+	t.Cleanup(server.Close)
+
+	a := newTestAnalyzer(server.URL, 0, 5)
+	result, err := a.Translate(context.Background(), []string{"Hello.", "Goodbye."}, Options{SourceLanguage: "en", TargetLanguage: "fr"})
+	if err != nil {
+		t.Fatalf("Translate returned %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("sent %d requests, want a repair after the first", len(requests))
+	}
+	if prompt := requests[0].Messages[0].Content; !strings.Contains(prompt, "from English into French") || !strings.Contains(prompt, "exactly 2 items") {
+		t.Errorf("prompt does not name the languages and paragraph count:\n%s", prompt)
+	}
+	if document := requests[0].Messages[1].Content; !strings.Contains(document, "[Paragraph 1]\nHello.\n\n[Paragraph 2]\nGoodbye.") {
+		t.Errorf("document does not number the paragraphs:\n%s", document)
+	}
+	if len(result.Paragraphs) != 2 || result.Paragraphs[1] != "Au revoir." {
+		t.Errorf("paragraphs = %q", result.Paragraphs)
+	}
+	if result.PromptVersion != "translate@1" || result.Usage.PromptTokens != 20 {
+		t.Errorf("prompt version %q with %d prompt tokens, want translate@1 with 20", result.PromptVersion, result.Usage.PromptTokens)
+	}
+}
+
+func TestBuiltinTranslateIsUnsupported(t *testing.T) {
+	_, err := NewBuiltinAnalyzer().Translate(context.Background(), []string{"Hello."}, Options{TargetLanguage: "fr"})
+	if !errors.Is(err, ErrTranslationUnsupported) {
+		t.Errorf("error = %v, want ErrTranslationUnsupported", err)
+	}
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import "github.com/BerylCAtieno/document-summarizer-api/internal/models"
+
+// UsageError is a failure of calls that were still billed, such as an
+// analysis whose repairs were exhausted, with the tokens they used
+type UsageError struct {
+	Err   error
+	Model string
+	Usage models.TokenUsage
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// withUsage reports the tokens billed before err, leaving err as it is
+// when nothing was billed
+func withUsage(err error, model string, usage models.TokenUsage) error {
+	if usage == (models.TokenUsage{}) {
+		return err
+	}
+	return &UsageError{Err: err, Model: model, Usage: usage}
+}
+
+// FailedUsage returns the billed usage of every failed call in err. A
+// fallback chain reports one entry per model that was billed.
+func FailedUsage(err error) []*UsageError {
+	var found []*UsageError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *UsageError:
+			found = append(found, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return found
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// invalidProvider answers every call with text that is not an analysis,
+// billing 10 prompt and 5 completion tokens each time
+func invalidProvider(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"I cannot help with that."}}],"usage":{"prompt_tokens":10,"completion_tokens":5}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestAnalyzeReportsUsageOfFailedRepairs(t *testing.T) {
+	server, calls := invalidProvider(t)
+	a := newTestAnalyzer(server.URL, 0, 5)
+
+	_, err := a.Analyze(context.Background(), "text", Options{})
+	if err == nil {
+		t.Fatal("Analyze accepted output that is not an analysis")
+	}
+
+	// Classification, the analysis and its repairs were all billed
+	failed := FailedUsage(err)
+	if len(failed) != 1 {
+		t.Fatalf("got %d failed usages, want 1", len(failed))
+	}
+	n := int64(calls.Load())
+	if n != 2+maxRepairAttempts {
+		t.Errorf("provider called %d times, want %d", n, 2+maxRepairAttempts)
+	}
+	if failed[0].Model != "test/model" || failed[0].Usage.PromptTokens != 10*n || failed[0].Usage.CompletionTokens != 5*n {
+		t.Errorf("failed usage = %s %+v, want %d calls of test/model", failed[0].Model, failed[0].Usage, n)
+	}
+}
+
+func TestFallbackReportsUsageOfEveryFailedModel(t *testing.T) {
+	server, _ := invalidProvider(t)
+	unauthorized, _ := stubProvider(t, http.StatusUnauthorized)
+	primary := newTestAnalyzer(server.URL, 0, 5)
+	primary.model = "primary/model"
+	secondary := newTestAnalyzer(server.URL, 0, 5)
+	secondary.model = "cheap/model"
+
+	// The unauthorized model was not billed and has no usage to report
+	a := NewFallbackAnalyzer([]Analyzer{primary, newTestAnalyzer(unauthorized.URL, 0, 5), secondary}, utils.NewLogger("error"))
+	_, err := a.Analyze(context.Background(), "text", Options{})
+
+	failed := FailedUsage(err)
+	if len(failed) != 2 || failed[0].Model != "primary/model" || failed[1].Model != "cheap/model" {
+		t.Errorf("failed usage = %+v, want primary/model and cheap/model", failed)
+	}
+}
@@ -3,8 +3,35 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
 )
 
+// ModelRef names a model at an LLM provider
+type ModelRef struct {
+	Provider string
+	Model    string
+}
+
+// ModelPrice is what a model costs in USD per million tokens
+type ModelPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// defaultPrices is used when LLM_PRICES is not set
+const defaultPrices = "openai/gpt-4o-mini=0.15/0.60"
+
+// providers lists the LLM providers a ModelRef may name
+var providers = map[string]bool{
+	"openrouter": true,
+	"builtin":    true,
+}
+
 type Config struct {
 	Port        string
 	DatabaseURL string
@@ -17,12 +44,64 @@ type Config struct {
 	S3BucketName      string
 	S3UseSSL          bool
 
-	// OpenRouter
-	OpenRouterAPIKey string
-	OpenRouterModel  string
+	// OpenRouter; without an API key only the builtin analyzer is used
+	OpenRouterAPIKey  string
+	OpenRouterModel   string
+	OpenRouterBaseURL string
+
+	// LLMFallbackModels are tried in order after OpenRouterModel fails
+	LLMFallbackModels []ModelRef
+
+	// LLMPrices maps model ids to their token prices
+	LLMPrices map[string]ModelPrice
+
+	// PromptsDir holds prompt templates that override the built-in ones
+	PromptsDir string
+
+	// RedactPolicy is the PII masked before text is sent to the analyzer
+	// when a request does not choose its own policy
+	RedactPolicy redact.Policy
+
+	// Taxonomy is the controlled set of document types
+	Taxonomy *taxonomy.Taxonomy
+	// ReviewConfidence is the classification confidence below which a
+	// document needs human review
+	ReviewConfidence float64
+	// ExtractEntities adds a named entity extraction step to analysis
+	ExtractEntities bool
+
+	// Embeddings for semantic search: openai calls an OpenAI-compatible
+	// endpoint, hashing embeds locally
+	EmbeddingProvider   string
+	EmbeddingAPIKey     string
+	EmbeddingBaseURL    string
+	EmbeddingModel      string
+	EmbeddingDimensions int
+	// VectorIndex is flat for exact search or hnsw for approximate search
+	VectorIndex string
+
+	// Analysis cache; a zero TTL or size turns it off
+	AnalysisCacheTTL        time.Duration
+	AnalysisCacheMaxEntries int
+	AnalysisCacheMaxBytes   int64
+
+	// LLM retry and circuit breaker
+	LLMMaxRetries       int
+	LLMRetryBaseDelay   time.Duration
+	LLMRetryMaxDelay    time.Duration
+	LLMBreakerThreshold int
+	LLMBreakerCooldown  time.Duration
 
 	// Upload limits
 	MaxFileSize int64
+
+	// Text normalization steps applied after extraction
+	NormalizeUnicode      bool
+	NormalizeLigatures    bool
+	NormalizeDehyphenate  bool
+	NormalizeHeaders      bool
+	NormalizeControlChars bool
+	NormalizeWhitespace   bool
 }
 
 func Load() (*Config, error) {
@@ -37,16 +116,181 @@ func Load() (*Config, error) {
 		S3UseSSL:          getEnv("S3_USE_SSL", "false") == "true",
 		OpenRouterAPIKey:  getEnv("OPENROUTER_API_KEY", ""),
 		OpenRouterModel:   getEnv("OPENROUTER_MODEL", "openai/gpt-4o-mini"),
-		MaxFileSize:       5 * 1024 * 1024,
+		OpenRouterBaseURL: getEnv("OPENROUTER_BASE_URL", "https://openrouter.ai/api/v1"),
+		PromptsDir:        getEnv("PROMPTS_DIR", ""),
+		MaxFileSize:       getEnvInt64("MAX_FILE_SIZE", 5*1024*1024),
+		ReviewConfidence:  getEnvFloat("REVIEW_CONFIDENCE", 0.7),
+		ExtractEntities:   getEnv("EXTRACT_ENTITIES", "true") == "true",
+
+		EmbeddingAPIKey:     getEnv("EMBEDDING_API_KEY", ""),
+		EmbeddingBaseURL:    getEnv("EMBEDDING_BASE_URL", "https://api.openai.com/v1"),
+		EmbeddingModel:      getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingDimensions: getEnvInt("EMBEDDING_DIMENSIONS", 256),
+		VectorIndex:         getEnv("VECTOR_INDEX", "flat"),
+
+		AnalysisCacheTTL:        getEnvDuration("ANALYSIS_CACHE_TTL", 7*24*time.Hour),
+		AnalysisCacheMaxEntries: getEnvInt("ANALYSIS_CACHE_MAX_ENTRIES", 10000),
+		AnalysisCacheMaxBytes:   getEnvInt64("ANALYSIS_CACHE_MAX_BYTES", 64<<20),
+
+		LLMMaxRetries:       getEnvInt("LLM_MAX_RETRIES", 3),
+		LLMRetryBaseDelay:   getEnvDuration("LLM_RETRY_BASE_DELAY", 500*time.Millisecond),
+		LLMRetryMaxDelay:    getEnvDuration("LLM_RETRY_MAX_DELAY", 10*time.Second),
+		LLMBreakerThreshold: getEnvInt("LLM_BREAKER_THRESHOLD", 5),
+		LLMBreakerCooldown:  getEnvDuration("LLM_BREAKER_COOLDOWN", 30*time.Second),
+
+		NormalizeUnicode:      getEnv("NORMALIZE_UNICODE", "true") == "true",
+		NormalizeLigatures:    getEnv("NORMALIZE_LIGATURES", "true") == "true",
+		NormalizeDehyphenate:  getEnv("NORMALIZE_DEHYPHENATE", "true") == "true",
+		NormalizeHeaders:      getEnv("NORMALIZE_HEADERS_FOOTERS", "true") == "true",
+		NormalizeControlChars: getEnv("NORMALIZE_CONTROL_CHARS", "true") == "true",
+		NormalizeWhitespace:   getEnv("NORMALIZE_WHITESPACE", "true") == "true",
+	}
+
+	fallbacks, err := parseModelChain(getEnv("LLM_FALLBACK_MODELS", ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg.LLMFallbackModels = fallbacks
+
+	prices, err := parsePrices(getEnv("LLM_PRICES", defaultPrices))
+	if err != nil {
+		return nil, err
+	}
+	cfg.LLMPrices = prices
+
+	policy, err := redact.ParsePolicy(getEnv("REDACT_PII", "all"))
+	if err != nil {
+		return nil, fmt.Errorf("REDACT_PII: %w", err)
+	}
+	cfg.RedactPolicy = policy
+
+	types, err := taxonomy.Parse(getEnv("DOCUMENT_TYPES", taxonomy.DefaultSpec))
+	if err != nil {
+		return nil, fmt.Errorf("DOCUMENT_TYPES: %w", err)
 	}
+	cfg.Taxonomy = types
 
-	if cfg.OpenRouterAPIKey == "" {
-		return nil, fmt.Errorf("OPENROUTER_API_KEY is required")
+	// Without an API key documents are embedded locally
+	defaultEmbedder := "hashing"
+	if cfg.EmbeddingAPIKey != "" {
+		defaultEmbedder = "openai"
+	}
+	cfg.EmbeddingProvider = getEnv("EMBEDDING_PROVIDER", defaultEmbedder)
+	if cfg.EmbeddingProvider != "openai" && cfg.EmbeddingProvider != "hashing" {
+		return nil, fmt.Errorf("EMBEDDING_PROVIDER: expected openai or hashing, got %q", cfg.EmbeddingProvider)
+	}
+	if cfg.EmbeddingProvider == "openai" && cfg.EmbeddingAPIKey == "" {
+		return nil, fmt.Errorf("EMBEDDING_PROVIDER: openai needs EMBEDDING_API_KEY")
+	}
+	if cfg.VectorIndex != "flat" && cfg.VectorIndex != "hnsw" {
+		return nil, fmt.Errorf("VECTOR_INDEX: expected flat or hnsw, got %q", cfg.VectorIndex)
 	}
 
 	return cfg, nil
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// parseModelChain reads a comma separated list of provider:model entries.
+// The provider prefix is optional and defaults to openrouter. Model ids
+// contain a slash and may contain colons (e.g. "meta-llama/llama-3-8b:free"),
+// so only a colon before the first slash separates a provider. A bare
+// "builtin" entry names the offline analyzer.
+func parseModelChain(value string) ([]ModelRef, error) {
+	var chain []ModelRef
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == "builtin" {
+			chain = append(chain, ModelRef{Provider: "builtin", Model: "builtin"})
+			continue
+		}
+
+		ref := ModelRef{Provider: "openrouter", Model: entry}
+		if provider, model, ok := strings.Cut(entry, ":"); ok && !strings.Contains(provider, "/") {
+			if !providers[provider] {
+				return nil, fmt.Errorf("LLM_FALLBACK_MODELS: unknown provider %q", provider)
+			}
+			ref = ModelRef{Provider: provider, Model: model}
+		}
+
+		if ref.Model == "" {
+			return nil, fmt.Errorf("LLM_FALLBACK_MODELS: missing model in %q", entry)
+		}
+		chain = append(chain, ref)
+	}
+	return chain, nil
+}
+
+// parsePrices reads a comma separated list of model=prompt/completion
+// entries, with prices in USD per million tokens
+func parsePrices(value string) (map[string]ModelPrice, error) {
+	prices := map[string]ModelPrice{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		model, price, ok := strings.Cut(entry, "=")
+		promptPrice, completionPrice, ok2 := strings.Cut(price, "/")
+		if !ok || !ok2 || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("LLM_PRICES: expected model=prompt/completion, got %q", entry)
+		}
+
+		prompt, err := strconv.ParseFloat(strings.TrimSpace(promptPrice), 64)
+		if err != nil || prompt < 0 {
+			return nil, fmt.Errorf("LLM_PRICES: invalid prompt price in %q", entry)
+		}
+		completion, err := strconv.ParseFloat(strings.TrimSpace(completionPrice), 64)
+		if err != nil || completion < 0 {
+			return nil, fmt.Errorf("LLM_PRICES: invalid completion price in %q", entry)
+		}
+
+		prices[strings.TrimSpace(model)] = ModelPrice{
+			PromptPerMillion:     prompt,
+			CompletionPerMillion: completion,
+		}
+	}
+	return prices, nil
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_documents_language;
+
+ALTER TABLE documents DROP COLUMN summary_language;
+ALTER TABLE documents DROP COLUMN language;
@@ -0,0 +1,4 @@
+ALTER TABLE documents ADD COLUMN language TEXT;
+ALTER TABLE documents ADD COLUMN summary_language TEXT;
+
+CREATE INDEX idx_documents_language ON documents(language);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_documents_content_hash;
+
+ALTER TABLE documents DROP COLUMN content_hash;
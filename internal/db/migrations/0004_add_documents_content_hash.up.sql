@@ -0,0 +1,3 @@
+ALTER TABLE documents ADD COLUMN content_hash TEXT;
+
+CREATE INDEX idx_documents_content_hash ON documents(content_hash);
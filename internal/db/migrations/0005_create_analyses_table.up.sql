@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS analyses (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    prompt_version TEXT NOT NULL,
+    summary TEXT,
+    summary_language TEXT,
+    document_type TEXT,
+    metadata TEXT,
+    latency_ms INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_analyses_document_id ON analyses(document_id, created_at);
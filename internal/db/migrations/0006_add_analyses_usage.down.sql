@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_analyses_created_at;
+
+ALTER TABLE analyses DROP COLUMN cost_usd;
+ALTER TABLE analyses DROP COLUMN completion_tokens;
+ALTER TABLE analyses DROP COLUMN prompt_tokens;
@@ -0,0 +1,5 @@
+ALTER TABLE analyses ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN cost_usd REAL;
+
+CREATE INDEX idx_analyses_created_at ON analyses(created_at);
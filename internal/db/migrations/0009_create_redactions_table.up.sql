@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS redactions (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    operation TEXT NOT NULL,
+    policy TEXT NOT NULL,
+    counts TEXT NOT NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_redactions_document_id ON redactions(document_id, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE analyses DROP COLUMN injection_signals;
+ALTER TABLE analyses DROP COLUMN suspicious;
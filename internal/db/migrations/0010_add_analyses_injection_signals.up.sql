@@ -0,0 +1,2 @@
+ALTER TABLE analyses ADD COLUMN suspicious INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyses ADD COLUMN injection_signals TEXT;
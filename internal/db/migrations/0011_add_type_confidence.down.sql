@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_documents_type_confidence;
+
+ALTER TABLE analyses DROP COLUMN type_alternatives;
+ALTER TABLE analyses DROP COLUMN type_confidence;
+ALTER TABLE documents DROP COLUMN type_alternatives;
+ALTER TABLE documents DROP COLUMN type_confidence;
@@ -0,0 +1,9 @@
+ALTER TABLE documents ADD COLUMN type_confidence REAL;
+ALTER TABLE documents ADD COLUMN type_alternatives TEXT;
+ALTER TABLE analyses ADD COLUMN type_confidence REAL;
+ALTER TABLE analyses ADD COLUMN type_alternatives TEXT;
+
+UPDATE documents SET document_type = lower(trim(document_type)) WHERE document_type IS NOT NULL;
+UPDATE analyses SET document_type = lower(trim(document_type)) WHERE document_type IS NOT NULL;
+
+CREATE INDEX idx_documents_type_confidence ON documents(type_confidence);
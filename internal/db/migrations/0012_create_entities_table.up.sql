@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS entities (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    value TEXT NOT NULL,
+    text TEXT NOT NULL,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_entities_type_value ON entities(type, value);
+CREATE INDEX idx_entities_value ON entities(value);
+CREATE INDEX idx_entities_document_id ON entities(document_id, start_offset);
@@ -0,0 +1,4 @@
+ALTER TABLE analyses DROP COLUMN summary_length;
+ALTER TABLE analyses DROP COLUMN summary_style;
+
+DROP TABLE IF EXISTS summaries;
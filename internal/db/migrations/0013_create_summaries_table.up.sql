@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS summaries (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    style TEXT NOT NULL,
+    length TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    summary_language TEXT,
+    analysis_id TEXT,
+    model TEXT,
+    prompt_version TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, style, length)
+);
+
+ALTER TABLE analyses ADD COLUMN summary_style TEXT;
+ALTER TABLE analyses ADD COLUMN summary_length TEXT;
+
+UPDATE analyses SET summary_style = 'standard', summary_length = 'medium';
+
+INSERT INTO summaries (id, document_id, style, length, summary, summary_language, analysis_id, model, prompt_version, created_at)
+SELECT lower(hex(randomblob(16))), d.id, 'standard', 'medium', d.summary, d.summary_language, a.id, a.model, a.prompt_version,
+       COALESCE(d.analyzed_at, d.updated_at)
+FROM documents d
+LEFT JOIN analyses a ON a.id = (
+    SELECT id FROM analyses WHERE document_id = d.id ORDER BY created_at DESC LIMIT 1
+)
+WHERE d.summary IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_text_versions_document;
+DROP TABLE IF EXISTS text_versions;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_embeddings_model;
+DROP TABLE IF EXISTS embeddings;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_analysis_cache_last_used_at;
+DROP INDEX IF EXISTS idx_analysis_cache_expires_at;
+DROP TABLE IF EXISTS analysis_cache;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_documents_reviewed_at;
+DROP INDEX IF EXISTS idx_corrections_document_id;
+DROP TABLE IF EXISTS corrections;
+
+ALTER TABLE documents DROP COLUMN reviewed_at;
+ALTER TABLE documents DROP COLUMN reviewed_by;
+ALTER TABLE documents DROP COLUMN verified_fields;
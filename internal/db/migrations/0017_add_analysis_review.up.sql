@@ -0,0 +1,18 @@
+ALTER TABLE documents ADD COLUMN verified_fields TEXT;
+ALTER TABLE documents ADD COLUMN reviewed_by TEXT;
+ALTER TABLE documents ADD COLUMN reviewed_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS corrections (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    action TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    reviewer TEXT NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_corrections_document_id ON corrections(document_id, created_at);
+CREATE INDEX idx_documents_reviewed_at ON documents(reviewed_at);
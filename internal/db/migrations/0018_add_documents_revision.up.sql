@@ -0,0 +1 @@
+ALTER TABLE documents ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_llm_calls_created_at;
+DROP TABLE IF EXISTS llm_calls;
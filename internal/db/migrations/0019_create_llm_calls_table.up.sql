@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS llm_calls (
+    id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    related_document_id TEXT REFERENCES documents(id) ON DELETE SET NULL,
+    operation TEXT NOT NULL,
+    model TEXT NOT NULL,
+    prompt_version TEXT,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_llm_calls_created_at ON llm_calls(created_at);
@@ -0,0 +1 @@
+ALTER TABLE llm_calls DROP COLUMN failed;
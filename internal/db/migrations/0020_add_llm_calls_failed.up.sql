@@ -0,0 +1 @@
+ALTER TABLE llm_calls ADD COLUMN failed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,319 @@
+package diff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Op is what happened to a paragraph between the old and new text
+type Op string
+
+const (
+	Unchanged Op = "unchanged"
+	Added     Op = "added"
+	Removed   Op = "removed"
+	// Changed pairs a removed paragraph with the similar paragraph that
+	// replaced it
+	Changed Op = "changed"
+	// Moved is a paragraph that appears unchanged at another position
+	Moved Op = "moved"
+)
+
+// Block is one paragraph of a diff. OldIndex and NewIndex are the
+// paragraph's position in the old and new text, or -1 when it is not in
+// that text.
+type Block struct {
+	Op       Op
+	Old      string
+	New      string
+	OldIndex int
+	NewIndex int
+}
+
+// Stats counts the blocks of a diff by operation
+type Stats struct {
+	Unchanged int
+	Added     int
+	Removed   int
+	Changed   int
+	Moved     int
+}
+
+const (
+	// changeSimilarity is how much of their wording a removed and an added
+	// paragraph must share to be reported as one changed paragraph
+	changeSimilarity = 0.5
+	// maxCells bounds the size of the LCS table; longer texts are compared
+	// without alignment, so every differing paragraph is removed and added
+	maxCells = 4_000_000
+)
+
+var (
+	paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// Paragraphs splits text at blank lines. Text without blank lines, as some
+// PDFs extract, is split into lines instead.
+func Paragraphs(text string) []string {
+	parts := paragraphBreak.Split(text, -1)
+	if len(parts) == 1 {
+		parts = strings.Split(text, "\n")
+	}
+
+	paragraphs := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			paragraphs = append(paragraphs, part)
+		}
+	}
+	return paragraphs
+}
+
+// Compare diffs two texts paragraph by paragraph. Paragraphs are aligned by
+// their longest common subsequence, ignoring differences in whitespace. A
+// removed paragraph that is added back elsewhere is reported once as moved,
+// and a removed paragraph followed by a similar added one as changed.
+// Blocks are in the order of the new text, with removed paragraphs where
+// they used to be.
+func Compare(oldText, newText string) []Block {
+	a, b := Paragraphs(oldText), Paragraphs(newText)
+	blocks := align(a, b)
+	blocks = detectMoves(blocks)
+	return pairChanges(blocks)
+}
+
+// Count returns the statistics of a diff
+func Count(blocks []Block) Stats {
+	var stats Stats
+	for _, block := range blocks {
+		switch block.Op {
+		case Unchanged:
+			stats.Unchanged++
+		case Added:
+			stats.Added++
+		case Removed:
+			stats.Removed++
+		case Changed:
+			stats.Changed++
+		case Moved:
+			stats.Moved++
+		}
+	}
+	return stats
+}
+
+// Identical reports whether a diff has no differences
+func Identical(blocks []Block) bool {
+	for _, block := range blocks {
+		if block.Op != Unchanged {
+			return false
+		}
+	}
+	return true
+}
+
+func key(paragraph string) string {
+	return whitespace.ReplaceAllString(paragraph, " ")
+}
+
+// align diffs the paragraph lists with an LCS over their keys. The common
+// prefix and suffix are matched first, which keeps the table small when
+// versions differ in a few places.
+func align(a, b []string) []Block {
+	ka := make([]string, len(a))
+	for i := range a {
+		ka[i] = key(a[i])
+	}
+	kb := make([]string, len(b))
+	for j := range b {
+		kb[j] = key(b[j])
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && ka[prefix] == kb[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && ka[len(a)-1-suffix] == kb[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var blocks []Block
+	unchanged := func(i, j int) {
+		blocks = append(blocks, Block{Op: Unchanged, Old: a[i], New: b[j], OldIndex: i, NewIndex: j})
+	}
+	removed := func(i int) {
+		blocks = append(blocks, Block{Op: Removed, Old: a[i], OldIndex: i, NewIndex: -1})
+	}
+	added := func(j int) {
+		blocks = append(blocks, Block{Op: Added, New: b[j], OldIndex: -1, NewIndex: j})
+	}
+
+	for i := 0; i < prefix; i++ {
+		unchanged(i, i)
+	}
+
+	// lcs[i][j] is the length of the LCS of the middle of a from i and the
+	// middle of b from j
+	ma, mb := ka[prefix:len(a)-suffix], kb[prefix:len(b)-suffix]
+	if len(ma)*len(mb) <= maxCells {
+		lcs := make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(ma) && j < len(mb) {
+			switch {
+			case ma[i] == mb[j]:
+				unchanged(prefix+i, prefix+j)
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				removed(prefix + i)
+				i++
+			default:
+				added(prefix + j)
+				j++
+			}
+		}
+		for ; i < len(ma); i++ {
+			removed(prefix + i)
+		}
+		for ; j < len(mb); j++ {
+			added(prefix + j)
+		}
+	} else {
+		for i := range ma {
+			removed(prefix + i)
+		}
+		for j := range mb {
+			added(prefix + j)
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		unchanged(len(a)-suffix+k, len(b)-suffix+k)
+	}
+	return blocks
+}
+
+// detectMoves turns each removed paragraph that was added elsewhere into a
+// single moved block at its new position
+func detectMoves(blocks []Block) []Block {
+	removedAt := map[string][]int{}
+	for i, block := range blocks {
+		if block.Op == Removed {
+			k := key(block.Old)
+			removedAt[k] = append(removedAt[k], i)
+		}
+	}
+
+	moved := map[int]bool{}
+	for i := range blocks {
+		block := &blocks[i]
+		if block.Op != Added {
+			continue
+		}
+		k := key(block.New)
+		if candidates := removedAt[k]; len(candidates) > 0 {
+			from := blocks[candidates[0]]
+			removedAt[k] = candidates[1:]
+			moved[candidates[0]] = true
+
+			block.Op = Moved
+			block.Old = from.Old
+			block.OldIndex = from.OldIndex
+		}
+	}
+
+	kept := blocks[:0]
+	for i, block := range blocks {
+		if !moved[i] {
+			kept = append(kept, block)
+		}
+	}
+	return kept
+}
+
+// pairChanges looks at each run of removed and added paragraphs between
+// unchanged ones and pairs a removed paragraph with the next similar added
+// one, keeping the pairs in order
+func pairChanges(blocks []Block) []Block {
+	var result []Block
+	for start := 0; start < len(blocks); {
+		if blocks[start].Op != Removed && blocks[start].Op != Added {
+			result = append(result, blocks[start])
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(blocks) && (blocks[end].Op == Removed || blocks[end].Op == Added) {
+			end++
+		}
+		run := blocks[start:end]
+
+		pairedWith := map[int]int{}
+		paired := map[int]bool{}
+		next := 0
+		for i, block := range run {
+			if block.Op != Removed {
+				continue
+			}
+			for j := max(next, i+1); j < len(run); j++ {
+				if run[j].Op == Added && !paired[j] && similarity(block.Old, run[j].New) >= changeSimilarity {
+					pairedWith[j] = i
+					paired[i], paired[j] = true, true
+					next = j + 1
+					break
+				}
+			}
+		}
+
+		for j, block := range run {
+			if i, ok := pairedWith[j]; ok {
+				block.Op = Changed
+				block.Old = run[i].Old
+				block.OldIndex = run[i].OldIndex
+			} else if paired[j] {
+				continue
+			}
+			result = append(result, block)
+		}
+		start = end
+	}
+	return result
+}
+
+// similarity is the Dice coefficient of the words of two paragraphs: the
+// share of words they have in common, counting repeated words
+func similarity(a, b string) float64 {
+	wa, wb := strings.Fields(strings.ToLower(a)), strings.Fields(strings.ToLower(b))
+	if len(wa)+len(wb) == 0 {
+		return 1
+	}
+
+	counts := map[string]int{}
+	for _, w := range wa {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range wb {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
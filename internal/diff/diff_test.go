@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func paragraphs(ps ...string) string {
+	return strings.Join(ps, "\n\n")
+}
+
+func ops(blocks []Block) string {
+	var s []string
+	for _, block := range blocks {
+		s = append(s, string(block.Op))
+	}
+	return strings.Join(s, ",")
+}
+
+func TestParagraphs(t *testing.T) {
+	got := Paragraphs("First paragraph\nstill first.\n \nSecond.\n\n\n  Third.  ")
+	want := []string{"First paragraph\nstill first.", "Second.", "Third."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Paragraphs = %q, want %q", got, want)
+	}
+
+	if got := Paragraphs("One line\nAnother line\n"); len(got) != 2 {
+		t.Errorf("Paragraphs without blank lines = %q, want two lines", got)
+	}
+}
+
+func TestCompareIdenticalIgnoresWhitespace(t *testing.T) {
+	blocks := Compare(paragraphs("The parties agree.", "Payment is due."), paragraphs("The  parties\nagree.", "Payment is due."))
+	if !Identical(blocks) || len(blocks) != 2 {
+		t.Errorf("blocks = %+v, want two unchanged paragraphs", blocks)
+	}
+}
+
+func TestCompareAddedRemovedChanged(t *testing.T) {
+	oldText := paragraphs(
+		"1. Definitions apply throughout this agreement.",
+		"2. Payment is due within 30 days of the invoice date.",
+		"3. Either party may terminate with notice.",
+		"4. The supplier keeps all intellectual property.",
+	)
+	newText := paragraphs(
+		"1. Definitions apply throughout this agreement.",
+		"2. Payment is due within 45 days of the invoice date.",
+		"3. Either party may terminate with notice.",
+		"5. Disputes go to arbitration in Nairobi.",
+	)
+
+	blocks := Compare(oldText, newText)
+	if got := ops(blocks); got != "unchanged,changed,unchanged,removed,added" {
+		t.Fatalf("ops = %s, want unchanged,changed,unchanged,removed,added", got)
+	}
+
+	changed := blocks[1]
+	if !strings.Contains(changed.Old, "30 days") || !strings.Contains(changed.New, "45 days") || changed.OldIndex != 1 || changed.NewIndex != 1 {
+		t.Errorf("changed block = %+v", changed)
+	}
+	if blocks[3].NewIndex != -1 || blocks[4].OldIndex != -1 {
+		t.Errorf("removed and added blocks have indexes %+v and %+v", blocks[3], blocks[4])
+	}
+
+	stats := Count(blocks)
+	if stats != (Stats{Unchanged: 2, Changed: 1, Removed: 1, Added: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCompareDetectsMovedBlocks(t *testing.T) {
+	oldText := paragraphs("Alpha clause.", "Beta clause.", "Gamma clause.", "Delta clause.")
+	newText := paragraphs("Alpha clause.", "Gamma clause.", "Delta clause.", "Beta  clause.")
+
+	blocks := Compare(oldText, newText)
+	if got := ops(blocks); got != "unchanged,unchanged,unchanged,moved" {
+		t.Fatalf("ops = %s, want unchanged,unchanged,unchanged,moved", got)
+	}
+	if moved := blocks[3]; moved.OldIndex != 1 || moved.NewIndex != 3 {
+		t.Errorf("moved block = %+v, want from 1 to 3", moved)
+	}
+}
+
+func TestCompareDoesNotPairDissimilarParagraphs(t *testing.T) {
+	blocks := Compare("The tenant pays rent monthly.", "Governing law is Kenyan law.")
+	if got := ops(blocks); got != "removed,added" {
+		t.Errorf("ops = %s, want removed,added", got)
+	}
+}
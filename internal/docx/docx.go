@@ -0,0 +1,81 @@
+// Package docx writes plain text as a minimal Word document
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const relationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// ContentType is the media type of a DOCX file
+const ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Write writes paragraphs as a DOCX file with one Word paragraph each.
+// Line breaks within a paragraph become line breaks in Word. language is
+// an ISO 639-1 code set as the document's proofing language, or empty.
+func Write(w io.Writer, paragraphs []string, language string) error {
+	z := zip.NewWriter(w)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", relationships},
+		{"word/document.xml", document(paragraphs, language)},
+	}
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func document(paragraphs []string, language string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	runProperties := ""
+	if language != "" {
+		runProperties = `<w:rPr><w:lang w:val="` + escape(language) + `"/></w:rPr>`
+	}
+	for _, paragraph := range paragraphs {
+		b.WriteString("<w:p>")
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			b.WriteString("<w:r>" + runProperties)
+			b.WriteString(`<w:t xml:space="preserve">` + escape(line) + "</w:t>")
+			if i < len(lines)-1 {
+				b.WriteString("<w:br/>")
+			}
+			b.WriteString("</w:r>")
+		}
+		b.WriteString("</w:p>")
+	}
+
+	b.WriteString("</w:body></w:document>")
+	return b.String()
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	// xml.EscapeText only fails when the writer does
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
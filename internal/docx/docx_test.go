@@ -0,0 +1,25 @@
+package docx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/extractor"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, []string{"Facture n° 42 <brouillon> & annexe", "Première ligne\nDeuxième ligne"}, "fr")
+	if err != nil {
+		t.Fatalf("Write returned %v", err)
+	}
+
+	text, err := extractor.ExtractDOCX(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ExtractDOCX returned %v", err)
+	}
+	want := "Facture n° 42 <brouillon> & annexe\nPremière ligne\nDeuxième ligne"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Package embedding turns text into vectors and finds the nearest ones
+package embedding
+
+import (
+	"context"
+	"math"
+)
+
+// Embedder turns texts into vectors. Vectors of different models are not
+// comparable, so each is stored with the model that made it.
+type Embedder interface {
+	// Model identifies the embedding model, e.g. openai:text-embedding-3-small
+	Model() string
+	// Embed returns one unit length vector per text, in the order of texts
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// IsLocal reports whether e embeds on this server, so no text is sent to a
+// provider
+func IsLocal(e Embedder) bool {
+	_, ok := e.(*hashingEmbedder)
+	return ok
+}
+
+// Dot is the cosine similarity of two unit length vectors
+func Dot(a, b []float32) float32 {
+	n := min(len(a), len(b))
+	var sum float32
+	for i := 0; i < n; i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Normalize scales v to unit length in place. The zero vector is left as
+// it is.
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// Mean is the normalized average of vectors, which stands for a document
+// made of several chunks
+func Mean(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	mean := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		for i := 0; i < len(mean) && i < len(v); i++ {
+			mean[i] += v[i]
+		}
+	}
+	return Normalize(mean)
+}
@@ -0,0 +1,103 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHashingEmbedderRanksSharedVocabulary(t *testing.T) {
+	e := NewHashingEmbedder(0)
+	vectors, err := e.Embed(context.Background(), []string{
+		"Payment is due within thirty days of the invoice date.",
+		"The invoice must be paid within thirty days.",
+		"The employee reports to the head of engineering.",
+	})
+	if err != nil {
+		t.Fatalf("Embed returned %v", err)
+	}
+
+	if len(vectors[0]) != DefaultHashingDimensions {
+		t.Fatalf("dimensions = %d, want %d", len(vectors[0]), DefaultHashingDimensions)
+	}
+	var norm float64
+	for _, x := range vectors[0] {
+		norm += float64(x) * float64(x)
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Errorf("vector length = %v, want 1", math.Sqrt(norm))
+	}
+
+	if related, unrelated := Dot(vectors[0], vectors[1]), Dot(vectors[0], vectors[2]); related <= unrelated {
+		t.Errorf("similarity to a related text %v is not above an unrelated one %v", related, unrelated)
+	}
+
+	again, _ := e.Embed(context.Background(), []string{"Payment is due within thirty days of the invoice date."})
+	if Dot(vectors[0], again[0]) < 0.9999 {
+		t.Error("embedding the same text twice gave different vectors")
+	}
+	if e.Model() != "hashing-256" || !IsLocal(e) {
+		t.Errorf("model = %q, local = %v", e.Model(), IsLocal(e))
+	}
+}
+
+func TestOpenAIEmbedderOrdersByIndex(t *testing.T) {
+	var requests []embeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request to %s with authorization %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req embeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		// Answer in reverse order with vectors that are not unit length
+		fmt.Fprint(w, `{"data":[`)
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			fmt.Fprintf(w, `{"index":%d,"embedding":[%d,0]}`, i, i+2)
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	texts := make([]string, maxBatchSize+1)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+
+	e := NewOpenAIEmbedder(OpenAIConfig{APIKey: "key", Model: "text-embedding-3-small", BaseURL: server.URL + "/"})
+	vectors, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed returned %v", err)
+	}
+
+	if len(requests) != 2 || len(requests[1].Input) != 1 || requests[0].Model != "text-embedding-3-small" {
+		t.Errorf("requests = %d, want a full batch and one more", len(requests))
+	}
+	if len(vectors) != len(texts) || vectors[0][0] != 1 || vectors[0][1] != 0 {
+		t.Errorf("vectors[0] = %v, want [1 0]", vectors[0])
+	}
+	if e.Model() != "openai:text-embedding-3-small" || IsLocal(e) {
+		t.Errorf("model = %q, local = %v", e.Model(), IsLocal(e))
+	}
+}
+
+func TestOpenAIEmbedderReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	e := NewOpenAIEmbedder(OpenAIConfig{APIKey: "bad", Model: "m", BaseURL: server.URL})
+	if _, err := e.Embed(context.Background(), []string{"text"}); err == nil {
+		t.Error("Embed succeeded against a failing provider")
+	}
+}
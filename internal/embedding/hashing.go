@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+)
+
+// DefaultHashingDimensions is the vector size of the hashing embedder
+const DefaultHashingDimensions = 256
+
+type hashingEmbedder struct {
+	dimensions int
+}
+
+// NewHashingEmbedder returns an embedder that needs no provider. Words and
+// pairs of adjacent words are hashed into a fixed number of dimensions, so
+// texts sharing vocabulary get similar vectors. It is deterministic, which
+// makes it useful in tests, but it cannot match paraphrases.
+func NewHashingEmbedder(dimensions int) Embedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashingDimensions
+	}
+	return &hashingEmbedder{dimensions: dimensions}
+}
+
+func (e *hashingEmbedder) Model() string {
+	return fmt.Sprintf("hashing-%d", e.dimensions)
+}
+
+func (e *hashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *hashingEmbedder) embed(text string) []float32 {
+	v := make([]float32, e.dimensions)
+	tokens := retrieval.Tokenize(text)
+	for i, token := range tokens {
+		e.add(v, token, 1)
+		if i > 0 {
+			e.add(v, tokens[i-1]+" "+token, 0.5)
+		}
+	}
+	return Normalize(v)
+}
+
+// add hashes a feature to a dimension and a sign, so collisions cancel out
+// rather than pile up
+func (e *hashingEmbedder) add(v []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(e.dimensions)] += weight
+}
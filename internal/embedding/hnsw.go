@@ -0,0 +1,262 @@
+package embedding
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+)
+
+// HNSW parameters: each node keeps up to hnswM links per layer (twice that
+// on the bottom layer), and hnswEfConstruction and hnswEfSearch are how many
+// candidates are followed when inserting and searching
+const (
+	hnswM              = 16
+	hnswEfConstruction = 200
+	hnswEfSearch       = 64
+)
+
+type hnswNode struct {
+	key     Key
+	vector  []float32
+	links   [][]int
+	removed bool
+}
+
+// HNSW is a hierarchical navigable small world graph. Searches follow
+// links from a coarse top layer down to the bottom one, so they look at a
+// small part of the index; results are approximate but usually the same as
+// a flat search. Removed chunks stay in the graph as waypoints and are left
+// out of results until they outnumber the live ones, when the graph is
+// rebuilt from the live chunks.
+type HNSW struct {
+	mu       sync.RWMutex
+	nodes    []*hnswNode
+	entry    int
+	top      int
+	byDoc    map[string][]int
+	live     int
+	levelMul float64
+	rng      *rand.Rand
+}
+
+func NewHNSW() *HNSW {
+	return &HNSW{
+		entry:    -1,
+		byDoc:    map[string][]int{},
+		levelMul: 1 / math.Log(hnswM),
+		// A fixed seed keeps the graph the same for the same insertions
+		rng: rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+func (h *HNSW) Add(key Key, vector []float32) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.add(key, vector)
+}
+
+func (h *HNSW) Remove(documentID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(documentID)
+}
+
+func (h *HNSW) Replace(documentID string, entries []Entry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(documentID)
+	for _, entry := range entries {
+		h.add(entry.Key, entry.Vector)
+	}
+}
+
+func (h *HNSW) add(key Key, vector []float32) {
+	id := len(h.nodes)
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMul))
+	node := &hnswNode{key: key, vector: vector, links: make([][]int, level+1)}
+	h.nodes = append(h.nodes, node)
+	h.byDoc[key.DocumentID] = append(h.byDoc[key.DocumentID], id)
+	h.live++
+
+	if h.entry < 0 {
+		h.entry, h.top = id, level
+		return
+	}
+
+	current := h.entry
+	for layer := h.top; layer > level; layer-- {
+		current = h.greedy(vector, current, layer)
+	}
+	for layer := min(level, h.top); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, current, hnswEfConstruction, layer)
+		for _, c := range candidates[:min(hnswM, len(candidates))] {
+			node.links[layer] = append(node.links[layer], c.id)
+			neighbour := h.nodes[c.id]
+			neighbour.links[layer] = append(neighbour.links[layer], id)
+			if len(neighbour.links[layer]) > maxLinks(layer) {
+				h.prune(c.id, layer)
+			}
+		}
+		current = candidates[0].id
+	}
+
+	if level > h.top {
+		h.entry, h.top = id, level
+	}
+}
+
+func (h *HNSW) remove(documentID string) {
+	for _, id := range h.byDoc[documentID] {
+		h.nodes[id].removed = true
+		h.live--
+	}
+	delete(h.byDoc, documentID)
+
+	// Searches walk removed nodes too, so a graph that is mostly removed
+	// nodes is slow to search and keeps their vectors in memory
+	if len(h.nodes)-h.live > h.live {
+		h.rebuild()
+	}
+}
+
+// rebuild inserts the live nodes into an empty graph
+func (h *HNSW) rebuild() {
+	nodes := h.nodes
+	h.nodes = make([]*hnswNode, 0, h.live)
+	h.entry, h.top, h.live = -1, 0, 0
+	h.byDoc = map[string][]int{}
+	for _, node := range nodes {
+		if !node.removed {
+			h.add(node.key, node.vector)
+		}
+	}
+}
+
+// Search widens the search until it finds k chunks that are kept, since
+// removed chunks and those keep rejects take up room in the candidate list
+func (h *HNSW) Search(query []float32, k int, keep func(Key) bool) []Hit {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+
+	current := h.entry
+	for layer := h.top; layer > 0; layer-- {
+		current = h.greedy(query, current, layer)
+	}
+
+	for ef := max(hnswEfSearch, k); ; ef *= 2 {
+		var hits []Hit
+		for _, c := range h.searchLayer(query, current, ef, 0) {
+			node := h.nodes[c.id]
+			if !node.removed && (keep == nil || keep(node.key)) {
+				hits = append(hits, Hit{Key: node.key, Score: c.score})
+			}
+		}
+		if len(hits) >= k || ef >= len(h.nodes) {
+			return topHits(hits, k)
+		}
+	}
+}
+
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.live
+}
+
+func maxLinks(layer int) int {
+	if layer == 0 {
+		return 2 * hnswM
+	}
+	return hnswM
+}
+
+// greedy moves from node to node on a layer while a neighbour is closer to
+// the query
+func (h *HNSW) greedy(query []float32, current, layer int) int {
+	best := Dot(query, h.nodes[current].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.nodes[current].links[layer] {
+			if score := Dot(query, h.nodes[n].vector); score > best {
+				best, current, changed = score, n, true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer returns up to ef nodes of a layer nearest to the query, most
+// similar first
+func (h *HNSW) searchLayer(query []float32, entry, ef, layer int) []scored {
+	visited := map[int]bool{entry: true}
+	start := scored{id: entry, score: Dot(query, h.nodes[entry].vector)}
+
+	// candidates pops the most similar node to explore next, results the
+	// least similar one found so far
+	candidates := &scoredHeap{items: []scored{start}, less: func(a, b scored) bool { return a.score > b.score }}
+	results := &scoredHeap{items: []scored{start}, less: func(a, b scored) bool { return a.score < b.score }}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(scored)
+		if results.Len() >= ef && c.score < results.items[0].score {
+			break
+		}
+		for _, n := range h.nodes[c.id].links[layer] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			s := scored{id: n, score: Dot(query, h.nodes[n].vector)}
+			if results.Len() < ef || s.score > results.items[0].score {
+				heap.Push(candidates, s)
+				heap.Push(results, s)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sort.Slice(results.items, func(i, j int) bool {
+		return results.items[i].score > results.items[j].score
+	})
+	return results.items
+}
+
+// prune keeps the links of a node to its nearest neighbours
+func (h *HNSW) prune(id, layer int) {
+	node := h.nodes[id]
+	links := node.links[layer]
+	sort.Slice(links, func(i, j int) bool {
+		return Dot(node.vector, h.nodes[links[i]].vector) > Dot(node.vector, h.nodes[links[j]].vector)
+	})
+	node.links[layer] = links[:maxLinks(layer)]
+}
+
+type scored struct {
+	id    int
+	score float32
+}
+
+type scoredHeap struct {
+	items []scored
+	less  func(a, b scored) bool
+}
+
+func (s *scoredHeap) Len() int           { return len(s.items) }
+func (s *scoredHeap) Less(i, j int) bool { return s.less(s.items[i], s.items[j]) }
+func (s *scoredHeap) Swap(i, j int)      { s.items[i], s.items[j] = s.items[j], s.items[i] }
+func (s *scoredHeap) Push(x any)         { s.items = append(s.items, x.(scored)) }
+func (s *scoredHeap) Pop() any {
+	last := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return last
+}
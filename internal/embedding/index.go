@@ -0,0 +1,120 @@
+package embedding
+
+import (
+	"sort"
+	"sync"
+)
+
+// Key identifies a chunk of a document by its byte offsets into the
+// document's extracted text
+type Key struct {
+	DocumentID string
+	Start      int
+	End        int
+}
+
+// Hit is a chunk with its cosine similarity to a query
+type Hit struct {
+	Key
+	Score float32
+}
+
+// Entry is a chunk and its vector
+type Entry struct {
+	Key
+	Vector []float32
+}
+
+// Index finds the chunks nearest to a query vector. Implementations are
+// safe for concurrent use.
+type Index interface {
+	Add(key Key, vector []float32)
+	// Remove drops every chunk of a document
+	Remove(documentID string)
+	// Replace swaps every chunk of a document for entries in one step, so
+	// searches see either the old chunks or the new ones
+	Replace(documentID string, entries []Entry)
+	// Search returns up to k chunks accepted by keep, most similar first.
+	// A nil keep accepts every chunk.
+	Search(query []float32, k int, keep func(Key) bool) []Hit
+	// Len is the number of chunks in the index
+	Len() int
+}
+
+type flatEntry struct {
+	key    Key
+	vector []float32
+}
+
+// Flat compares a query with every vector. It is exact, and fast enough
+// for tens of thousands of chunks.
+type Flat struct {
+	mu      sync.RWMutex
+	entries []flatEntry
+}
+
+func NewFlat() *Flat {
+	return &Flat{}
+}
+
+func (f *Flat) Add(key Key, vector []float32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, flatEntry{key: key, vector: vector})
+}
+
+func (f *Flat) Remove(documentID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remove(documentID)
+}
+
+func (f *Flat) Replace(documentID string, entries []Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.remove(documentID)
+	for _, entry := range entries {
+		f.entries = append(f.entries, flatEntry{key: entry.Key, vector: entry.Vector})
+	}
+}
+
+func (f *Flat) remove(documentID string) {
+	kept := f.entries[:0]
+	for _, entry := range f.entries {
+		if entry.key.DocumentID != documentID {
+			kept = append(kept, entry)
+		}
+	}
+	clear(f.entries[len(kept):])
+	f.entries = kept
+}
+
+func (f *Flat) Search(query []float32, k int, keep func(Key) bool) []Hit {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var hits []Hit
+	for _, entry := range f.entries {
+		if keep == nil || keep(entry.key) {
+			hits = append(hits, Hit{Key: entry.key, Score: Dot(query, entry.vector)})
+		}
+	}
+	return topHits(hits, k)
+}
+
+func (f *Flat) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.entries)
+}
+
+func topHits(hits []Hit, k int) []Hit {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
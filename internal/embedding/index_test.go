@@ -0,0 +1,142 @@
+package embedding
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func randomVectors(n, dimensions int) [][]float32 {
+	rng := rand.New(rand.NewPCG(7, 7))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := make([]float32, dimensions)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		vectors[i] = Normalize(v)
+	}
+	return vectors
+}
+
+func TestFlatSearch(t *testing.T) {
+	f := NewFlat()
+	f.Add(Key{DocumentID: "a"}, []float32{1, 0})
+	f.Add(Key{DocumentID: "b", Start: 10}, []float32{0.6, 0.8})
+	f.Add(Key{DocumentID: "b", Start: 20}, []float32{0, 1})
+
+	hits := f.Search([]float32{0, 1}, 2, nil)
+	if len(hits) != 2 || hits[0].Start != 20 || hits[1].Start != 10 {
+		t.Errorf("hits = %+v", hits)
+	}
+
+	hits = f.Search([]float32{0, 1}, 5, func(k Key) bool { return k.DocumentID != "b" })
+	if len(hits) != 1 || hits[0].DocumentID != "a" {
+		t.Errorf("hits without b = %+v", hits)
+	}
+
+	f.Remove("b")
+	if f.Len() != 1 {
+		t.Errorf("Len = %d after removing b, want 1", f.Len())
+	}
+}
+
+// TestHNSWRecall checks the approximate search finds nearly all of the
+// exact nearest neighbours
+func TestHNSWRecall(t *testing.T) {
+	vectors := randomVectors(2000, 32)
+	flat, hnsw := NewFlat(), NewHNSW()
+	for i, v := range vectors {
+		key := Key{DocumentID: fmt.Sprintf("doc%d", i%500), Start: i}
+		flat.Add(key, v)
+		hnsw.Add(key, v)
+	}
+
+	const k = 10
+	found, total := 0, 0
+	for _, query := range randomVectors(50, 32) {
+		want := map[Key]bool{}
+		for _, hit := range flat.Search(query, k, nil) {
+			want[hit.Key] = true
+		}
+		for _, hit := range hnsw.Search(query, k, nil) {
+			if want[hit.Key] {
+				found++
+			}
+		}
+		total += k
+	}
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Errorf("recall = %.2f, want at least 0.9", recall)
+	}
+}
+
+func TestHNSWRemoveAndFilter(t *testing.T) {
+	vectors := randomVectors(300, 16)
+	h := NewHNSW()
+	for i, v := range vectors {
+		h.Add(Key{DocumentID: fmt.Sprintf("doc%d", i%30), Start: i}, v)
+	}
+
+	h.Remove("doc0")
+	if h.Len() != 290 {
+		t.Errorf("Len = %d after removing a document, want 290", h.Len())
+	}
+
+	// The nearest chunk to one of doc0's vectors is doc0's own, which must
+	// not come back once removed
+	hits := h.Search(vectors[0], 5, func(k Key) bool { return k.DocumentID != "doc1" })
+	if len(hits) != 5 {
+		t.Fatalf("got %d hits, want 5", len(hits))
+	}
+	for _, hit := range hits {
+		if hit.DocumentID == "doc0" || hit.DocumentID == "doc1" {
+			t.Errorf("hit from %s, which is removed or filtered", hit.DocumentID)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	for name, index := range map[string]Index{"flat": NewFlat(), "hnsw": NewHNSW()} {
+		t.Run(name, func(t *testing.T) {
+			index.Add(Key{DocumentID: "a", Start: 0}, []float32{1, 0})
+			index.Add(Key{DocumentID: "a", Start: 10}, []float32{0.6, 0.8})
+			index.Add(Key{DocumentID: "b"}, []float32{0.8, 0.6})
+
+			index.Replace("a", []Entry{{Key: Key{DocumentID: "a", Start: 20}, Vector: []float32{0, 1}}})
+			if index.Len() != 2 {
+				t.Errorf("Len = %d after replacing a, want 2", index.Len())
+			}
+			hits := index.Search([]float32{1, 0}, 5, nil)
+			if len(hits) != 2 || hits[0].DocumentID != "b" || hits[1].Start != 20 {
+				t.Errorf("hits = %+v, want b and a's new chunk", hits)
+			}
+		})
+	}
+}
+
+// TestHNSWRebuildsWhenMostlyRemoved checks removed nodes are dropped from
+// the graph once they outnumber the live ones, and searches still work
+func TestHNSWRebuildsWhenMostlyRemoved(t *testing.T) {
+	vectors := randomVectors(300, 16)
+	h := NewHNSW()
+	for i, v := range vectors {
+		h.Add(Key{DocumentID: fmt.Sprintf("doc%d", i%30), Start: i}, v)
+	}
+
+	for i := range 16 {
+		h.Remove(fmt.Sprintf("doc%d", i))
+	}
+	if h.Len() != 140 {
+		t.Errorf("Len = %d, want 140", h.Len())
+	}
+	if len(h.nodes) != h.Len() {
+		t.Errorf("graph has %d nodes for %d live chunks, want it rebuilt", len(h.nodes), h.Len())
+	}
+
+	// doc16 holds vector 16, the nearest chunk to itself
+	hits := h.Search(vectors[16], 5, nil)
+	if len(hits) != 5 || hits[0].DocumentID != "doc16" {
+		t.Errorf("hits = %+v, want doc16 first", hits)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
 )
 
 type WordDocument struct {
@@ -24,7 +27,22 @@ type BodyElement struct {
 }
 
 type Paragraph struct {
-	Runs []Run `xml:"r"`
+	Properties *ParagraphProperties `xml:"pPr"`
+	Runs       []Run                `xml:"r"`
+}
+
+type ParagraphProperties struct {
+	Style        *ValueAttr     `xml:"pStyle"`
+	OutlineLevel *ValueAttr     `xml:"outlineLvl"`
+	Numbering    *NumberingProp `xml:"numPr"`
+}
+
+type NumberingProp struct {
+	Level *ValueAttr `xml:"ilvl"`
+}
+
+type ValueAttr struct {
+	Val string `xml:"val,attr"`
 }
 
 type Table struct {
@@ -95,11 +113,96 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 }
 
 func ExtractDOCX(data []byte) (string, error) {
-	reader := bytes.NewReader(data)
+	return ExtractDOCXReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ExtractDOCXReader is ExtractDOCX for a file read on demand, such as an
+// upload spooled to a temporary file
+func ExtractDOCXReader(r io.ReaderAt, size int64) (string, error) {
+	doc, err := parseDOCX(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	// Extract text
+	var textBuilder strings.Builder
+
+	for _, element := range doc.Body.Content {
+		if element.Paragraph != nil {
+			extractParagraph(element.Paragraph, &textBuilder)
+			textBuilder.WriteString("\n")
+		} else if element.Table != nil {
+			extractTable(element.Table, &textBuilder)
+			textBuilder.WriteString("\n")
+		}
+	}
+
+	extractedText := strings.TrimSpace(textBuilder.String())
+
+	if extractedText == "" {
+		return "", fmt.Errorf("no text could be extracted from DOCX")
+	}
+
+	return extractedText, nil
+}
+
+// ExtractDOCXStructure extracts headings, paragraphs, list items and tables
+// from a DOCX file, keeping their order in the document body
+func ExtractDOCXStructure(data []byte) (*models.DocumentStructure, error) {
+	return ExtractDOCXStructureReader(bytes.NewReader(data), int64(len(data)))
+}
 
-	zipReader, err := zip.NewReader(reader, int64(len(data)))
+// ExtractDOCXStructureReader is ExtractDOCXStructure for a file read on demand
+func ExtractDOCXStructureReader(r io.ReaderAt, size int64) (*models.DocumentStructure, error) {
+	doc, err := parseDOCX(r, size)
 	if err != nil {
-		return "", fmt.Errorf("failed to read DOCX as ZIP: %w", err)
+		return nil, err
+	}
+
+	structure := &models.DocumentStructure{}
+
+	for _, element := range doc.Body.Content {
+		if element.Paragraph != nil {
+			var builder strings.Builder
+			extractParagraph(element.Paragraph, &builder)
+			text := strings.TrimSpace(builder.String())
+			if text == "" {
+				continue
+			}
+
+			blockType, level := classifyParagraph(element.Paragraph)
+			structure.Blocks = append(structure.Blocks, models.Block{
+				Type:  blockType,
+				Level: level,
+				Text:  text,
+			})
+		} else if element.Table != nil {
+			cells := tableCells(element.Table)
+			if len(cells) == 0 {
+				continue
+			}
+
+			var builder strings.Builder
+			extractTable(element.Table, &builder)
+			structure.Blocks = append(structure.Blocks, models.Block{
+				Type:  models.BlockTable,
+				Text:  strings.TrimSpace(builder.String()),
+				Cells: cells,
+			})
+		}
+	}
+
+	if len(structure.Blocks) == 0 {
+		return nil, fmt.Errorf("no structure could be extracted from DOCX")
+	}
+
+	return structure, nil
+}
+
+func parseDOCX(r io.ReaderAt, size int64) (*WordDocument, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DOCX as ZIP: %w", err)
 	}
 
 	// Find document.xml
@@ -112,47 +215,91 @@ func ExtractDOCX(data []byte) (string, error) {
 	}
 
 	if documentFile == nil {
-		return "", fmt.Errorf("document.xml not found in DOCX")
+		return nil, fmt.Errorf("document.xml not found in DOCX")
 	}
 
 	// Read document.xml
 	xmlFile, err := documentFile.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open document.xml: %w", err)
+		return nil, fmt.Errorf("failed to open document.xml: %w", err)
 	}
 	defer xmlFile.Close()
 
-	xmlData, err := io.ReadAll(xmlFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to read document.xml: %w", err)
+	// Decode straight from the archive rather than inflating it into memory first
+	var doc WordDocument
+	if err := xml.NewDecoder(xmlFile).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document.xml: %w", err)
 	}
 
-	// Parse XML
-	var doc WordDocument
-	if err := xml.Unmarshal(xmlData, &doc); err != nil {
-		return "", fmt.Errorf("failed to parse document.xml: %w", err)
+	return &doc, nil
+}
+
+// classifyParagraph maps Word paragraph styles onto block types.
+// Title and HeadingN styles become headings, outline levels are honoured
+// for custom heading styles, and numbered paragraphs become list items.
+func classifyParagraph(para *Paragraph) (models.BlockType, int) {
+	props := para.Properties
+	if props == nil {
+		return models.BlockParagraph, 0
 	}
 
-	// Extract text
-	var textBuilder strings.Builder
+	if props.Style != nil {
+		style := strings.ToLower(props.Style.Val)
+		switch {
+		case style == "title":
+			return models.BlockHeading, 1
+		case strings.HasPrefix(style, "heading"):
+			if level, err := strconv.Atoi(strings.TrimPrefix(style, "heading")); err == nil && level > 0 {
+				return models.BlockHeading, level
+			}
+		}
+	}
 
-	for _, element := range doc.Body.Content {
-		if element.Paragraph != nil {
-			extractParagraph(element.Paragraph, &textBuilder)
-			textBuilder.WriteString("\n")
-		} else if element.Table != nil {
-			extractTable(element.Table, &textBuilder)
-			textBuilder.WriteString("\n")
+	if props.OutlineLevel != nil {
+		// outlineLvl is 0-based and 9 means body text
+		if level, err := strconv.Atoi(props.OutlineLevel.Val); err == nil && level < 9 {
+			return models.BlockHeading, level + 1
 		}
 	}
 
-	extractedText := strings.TrimSpace(textBuilder.String())
+	if props.Numbering != nil {
+		level := 1
+		if props.Numbering.Level != nil {
+			if ilvl, err := strconv.Atoi(props.Numbering.Level.Val); err == nil {
+				level = ilvl + 1
+			}
+		}
+		return models.BlockListItem, level
+	}
 
-	if extractedText == "" {
-		return "", fmt.Errorf("no text could be extracted from DOCX")
+	if props.Style != nil && strings.EqualFold(props.Style.Val, "ListParagraph") {
+		return models.BlockListItem, 1
 	}
 
-	return extractedText, nil
+	return models.BlockParagraph, 0
+}
+
+func tableCells(table *Table) [][]string {
+	var rows [][]string
+	for _, row := range table.Rows {
+		var cells []string
+		hasText := false
+		for _, cell := range row.Cells {
+			var cellBuilder strings.Builder
+			for _, para := range cell.Paragraphs {
+				extractParagraph(&para, &cellBuilder)
+			}
+			cellText := strings.TrimSpace(cellBuilder.String())
+			if cellText != "" {
+				hasText = true
+			}
+			cells = append(cells, cellText)
+		}
+		if hasText {
+			rows = append(rows, cells)
+		}
+	}
+	return rows
 }
 
 func extractParagraph(para *Paragraph, builder *strings.Builder) {
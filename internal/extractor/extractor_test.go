@@ -3,6 +3,8 @@ package extractor
 import (
 	"os"
 	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
 )
 
 func TestExtractPDF(t *testing.T) {
@@ -40,3 +42,72 @@ func TestExtractDOCX(t *testing.T) {
 
 	t.Logf("Extracted DOCX text:\n%s", text)
 }
+
+func TestExtractPDFStructure(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.pdf")
+	if err != nil {
+		t.Fatalf("failed to read sample PDF: %v", err)
+	}
+
+	structure, err := ExtractPDFStructure(data)
+	if err != nil {
+		t.Fatalf("ExtractPDFStructure returned error: %v", err)
+	}
+
+	if len(structure.Blocks) == 0 {
+		t.Fatalf("ExtractPDFStructure returned no blocks")
+	}
+
+	for _, block := range structure.Blocks {
+		if block.Page < 1 || block.Page > structure.PageCount {
+			t.Errorf("block has page %d outside 1..%d", block.Page, structure.PageCount)
+		}
+	}
+}
+
+func TestExtractDOCXStructure(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.docx")
+	if err != nil {
+		t.Fatalf("failed to read sample DOCX: %v", err)
+	}
+
+	structure, err := ExtractDOCXStructure(data)
+	if err != nil {
+		t.Fatalf("ExtractDOCXStructure returned error: %v", err)
+	}
+
+	if len(structure.Blocks) == 0 {
+		t.Fatalf("ExtractDOCXStructure returned no blocks")
+	}
+}
+
+func TestExtractTXTStructure(t *testing.T) {
+	data := []byte("# Invoice\n\nBilled to ACME Ltd.\nDue on receipt.\n\nItem | Qty | Price\nWidget | 2 | 10.00\n\n- First note\n- Second note\n")
+
+	structure, err := ExtractTXTStructure(data)
+	if err != nil {
+		t.Fatalf("ExtractTXTStructure returned error: %v", err)
+	}
+
+	want := []models.BlockType{
+		models.BlockHeading,
+		models.BlockParagraph,
+		models.BlockTable,
+		models.BlockListItem,
+		models.BlockListItem,
+	}
+
+	if len(structure.Blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(structure.Blocks), len(want), structure.Blocks)
+	}
+
+	for i, block := range structure.Blocks {
+		if block.Type != want[i] {
+			t.Errorf("block %d: got type %s, want %s", i, block.Type, want[i])
+		}
+	}
+
+	if got := structure.Blocks[2].Cells[1][2]; got != "10.00" {
+		t.Errorf("table cell: got %q, want %q", got, "10.00")
+	}
+}
@@ -0,0 +1,339 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions toggles the steps of the normalization pipeline
+type NormalizeOptions struct {
+	UnicodeNFC        bool // compose characters to Unicode NFC
+	ExpandLigatures   bool // replace typographic ligatures such as ﬁ and ﬂ
+	Dehyphenate       bool // rejoin words hyphenated across line breaks
+	RemoveHeaders     bool // drop header and footer lines repeated across pages
+	StripControlChars bool // remove control and invisible formatting characters
+	CleanWhitespace   bool // unify exotic spaces and collapse runs of blank space
+}
+
+// DefaultNormalizeOptions enables every step
+func DefaultNormalizeOptions() NormalizeOptions {
+	return NormalizeOptions{
+		UnicodeNFC:        true,
+		ExpandLigatures:   true,
+		Dehyphenate:       true,
+		RemoveHeaders:     true,
+		StripControlChars: true,
+		CleanWhitespace:   true,
+	}
+}
+
+var ligatureReplacer = strings.NewReplacer(
+	"ﬀ", "ff",
+	"ﬁ", "fi",
+	"ﬂ", "fl",
+	"ﬃ", "ffi",
+	"ﬄ", "ffl",
+	"ﬅ", "st",
+	"ﬆ", "st",
+)
+
+var (
+	hyphenBreakPattern = regexp.MustCompile(`(\p{L})[-\x{2010}]\n[ \t]*(\p{Ll})`)
+	spaceRunPattern    = regexp.MustCompile(`[ \t]{2,}`)
+	blankRunPattern    = regexp.MustCompile(`\n{3,}`)
+	// pageNumberPattern and pageNumberLinePattern match page numbers in a
+	// running header or footer, e.g. "page 3 of 12", or a line that is
+	// only a number such as "- 3 -"
+	pageNumberPattern     = regexp.MustCompile(`\bpage\s*\d+(\s*(of|/)\s*\d+)?\b`)
+	pageNumberLinePattern = regexp.MustCompile(`^[-\x{2013}\x{2014}(\[ ]*\d+(\s*(of|/)\s*\d+)?[-\x{2013}\x{2014})\] ]*$`)
+)
+
+const (
+	// headerZone is how many lines at the top and bottom of a page are
+	// considered as header or footer candidates
+	headerZone = 3
+	// minHeaderPages is the fewest pages a line must repeat on
+	minHeaderPages = 2
+	// maxHeaderLength excludes long lines, which are body text
+	maxHeaderLength = 120
+)
+
+// Normalizer cleans up extracted text. PDF text in particular comes out with
+// ligatures, words hyphenated across lines and running headers and footers.
+type Normalizer struct {
+	opts NormalizeOptions
+}
+
+func NewNormalizer(opts NormalizeOptions) *Normalizer {
+	return &Normalizer{opts: opts}
+}
+
+// Normalize runs the pipeline over text that has no page boundaries
+func (n *Normalizer) Normalize(text string) string {
+	return n.NormalizePages([]string{text})
+}
+
+// NormalizePages runs the pipeline over the text of each page and joins the
+// pages with blank lines. Header and footer removal needs at least two pages.
+func (n *Normalizer) NormalizePages(pages []string) string {
+	cleaned := make([]string, len(pages))
+	for i, page := range pages {
+		cleaned[i] = n.normalizeChars(page)
+	}
+
+	if n.opts.RemoveHeaders {
+		cleaned = removeRepeatedLines(cleaned)
+	}
+
+	for i, page := range cleaned {
+		if n.opts.Dehyphenate {
+			page = hyphenBreakPattern.ReplaceAllString(page, "$1$2")
+		}
+		if n.opts.CleanWhitespace {
+			page = cleanWhitespace(page)
+		}
+		cleaned[i] = page
+	}
+
+	var nonEmpty []string
+	for _, page := range cleaned {
+		if page = strings.TrimSpace(page); page != "" {
+			nonEmpty = append(nonEmpty, page)
+		}
+	}
+
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+// NormalizeStructure applies the character level steps to every block and,
+// when headers are removed, drops blocks repeated at the edges of pages
+func (n *Normalizer) NormalizeStructure(structure *models.DocumentStructure) {
+	if structure == nil {
+		return
+	}
+
+	if n.opts.RemoveHeaders {
+		structure.Blocks = removeRepeatedBlocks(structure.Blocks)
+	}
+
+	for i := range structure.Blocks {
+		block := &structure.Blocks[i]
+		block.Text = n.normalizeInline(block.Text)
+		for _, row := range block.Cells {
+			for j := range row {
+				row[j] = n.normalizeInline(row[j])
+			}
+		}
+	}
+}
+
+func (n *Normalizer) normalizeChars(text string) string {
+	if n.opts.StripControlChars {
+		text = stripControlChars(text)
+	}
+	if n.opts.UnicodeNFC {
+		text = norm.NFC.String(text)
+	}
+	if n.opts.ExpandLigatures {
+		text = ligatureReplacer.Replace(text)
+	}
+	return text
+}
+
+func (n *Normalizer) normalizeInline(text string) string {
+	text = n.normalizeChars(text)
+	if n.opts.CleanWhitespace {
+		text = cleanWhitespace(text)
+	}
+	return strings.TrimSpace(text)
+}
+
+// stripControlChars removes control and format characters such as NUL, soft
+// hyphens and zero-width spaces, keeping newlines and tabs
+func stripControlChars(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r == '\r' {
+			return '\n'
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// cleanWhitespace turns non-breaking and other Unicode spaces into plain
+// spaces, collapses runs of spaces, trims lines and limits blank lines to one
+func cleanWhitespace(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, text)
+	text = spaceRunPattern.ReplaceAllString(text, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+
+	return blankRunPattern.ReplaceAllString(text, "\n\n")
+}
+
+// removeRepeatedLines drops lines near the top or bottom of a page that
+// recur on at least half of the pages. Page numbers are ignored when
+// comparing so running headers with page numbers are caught; other digits
+// are compared, so totals and dates in the body of short documents are kept.
+func removeRepeatedLines(pages []string) []string {
+	if len(pages) < minHeaderPages {
+		return pages
+	}
+
+	pageLines := make([][]string, len(pages))
+	edges := make([][]string, len(pages))
+	for i, page := range pages {
+		pageLines[i] = strings.Split(page, "\n")
+		edges[i] = edgeItems(pageLines[i])
+	}
+
+	repeated := repeatedKeys(edges)
+	if len(repeated) == 0 {
+		return pages
+	}
+
+	result := make([]string, len(pages))
+	for i, lines := range pageLines {
+		var kept []string
+		for j, line := range lines {
+			if isEdge(lines, j) && repeated[edgeKey(line)] {
+				continue
+			}
+			kept = append(kept, line)
+		}
+		result[i] = strings.Join(kept, "\n")
+	}
+
+	return result
+}
+
+// removeRepeatedBlocks applies the same header and footer detection to
+// blocks, grouping them by page
+func removeRepeatedBlocks(blocks []models.Block) []models.Block {
+	var pageOrder []int
+	byPage := map[int][]string{}
+	for _, block := range blocks {
+		if block.Page == 0 {
+			return blocks
+		}
+		if _, ok := byPage[block.Page]; !ok {
+			pageOrder = append(pageOrder, block.Page)
+		}
+		byPage[block.Page] = append(byPage[block.Page], block.Text)
+	}
+	if len(pageOrder) < minHeaderPages {
+		return blocks
+	}
+
+	edges := make([][]string, 0, len(pageOrder))
+	for _, page := range pageOrder {
+		edges = append(edges, edgeItems(byPage[page]))
+	}
+
+	repeated := repeatedKeys(edges)
+	if len(repeated) == 0 {
+		return blocks
+	}
+
+	var kept []models.Block
+	position := map[int]int{}
+	for _, block := range blocks {
+		texts := byPage[block.Page]
+		idx := position[block.Page]
+		position[block.Page]++
+		if isEdge(texts, idx) && repeated[edgeKey(block.Text)] {
+			continue
+		}
+		kept = append(kept, block)
+	}
+
+	return kept
+}
+
+// repeatedKeys returns the edge keys found on at least half of the pages
+func repeatedKeys(edges [][]string) map[string]bool {
+	counts := map[string]int{}
+	for _, items := range edges {
+		seen := map[string]bool{}
+		for _, item := range items {
+			key := edgeKey(item)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+		}
+	}
+
+	threshold := max(minHeaderPages, (len(edges)+1)/2)
+	repeated := map[string]bool{}
+	for key, count := range counts {
+		if count >= threshold {
+			repeated[key] = true
+		}
+	}
+
+	return repeated
+}
+
+// edgeItems returns the non-empty items within the header and footer zones
+func edgeItems(items []string) []string {
+	var edges []string
+	for i, item := range items {
+		if isEdge(items, i) {
+			edges = append(edges, item)
+		}
+	}
+	return edges
+}
+
+// isEdge reports whether the item at idx is among the first or last
+// headerZone non-empty items
+func isEdge(items []string, idx int) bool {
+	if strings.TrimSpace(items[idx]) == "" {
+		return false
+	}
+
+	before, after := 0, 0
+	for i, item := range items {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		if i < idx {
+			before++
+		} else if i > idx {
+			after++
+		}
+	}
+
+	return before < headerZone || after < headerZone
+}
+
+func edgeKey(line string) string {
+	if len(line) > maxHeaderLength {
+		return ""
+	}
+	key := strings.ToLower(strings.Join(strings.Fields(line), " "))
+	if pageNumberLinePattern.MatchString(key) {
+		return "#"
+	}
+	return pageNumberPattern.ReplaceAllString(key, "page #")
+}
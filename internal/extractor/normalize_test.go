@@ -0,0 +1,149 @@
+package extractor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestNormalizeSteps(t *testing.T) {
+	tests := []struct {
+		name string
+		opts NormalizeOptions
+		in   string
+		want string
+	}{
+		{
+			name: "unicode nfc",
+			opts: NormalizeOptions{UnicodeNFC: true},
+			in:   "cafe\u0301",
+			want: "caf\u00e9",
+		},
+		{
+			name: "ligatures",
+			opts: NormalizeOptions{ExpandLigatures: true},
+			in:   "ﬁnal ﬂow eﬀort",
+			want: "final flow effort",
+		},
+		{
+			name: "dehyphenate",
+			opts: NormalizeOptions{Dehyphenate: true},
+			in:   "the docu-\nment was signed",
+			want: "the document was signed",
+		},
+		{
+			name: "dehyphenate keeps capitalised continuation",
+			opts: NormalizeOptions{Dehyphenate: true},
+			in:   "Anglo-\nSaxon",
+			want: "Anglo-\nSaxon",
+		},
+		{
+			name: "control characters",
+			opts: NormalizeOptions{StripControlChars: true},
+			in:   "in\x00voice\u00ad total\u200b\x07",
+			want: "invoice total",
+		},
+		{
+			name: "whitespace",
+			opts: NormalizeOptions{CleanWhitespace: true},
+			in:   "Total due:   42  \n\n\n\nThanks",
+			want: "Total due: 42\n\nThanks",
+		},
+		{
+			name: "all steps disabled",
+			opts: NormalizeOptions{},
+			in:   "ﬁle  docu-\nment",
+			want: "ﬁle  docu-\nment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewNormalizer(tt.opts).Normalize(tt.in)
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePagesRemovesHeadersAndFooters(t *testing.T) {
+	pages := []string{
+		"ACME Corp Confidential\nFirst page body.\nPage 1 of 3",
+		"ACME Corp Confidential\nSecond page body.\nPage 2 of 3",
+		"ACME Corp Confidential\nThird page body.\nPage 3 of 3",
+	}
+
+	got := NewNormalizer(NormalizeOptions{RemoveHeaders: true}).NormalizePages(pages)
+	want := "First page body.\n\nSecond page body.\n\nThird page body."
+	if got != want {
+		t.Errorf("NormalizePages() = %q, want %q", got, want)
+	}
+
+	got = NewNormalizer(NormalizeOptions{}).NormalizePages(pages)
+	if !strings.Contains(got, "ACME Corp Confidential") {
+		t.Errorf("headers removed with RemoveHeaders disabled: %q", got)
+	}
+}
+
+// TestNormalizePagesKeepsNumbersOutsidePageNumbers checks lines that only
+// differ in a number, such as totals at the end of each page of a short
+// document, are not taken for a running footer
+func TestNormalizePagesKeepsNumbersOutsidePageNumbers(t *testing.T) {
+	pages := []string{
+		"Invoice 1001\nConsulting services.\nTotal: $1,200\n- 1 -",
+		"Invoice 1002\nTraining services.\nTotal: $3,400\n- 2 -",
+	}
+
+	got := NewNormalizer(NormalizeOptions{RemoveHeaders: true}).NormalizePages(pages)
+	want := "Invoice 1001\nConsulting services.\nTotal: $1,200\n\nInvoice 1002\nTraining services.\nTotal: $3,400"
+	if got != want {
+		t.Errorf("NormalizePages() = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeStructure(t *testing.T) {
+	structure := &models.DocumentStructure{
+		Blocks: []models.Block{
+			{Type: models.BlockParagraph, Page: 1, Text: "Running head"},
+			{Type: models.BlockHeading, Level: 1, Page: 1, Text: "ﬁrst  section"},
+			{Type: models.BlockParagraph, Page: 2, Text: "Running head"},
+			{Type: models.BlockTable, Page: 2, Text: "a | b", Cells: [][]string{{"eﬀort", " b "}}},
+		},
+	}
+
+	NewNormalizer(DefaultNormalizeOptions()).NormalizeStructure(structure)
+
+	if len(structure.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2: %+v", len(structure.Blocks), structure.Blocks)
+	}
+	if got := structure.Blocks[0].Text; got != "first section" {
+		t.Errorf("heading text = %q, want %q", got, "first section")
+	}
+	if got := structure.Blocks[1].Cells[0]; got[0] != "effort" || got[1] != "b" {
+		t.Errorf("table cells = %q", got)
+	}
+}
+
+func TestNormalizeSamplePDF(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.pdf")
+	if err != nil {
+		t.Fatalf("failed to read sample PDF: %v", err)
+	}
+
+	pages, err := ExtractPDFPages(data)
+	if err != nil {
+		t.Fatalf("ExtractPDFPages returned error: %v", err)
+	}
+
+	text := NewNormalizer(DefaultNormalizeOptions()).NormalizePages(pages)
+	if text == "" {
+		t.Fatalf("NormalizePages returned empty text")
+	}
+
+	if strings.Contains(text, "Running head") {
+		t.Errorf("running header survived normalization")
+	}
+}
@@ -3,20 +3,52 @@ package extractor
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
 	"github.com/ledongthuc/pdf"
 )
 
 func ExtractPDF(data []byte) (string, error) {
-	reader := bytes.NewReader(data)
-
-	pdfReader, err := pdf.NewReader(reader, int64(len(data)))
+	pages, err := ExtractPDFPages(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to create PDF reader: %w", err)
+		return "", err
 	}
 
 	var textBuilder strings.Builder
+	for _, text := range pages {
+		textBuilder.WriteString(text)
+		textBuilder.WriteString("\n")
+	}
+
+	extractedText := strings.TrimSpace(textBuilder.String())
+
+	if extractedText == "" {
+		return "", fmt.Errorf("no text could be extracted from PDF")
+	}
+
+	return extractedText, nil
+}
+
+// ExtractPDFPages returns the plain text of each readable page, which lets
+// the normalizer detect headers and footers repeated across pages
+func ExtractPDFPages(data []byte) ([]string, error) {
+	return ExtractPDFPagesReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ExtractPDFPagesReader is ExtractPDFPages for a PDF read on demand, such as
+// an upload spooled to a temporary file
+func ExtractPDFPagesReader(r io.ReaderAt, size int64) ([]string, error) {
+	pdfReader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF reader: %w", err)
+	}
+
+	var pages []string
 	numPages := pdfReader.NumPage()
 
 	for i := 1; i <= numPages; i++ {
@@ -30,15 +62,224 @@ func ExtractPDF(data []byte) (string, error) {
 			continue
 		}
 
-		textBuilder.WriteString(text)
-		textBuilder.WriteString("\n")
+		pages = append(pages, text)
 	}
 
-	extractedText := strings.TrimSpace(textBuilder.String())
+	return pages, nil
+}
 
-	if extractedText == "" {
-		return "", fmt.Errorf("no text could be extracted from PDF")
+var listItemPattern = regexp.MustCompile(`^([•·▪‣◦\-*]|\(?\d{1,3}[.)]|\(?[a-zA-Z][.)])\s+`)
+
+type pdfLine struct {
+	page     int
+	y        float64
+	fontSize float64
+	bold     bool
+	text     string
+}
+
+// ExtractPDFStructure extracts headings, paragraphs and list items from a PDF.
+// PDFs carry no semantic markup, so headings are inferred from lines set in a
+// larger font than the dominant body size, or from short lines set entirely
+// in bold, which rank below any size-based heading.
+func ExtractPDFStructure(data []byte) (*models.DocumentStructure, error) {
+	return ExtractPDFStructureReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ExtractPDFStructureReader is ExtractPDFStructure for a PDF read on demand
+func ExtractPDFStructureReader(r io.ReaderAt, size int64) (*models.DocumentStructure, error) {
+	pdfReader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF reader: %w", err)
 	}
 
-	return extractedText, nil
+	numPages := pdfReader.NumPage()
+	var lines []pdfLine
+
+	for i := 1; i <= numPages; i++ {
+		page := pdfReader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		pageLines, err := extractPageLines(page, i)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, pageLines...)
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no structure could be extracted from PDF")
+	}
+
+	bodySize := dominantFontSize(lines)
+	headingLevels := headingLevelsBySize(lines, bodySize)
+
+	structure := &models.DocumentStructure{PageCount: numPages}
+	var current *models.Block
+	var last pdfLine
+
+	flush := func() {
+		if current != nil {
+			structure.Blocks = append(structure.Blocks, *current)
+			current = nil
+		}
+	}
+
+	for _, line := range lines {
+		if level, ok := headingLevels[roundSize(line.fontSize)]; ok && isHeadingCandidate(line, bodySize) {
+			flush()
+			structure.Blocks = append(structure.Blocks, models.Block{
+				Type:  models.BlockHeading,
+				Level: level,
+				Page:  line.page,
+				Text:  line.text,
+			})
+			continue
+		}
+
+		if isBoldHeading(line, bodySize) {
+			flush()
+			structure.Blocks = append(structure.Blocks, models.Block{
+				Type:  models.BlockHeading,
+				Level: min(len(headingLevels)+1, 6),
+				Page:  line.page,
+				Text:  line.text,
+			})
+			continue
+		}
+
+		if listItemPattern.MatchString(line.text) {
+			flush()
+			current = &models.Block{Type: models.BlockListItem, Level: 1, Page: line.page, Text: line.text}
+			last = line
+			continue
+		}
+
+		// Lines continue the current block unless the page changes or the
+		// vertical gap is large enough to indicate a paragraph break
+		if current != nil && line.page == last.page && last.y-line.y <= line.fontSize*1.8 {
+			current.Text += " " + line.text
+			last = line
+			continue
+		}
+
+		flush()
+		current = &models.Block{Type: models.BlockParagraph, Page: line.page, Text: line.text}
+		last = line
+	}
+	flush()
+
+	return structure, nil
+}
+
+func extractPageLines(page pdf.Page, pageNum int) (lines []pdfLine, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			lines = nil
+			err = fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+
+	byY := map[float64][]pdf.Text{}
+	for _, t := range page.Content().Text {
+		if t.S == "\n" {
+			continue
+		}
+		y := math.Round(t.Y)
+		byY[y] = append(byY[y], t)
+	}
+
+	ys := make([]float64, 0, len(byY))
+	for y := range byY {
+		ys = append(ys, y)
+	}
+	// PDF coordinates increase bottom to top
+	sort.Sort(sort.Reverse(sort.Float64Slice(ys)))
+
+	for _, y := range ys {
+		texts := byY[y]
+		sort.SliceStable(texts, func(i, j int) bool { return texts[i].X < texts[j].X })
+
+		var builder strings.Builder
+		var fontSize float64
+		var prevEnd float64
+		bold := true
+		for i, t := range texts {
+			if i > 0 && t.X-prevEnd > t.FontSize*0.25 && !strings.HasSuffix(builder.String(), " ") {
+				builder.WriteString(" ")
+			}
+			builder.WriteString(t.S)
+			prevEnd = t.X + t.W
+			if strings.TrimSpace(t.S) == "" {
+				continue
+			}
+			if t.FontSize > fontSize {
+				fontSize = t.FontSize
+			}
+			if !strings.Contains(strings.ToLower(t.Font), "bold") {
+				bold = false
+			}
+		}
+
+		text := strings.Join(strings.Fields(builder.String()), " ")
+		if text == "" {
+			continue
+		}
+		lines = append(lines, pdfLine{page: pageNum, y: y, fontSize: fontSize, bold: bold, text: text})
+	}
+
+	return lines, nil
+}
+
+// dominantFontSize returns the font size covering the most characters,
+// which is taken to be the body text size
+func dominantFontSize(lines []pdfLine) float64 {
+	counts := map[float64]int{}
+	for _, line := range lines {
+		counts[roundSize(line.fontSize)] += len(line.text)
+	}
+
+	var size float64
+	best := -1
+	for s, count := range counts {
+		if count > best || (count == best && s < size) {
+			size, best = s, count
+		}
+	}
+	return size
+}
+
+// headingLevelsBySize assigns heading levels to font sizes larger than the
+// body size, largest first
+func headingLevelsBySize(lines []pdfLine, bodySize float64) map[float64]int {
+	seen := map[float64]bool{}
+	var sizes []float64
+	for _, line := range lines {
+		size := roundSize(line.fontSize)
+		if isHeadingCandidate(line, bodySize) && !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+
+	levels := make(map[float64]int, len(sizes))
+	for i, size := range sizes {
+		levels[size] = min(i+1, 6)
+	}
+	return levels
+}
+
+func isHeadingCandidate(line pdfLine, bodySize float64) bool {
+	return bodySize > 0 && line.fontSize >= bodySize*1.15 && len(line.text) <= 200
+}
+
+func isBoldHeading(line pdfLine, bodySize float64) bool {
+	return line.bold && line.fontSize >= bodySize && len(line.text) <= 100 && !strings.HasSuffix(line.text, ".")
+}
+
+func roundSize(size float64) float64 {
+	return math.Round(size*2) / 2
 }
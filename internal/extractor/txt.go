@@ -1,15 +1,30 @@
 package extractor
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
 	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/encoding/unicode"
+	textunicode "golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
+// ExtractTXTReader is ExtractTXT for a file read on demand. The decoded text
+// is the extraction result, so the file is read in full.
+func ExtractTXTReader(r io.ReaderAt, size int64) (string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("failed to read text file: %w", err)
+	}
+	return ExtractTXT(data)
+}
+
 func ExtractTXT(data []byte) (string, error) {
 	if len(data) == 0 {
 		return "", fmt.Errorf("empty text file")
@@ -35,7 +50,7 @@ func decodeText(data []byte) (string, error) {
 	}
 
 	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
-		decoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+		decoder := textunicode.UTF16(textunicode.LittleEndian, textunicode.UseBOM).NewDecoder()
 		decoded, _, err := transform.Bytes(decoder, data)
 		if err != nil {
 			return "", err
@@ -44,7 +59,7 @@ func decodeText(data []byte) (string, error) {
 	}
 
 	if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
-		decoder := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+		decoder := textunicode.UTF16(textunicode.BigEndian, textunicode.UseBOM).NewDecoder()
 		decoded, _, err := transform.Bytes(decoder, data)
 		if err != nil {
 			return "", err
@@ -140,3 +155,183 @@ func ValidateTXT(data []byte) error {
 
 	return nil
 }
+
+var markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*$`)
+
+// ExtractTXTStructure splits a plain text file into blocks. Paragraphs are
+// separated by blank lines; markdown and underlined headings, short all-caps
+// lines, list markers and pipe or tab separated rows are recognised.
+func ExtractTXTStructure(data []byte) (*models.DocumentStructure, error) {
+	return ExtractTXTStructureReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ExtractTXTStructureReader is ExtractTXTStructure for a file read on demand
+func ExtractTXTStructureReader(r io.ReaderAt, size int64) (*models.DocumentStructure, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read text file: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty text file")
+	}
+
+	text, err := decodeText(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode text file: %w", err)
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.ReplaceAll(text, "\x00", "")
+
+	structure := &models.DocumentStructure{}
+	for _, group := range splitParagraphs(text) {
+		structure.Blocks = append(structure.Blocks, txtBlocks(group)...)
+	}
+
+	if len(structure.Blocks) == 0 {
+		return nil, fmt.Errorf("no structure could be extracted from file")
+	}
+
+	return structure, nil
+}
+
+// splitParagraphs groups trimmed non-empty lines separated by blank lines
+func splitParagraphs(text string) [][]string {
+	var groups [][]string
+	var current []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(current) > 0 {
+				groups = append(groups, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+func txtBlocks(lines []string) []models.Block {
+	var blocks []models.Block
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, models.Block{Type: models.BlockParagraph, Text: strings.Join(paragraph, " ")})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := markdownHeadingPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, models.Block{Type: models.BlockHeading, Level: len(m[1]), Text: m[2]})
+			continue
+		}
+
+		// Setext style headings underline the title with = or -
+		if i+1 < len(lines) && isUnderline(lines[i+1]) {
+			flush()
+			level := 1
+			if lines[i+1][0] == '-' {
+				level = 2
+			}
+			blocks = append(blocks, models.Block{Type: models.BlockHeading, Level: level, Text: line})
+			i++
+			continue
+		}
+
+		if isTableRow(line) {
+			end := i
+			for end < len(lines) && isTableRow(lines[end]) {
+				end++
+			}
+			if end-i >= 2 {
+				flush()
+				blocks = append(blocks, tableBlock(lines[i:end]))
+				i = end - 1
+				continue
+			}
+		}
+
+		if listItemPattern.MatchString(line) {
+			flush()
+			blocks = append(blocks, models.Block{Type: models.BlockListItem, Level: 1, Text: line})
+			continue
+		}
+
+		if len(lines) == 1 && isAllCapsTitle(line) {
+			blocks = append(blocks, models.Block{Type: models.BlockHeading, Level: 1, Text: line})
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+	flush()
+
+	return blocks
+}
+
+func isUnderline(line string) bool {
+	if len(line) < 3 {
+		return false
+	}
+	return strings.Trim(line, "=") == "" || strings.Trim(line, "-") == ""
+}
+
+func isTableRow(line string) bool {
+	return strings.Count(line, "|") >= 2 || strings.Count(line, "\t") >= 1
+}
+
+func tableBlock(lines []string) models.Block {
+	var cells [][]string
+	var text []string
+
+	for _, line := range lines {
+		var row []string
+		if strings.Contains(line, "|") {
+			// Skip markdown separator rows such as |---|---|
+			if strings.Trim(line, "|-: ") == "" {
+				continue
+			}
+			for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+				row = append(row, strings.TrimSpace(cell))
+			}
+		} else {
+			for _, cell := range strings.Split(line, "\t") {
+				row = append(row, strings.TrimSpace(cell))
+			}
+		}
+		cells = append(cells, row)
+		text = append(text, strings.Join(row, " | "))
+	}
+
+	return models.Block{Type: models.BlockTable, Text: strings.Join(text, "\n"), Cells: cells}
+}
+
+func isAllCapsTitle(line string) bool {
+	if len(line) > 80 || strings.ContainsAny(line[len(line)-1:], ".,;") {
+		return false
+	}
+	hasLetter := false
+	for _, r := range line {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			if !unicode.IsUpper(r) {
+				return false
+			}
+		}
+	}
+	return hasLetter
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// maxAskBodySize bounds the JSON body of a question
+const maxAskBodySize = 64 << 10
+
+func (h *DocumentHandler) AskDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAskBodySize)
+
+	var req models.AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, utils.NewBadRequestError("Invalid JSON body"))
+		return
+	}
+
+	resp, err := h.service.AskDocument(r.Context(), id, &req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
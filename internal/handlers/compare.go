@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// maxCompareBodySize bounds the JSON body of a comparison request
+const maxCompareBodySize = 4 << 10
+
+func (h *DocumentHandler) CompareDocuments(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCompareBodySize)
+
+	var req models.CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, utils.NewBadRequestError("Invalid JSON body"))
+		return
+	}
+
+	resp, err := h.service.CompareDocuments(r.Context(), &req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
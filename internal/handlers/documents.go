@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
@@ -14,56 +17,67 @@ import (
 )
 
 const (
-	MaxFileSize = 5 << 20 // 5MB
+	DefaultListLimit = 20
+	MaxListLimit     = 100
 )
 
 type DocumentHandler struct {
-	service services.DocumentService
-	logger  *utils.Logger
+	service     services.DocumentService
+	maxFileSize int64
+	logger      *utils.Logger
 }
 
-func NewDocumentHandler(service services.DocumentService, logger *utils.Logger) *DocumentHandler {
+func NewDocumentHandler(service services.DocumentService, maxFileSize int64, logger *utils.Logger) *DocumentHandler {
 	return &DocumentHandler{
-		service: service,
-		logger:  logger,
+		service:     service,
+		maxFileSize: maxFileSize,
+		logger:      logger,
 	}
 }
 
 func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	sizeLimitMessage := fmt.Sprintf("File size exceeds %s limit", formatSize(h.maxFileSize))
+
 	// Check Content-Length header first to reject oversized requests early
-	if r.ContentLength > MaxFileSize {
-		h.respondError(w, utils.NewBadRequestError("File size exceeds 5MB limit"))
+	if r.ContentLength > h.maxFileSize {
+		h.respondError(w, utils.NewBadRequestError(sizeLimitMessage))
 		return
 	}
 
-	// Limit the request body size to prevent memory exhaustion
-	r.Body = http.MaxBytesReader(w, r.Body, MaxFileSize)
+	// Limit the request body size to prevent disk exhaustion
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize)
 
-	// Parse multipart form with size limit
-	if err := r.ParseMultipartForm(MaxFileSize); err != nil {
-		// Check if error is due to size limit
-		if strings.Contains(err.Error(), "request body too large") ||
-			strings.Contains(err.Error(), "multipart: NextPart: http: request body too large") {
-			h.respondError(w, utils.NewBadRequestError("File size exceeds 5MB limit"))
-			return
-		}
+	// Read the multipart body part by part instead of parsing the whole form,
+	// so the file is never held in memory
+	reader, err := r.MultipartReader()
+	if err != nil {
 		h.respondError(w, utils.NewBadRequestError("Invalid form data"))
 		return
 	}
 
-	file, header, err := r.FormFile("file")
+	part, err := nextFilePart(reader)
 	if err != nil {
-		h.respondError(w, utils.NewBadRequestError("No file provided"))
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			h.respondError(w, utils.NewBadRequestError(sizeLimitMessage))
+		case errors.Is(err, errNoFilePart):
+			h.respondError(w, utils.NewBadRequestError("No file provided"))
+		default:
+			h.respondError(w, utils.NewBadRequestError("Invalid form data"))
+		}
 		return
 	}
-	defer file.Close()
+	defer part.Close()
+
+	filename := part.FileName()
 
 	// Determine content type with fallback to file extension
-	contentType := determineContentType(header.Filename, header.Header.Get("Content-Type"))
+	contentType := determineContentType(filename, part.Header.Get("Content-Type"))
 
 	h.logger.Info("File upload attempt",
-		"filename", header.Filename,
-		"reported_content_type", header.Header.Get("Content-Type"),
+		"filename", filename,
+		"reported_content_type", part.Header.Get("Content-Type"),
 		"determined_content_type", contentType)
 
 	// Validate content type
@@ -72,30 +86,35 @@ func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Read file data with size limit
-	data, err := io.ReadAll(io.LimitReader(file, MaxFileSize+1))
+	// Spool the file to disk with size limit
+	file, size, err := spoolToTempFile(part, h.maxFileSize)
+	if file != nil {
+		defer removeTempFile(file)
+	}
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, errFileTooLarge) || errors.As(err, &maxBytesErr) {
+			h.respondError(w, utils.NewBadRequestError(sizeLimitMessage))
+			return
+		}
+		h.logger.Error("Failed to spool upload", "error", err, "filename", filename)
 		h.respondError(w, utils.NewInternalError("Failed to read file"))
 		return
 	}
 
-	// Check if file exceeded size limit
-	if len(data) > MaxFileSize {
-		h.respondError(w, utils.NewBadRequestError("File size exceeds 5MB limit"))
-		return
-	}
-
 	// Validate file is not empty
-	if len(data) == 0 {
+	if size == 0 {
 		h.respondError(w, utils.NewBadRequestError("Uploaded file is empty"))
 		return
 	}
 
 	// Process upload
 	req := &models.UploadRequest{
-		File:        data,
-		Filename:    header.Filename,
+		File:        file,
+		Size:        size,
+		Filename:    filename,
 		ContentType: contentType,
+		Dedupe:      r.URL.Query().Get("dedupe") != "false",
 	}
 
 	resp, err := h.service.UploadDocument(r.Context(), req)
@@ -104,7 +123,12 @@ func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	h.respondJSON(w, http.StatusCreated, resp)
+	status := http.StatusCreated
+	if resp.Duplicate {
+		status = http.StatusOK
+	}
+
+	h.respondJSON(w, status, resp)
 }
 
 func (h *DocumentHandler) AnalyzeDocument(w http.ResponseWriter, r *http.Request) {
@@ -116,7 +140,9 @@ func (h *DocumentHandler) AnalyzeDocument(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	resp, err := h.service.AnalyzeDocument(r.Context(), id)
+	req := analyzeRequest(r)
+
+	resp, err := h.service.AnalyzeDocument(r.Context(), id, req)
 	if err != nil {
 		h.respondError(w, err)
 		return
@@ -143,6 +169,68 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, doc)
 }
 
+func (h *DocumentHandler) ListAnalyses(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListAnalyses(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, offset, err := parsePage(query)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	filter := models.DocumentFilter{
+		Language:     strings.ToLower(query.Get("language")),
+		DocumentType: query.Get("document_type"),
+		NeedsReview:  query.Get("needs_review") == "true",
+		Limit:        limit,
+		Offset:       offset,
+	}
+
+	resp, err := h.service.ListDocuments(r.Context(), filter)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) GetDocumentStructure(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.GetDocumentStructure(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
 // determineContentType determines the content type from filename extension
 // with fallback to the provided content type header
 func determineContentType(filename, headerContentType string) string {
@@ -171,6 +259,59 @@ func determineContentType(filename, headerContentType string) string {
 	return headerContentType
 }
 
+// formatSize renders a byte count for error messages, e.g. 5MB or 512KB
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
+
+// analyzeRequest reads the query parameters shared by the analyze and
+// stream endpoints
+func analyzeRequest(r *http.Request) *models.AnalyzeRequest {
+	query := r.URL.Query()
+	return &models.AnalyzeRequest{
+		TargetLanguage: strings.ToLower(query.Get("language")),
+		Force:          query.Get("force") == "true",
+		Redact:         query.Get("redact"),
+		Style:          query.Get("style"),
+		Length:         query.Get("length"),
+	}
+}
+
+// parsePage reads the limit and offset query parameters of list endpoints.
+// Limits above MaxListLimit are capped rather than rejected.
+func parsePage(query url.Values) (int, int, error) {
+	limit, err := parseIntParam(query.Get("limit"), DefaultListLimit)
+	if err != nil || limit < 1 {
+		return 0, 0, utils.NewBadRequestError("limit must be a positive integer")
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	offset, err := parseIntParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		return 0, 0, utils.NewBadRequestError("offset must be a non-negative integer")
+	}
+	return limit, offset, nil
+}
+
+// parseIntParam parses an optional integer query parameter
+func parseIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
 // isValidContentType checks if the content type is supported
 func isValidContentType(contentType string) bool {
 	validTypes := map[string]bool{
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+func (h *DocumentHandler) ListEntities(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListEntities(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) SearchEntities(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, offset, err := parsePage(query)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	filter := models.EntityFilter{
+		Type:   query.Get("type"),
+		Value:  query.Get("value"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	resp, err := h.service.SearchEntities(r.Context(), filter)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,7 @@
+package handlers
+
+import "net/http"
+
+func (h *DocumentHandler) Health(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, h.service.Health(r.Context()))
+}
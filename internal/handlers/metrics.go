@@ -0,0 +1,13 @@
+package handlers
+
+import "net/http"
+
+func (h *DocumentHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Metrics(r.Context())
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+func (h *DocumentHandler) ListRedactions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListRedactions(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// maxCorrectionBodySize bounds the JSON body of a correction
+const maxCorrectionBodySize = 64 << 10
+
+func (h *DocumentHandler) CorrectAnalysis(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCorrectionBodySize)
+
+	var req models.CorrectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, utils.NewBadRequestError("Invalid JSON body"))
+		return
+	}
+
+	resp, err := h.service.CorrectAnalysis(r.Context(), id, &req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) ListCorrections(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListCorrections(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) ReviewQueue(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePage(r.URL.Query())
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	resp, err := h.service.ReviewQueue(r.Context(), limit, offset)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
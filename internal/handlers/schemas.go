@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// maxSchemaBodySize bounds the JSON body of a schema definition
+const maxSchemaBodySize = 1 << 20
+
+func (h *DocumentHandler) CreateSchema(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSchemaBodySize)
+
+	var req models.CreateSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, utils.NewBadRequestError("Invalid JSON body"))
+		return
+	}
+
+	schema, err := h.service.CreateSchema(r.Context(), &req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, schema)
+}
+
+func (h *DocumentHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ListSchemas(r.Context())
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	if name == "" {
+		h.respondError(w, utils.NewBadRequestError("Schema name is required"))
+		return
+	}
+
+	schema, err := h.service.GetSchema(r.Context(), name)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, schema)
+}
+
+func (h *DocumentHandler) ExtractDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	schemaName := r.URL.Query().Get("schema")
+	if schemaName == "" {
+		h.respondError(w, utils.NewBadRequestError("schema query parameter is required"))
+		return
+	}
+
+	resp, err := h.service.ExtractDocument(r.Context(), id, schemaName, r.URL.Query().Get("redact"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) ListExtractions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListExtractions(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
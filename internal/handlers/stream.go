@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// AnalyzeDocumentStream analyzes a document like AnalyzeDocument but sends
+// the model output as server-sent events while it is generated:
+//
+//	stage    {"stage": "classify"}    a stage of the analysis started
+//	token    {"text": "..."}          a piece of model output
+//	restart  {"reason": "retry"}      discard the tokens received so far
+//	result   AnalysisResponse         the parsed result, sent last
+//	error    {"error": "...", "status": 503}
+//
+// Errors before the first event, such as an unknown document, are plain
+// JSON error responses. Once the stream has started a comment is sent every
+// streamPingInterval, so proxies do not close a stream waiting on the model.
+func (h *DocumentHandler) AnalyzeDocumentStream(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	req := analyzeRequest(r)
+
+	events := &sseWriter{w: w, rc: http.NewResponseController(w), h: h}
+
+	stop := events.keepAlive(streamPingInterval)
+	resp, err := h.service.AnalyzeDocumentStream(r.Context(), id, req, events)
+	stop()
+	if err != nil {
+		if !events.started {
+			h.respondError(w, err)
+			return
+		}
+
+		status, message := http.StatusInternalServerError, "Internal server error"
+		if appErr, ok := err.(*utils.AppError); ok {
+			status, message = appErr.StatusCode, appErr.Message
+		}
+		h.logger.Error("Request error", "status", status, "error", message)
+		events.send("error", map[string]any{"error": message, "status": status})
+		return
+	}
+
+	events.send("result", resp)
+}
+
+// streamPingInterval is how often a started stream sends a comment
+const streamPingInterval = 15 * time.Second
+
+// sseWriter writes server-sent events, sending the response headers with
+// the first event. It implements analyzer.TokenSink.
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	h       *DocumentHandler
+	started bool
+}
+
+func (s *sseWriter) Token(text string) {
+	s.send("token", map[string]string{"text": text})
+}
+
+func (s *sseWriter) Restart(reason string) {
+	s.send("restart", map[string]string{"reason": reason})
+}
+
+func (s *sseWriter) Stage(name string) {
+	s.send("stage", map[string]string{"stage": name})
+}
+
+// keepAlive pings the stream every interval until stop is called
+func (s *sseWriter) keepAlive(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				s.ping()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// ping writes a comment, which clients ignore, to a started stream
+func (s *sseWriter) ping() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		return
+	}
+	fmt.Fprint(s.w, ": ping\n\n")
+	if err := s.rc.Flush(); err != nil {
+		s.h.logger.Warn("Failed to flush stream ping", "error", err)
+	}
+}
+
+func (s *sseWriter) send(event string, data any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		s.started = true
+		// Analysis can outlast the server's write timeout
+		if err := s.rc.SetWriteDeadline(time.Time{}); err != nil {
+			s.h.logger.Warn("Failed to clear write deadline for stream", "error", err)
+		}
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.h.logger.Error("Failed to encode stream event", "event", event, "error", err)
+		return
+	}
+
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload)
+	if err := s.rc.Flush(); err != nil {
+		s.h.logger.Error("Failed to flush stream event", "event", event, "error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+func (h *DocumentHandler) TranslateDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	req := &models.TranslateRequest{
+		TargetLanguage: query.Get("target"),
+		Force:          query.Get("force") == "true",
+		Redact:         query.Get("redact"),
+	}
+
+	resp, err := h.service.TranslateDocument(r.Context(), id, req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *DocumentHandler) ListTextVersions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID is required"))
+		return
+	}
+
+	resp, err := h.service.ListTextVersions(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// DownloadTextVersion sends a text version as a file attachment
+func (h *DocumentHandler) DownloadTextVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, versionID := vars["id"], vars["versionId"]
+
+	if id == "" || versionID == "" {
+		h.respondError(w, utils.NewBadRequestError("Document ID and version ID are required"))
+		return
+	}
+
+	file, err := h.service.DownloadTextVersion(r.Context(), id, versionID, r.URL.Query().Get("format"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(file.Data)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ReplaceAll(file.Filename, `"`, "")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file.Data)
+}
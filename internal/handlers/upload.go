@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+)
+
+var (
+	errNoFilePart   = errors.New("no file part in form")
+	errFileTooLarge = errors.New("file exceeds size limit")
+)
+
+// nextFilePart advances the multipart reader to the "file" form field,
+// discarding any fields sent before it
+func nextFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errNoFilePart
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == "file" && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// spoolToTempFile copies src to a temporary file, failing with
+// errFileTooLarge once more than limit bytes have been read. The returned
+// file is positioned at its start and must be released with removeTempFile,
+// even when an error is returned alongside it.
+func spoolToTempFile(src io.Reader, limit int64) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := io.Copy(file, io.LimitReader(src, limit+1))
+	if err != nil {
+		return file, size, err
+	}
+	if size > limit {
+		return file, size, errFileTooLarge
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return file, size, err
+	}
+
+	return file, size, nil
+}
+
+func removeTempFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/services"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// uploadService records the file it is given. Its other methods are not
+// used by the upload handler.
+type uploadService struct {
+	services.DocumentService
+	content []byte
+	err     error
+}
+
+func (s *uploadService) UploadDocument(ctx context.Context, req *models.UploadRequest) (*models.UploadResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	content, err := io.ReadAll(io.NewSectionReader(req.File, 0, req.Size))
+	if err != nil {
+		return nil, err
+	}
+	s.content = content
+	return &models.UploadResponse{ID: "doc", Filename: req.Filename, FileSize: req.Size, ContentType: req.ContentType}, nil
+}
+
+// formField is a multipart form field; a filename makes it a file part
+type formField struct {
+	name     string
+	filename string
+	content  string
+}
+
+func multipartBody(t *testing.T, fields ...formField) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range fields {
+		header := textproto.MIMEHeader{}
+		disposition := `form-data; name="` + field.name + `"`
+		if field.filename != "" {
+			disposition += `; filename="` + field.filename + `"`
+			header.Set("Content-Type", "text/plain")
+		}
+		header.Set("Content-Disposition", disposition)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(field.content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+// upload sends body to the upload handler, with temporary files created in
+// a directory of their own that is returned for inspection
+func upload(t *testing.T, service *uploadService, maxFileSize int64, body io.Reader, contentType string, contentLength int64) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/documents/upload", body)
+	r.Header.Set("Content-Type", contentType)
+	r.ContentLength = contentLength
+	w := httptest.NewRecorder()
+
+	NewDocumentHandler(service, maxFileSize, utils.NewLogger("error")).UploadDocument(w, r)
+	return w, tmp
+}
+
+func errorMessage(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q is not a JSON error: %v", w.Body.String(), err)
+	}
+	return body["error"]
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("temporary file %s was left behind", entry.Name())
+	}
+}
+
+func TestUploadDocument(t *testing.T) {
+	service := &uploadService{}
+	// Fields before the file part are skipped
+	body, contentType := multipartBody(t,
+		formField{name: "note", content: "ignored"},
+		formField{name: "file", filename: "notes.txt", content: "Meeting notes"},
+	)
+
+	w, tmp := upload(t, service, 1<<20, body, contentType, int64(body.Len()))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if string(service.content) != "Meeting notes" {
+		t.Errorf("service got %q, want the uploaded file", service.content)
+	}
+	assertNoTempFiles(t, tmp)
+}
+
+func TestUploadDocumentRejects(t *testing.T) {
+	const limit = 1 << 10
+	large := strings.Repeat("a", 2*limit)
+
+	tests := []struct {
+		name   string
+		fields []formField
+		// chunked leaves the length unknown, so the limit is only hit
+		// while the file is read
+		chunked bool
+		status  int
+		message string
+	}{
+		{
+			name:    "oversized by content length",
+			fields:  []formField{{name: "file", filename: "big.txt", content: large}},
+			status:  http.StatusBadRequest,
+			message: "File size exceeds 1KB limit",
+		},
+		{
+			name:    "oversized while reading",
+			fields:  []formField{{name: "file", filename: "big.txt", content: large}},
+			chunked: true,
+			status:  http.StatusBadRequest,
+			message: "File size exceeds 1KB limit",
+		},
+		{
+			name:    "no file part",
+			fields:  []formField{{name: "note", content: "no file here"}},
+			status:  http.StatusBadRequest,
+			message: "No file provided",
+		},
+		{
+			name:    "empty file",
+			fields:  []formField{{name: "file", filename: "empty.txt"}},
+			status:  http.StatusBadRequest,
+			message: "Uploaded file is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &uploadService{}
+			body, contentType := multipartBody(t, tt.fields...)
+			length := int64(body.Len())
+			if tt.chunked {
+				length = -1
+			}
+
+			w, tmp := upload(t, service, limit, body, contentType, length)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := errorMessage(t, w); got != tt.message {
+				t.Errorf("error = %q, want %q", got, tt.message)
+			}
+			if service.content != nil {
+				t.Errorf("service was called with %q", service.content)
+			}
+			assertNoTempFiles(t, tmp)
+		})
+	}
+}
+
+func TestUploadDocumentRemovesTempFileOnServiceError(t *testing.T) {
+	service := &uploadService{err: errors.New("storage is down")}
+	body, contentType := multipartBody(t, formField{name: "file", filename: "notes.txt", content: "Meeting notes"})
+
+	w, tmp := upload(t, service, 1<<20, body, contentType, int64(body.Len()))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	assertNoTempFiles(t, tmp)
+}
+
+func TestSpoolToTempFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	file, size, err := spoolToTempFile(strings.NewReader("12345"), 5)
+	if err != nil || size != 5 {
+		t.Fatalf("spoolToTempFile = %d, %v, want 5 bytes", size, err)
+	}
+	content, _ := io.ReadAll(file)
+	removeTempFile(file)
+	if string(content) != "12345" {
+		t.Errorf("spooled %q, want the whole input from its start", content)
+	}
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("removeTempFile left %s behind", file.Name())
+	}
+
+	// The file is returned with the error so it can be removed
+	file, _, err = spoolToTempFile(strings.NewReader("123456"), 5)
+	if !errors.Is(err, errFileTooLarge) || file == nil {
+		t.Fatalf("spoolToTempFile over the limit = %v, %v, want the file and errFileTooLarge", file, err)
+	}
+	removeTempFile(file)
+}
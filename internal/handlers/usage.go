@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+const dateLayout = "2006-01-02"
+
+func (h *DocumentHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.UsageFilter{
+		From: query.Get("from"),
+		To:   query.Get("to"),
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, value); err != nil {
+			h.respondError(w, utils.NewBadRequestError(param+" must be a date in YYYY-MM-DD format"))
+			return
+		}
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		h.respondError(w, utils.NewBadRequestError("from must not be after to"))
+		return
+	}
+
+	resp, err := h.service.GetUsage(r.Context(), filter)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
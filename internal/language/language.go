@@ -0,0 +1,150 @@
+package language
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// names maps the ISO 639-1 codes accepted as summary and translation target
+// languages to their English names, which is how they are referred to in
+// prompts. Only the languages in stopwords can be detected.
+var names = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sw": "Swahili",
+	"zh": "Chinese",
+}
+
+// stopwords holds frequent function words for each detectable language.
+// Detection counts how many tokens of the text fall in each list, which is
+// reliable for prose of a few sentences and needs no external model.
+var stopwords = map[string][]string{
+	"en": {
+		"the", "and", "of", "to", "in", "is", "that", "for", "it", "with",
+		"as", "was", "on", "are", "be", "by", "this", "have", "from", "or",
+		"an", "not", "which", "but", "at", "we", "they", "you", "has", "their",
+		"will", "would", "been", "were", "there", "our", "all", "your", "these", "should",
+	},
+	"fr": {
+		"le", "la", "les", "de", "des", "du", "et", "est", "un", "une",
+		"en", "que", "qui", "dans", "pour", "pas", "sur", "au", "aux", "avec",
+		"ce", "cette", "il", "elle", "nous", "vous", "ils", "sont", "par", "plus",
+		"mais", "ou", "son", "sa", "ses", "leur", "été", "être", "avoir", "à",
+	},
+	"sw": {
+		"na", "ya", "wa", "kwa", "za", "la", "katika", "ni", "cha", "hii",
+		"kama", "lakini", "pia", "kwamba", "hiyo", "yake", "zao", "wake", "huo", "hizo",
+		"vya", "kuwa", "au", "sana", "bila", "baada", "kabla", "hata", "kila", "wao",
+		"sisi", "wewe", "mimi", "yeye", "nini", "hapa", "pamoja", "tena", "ndani", "juu",
+	},
+	"es": {
+		"el", "la", "los", "las", "de", "del", "y", "que", "en", "un",
+		"una", "es", "por", "con", "para", "no", "se", "su", "al", "lo",
+		"como", "más", "pero", "sus", "le", "ya", "este", "esta", "entre", "cuando",
+	},
+	"de": {
+		"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den",
+		"mit", "von", "sich", "des", "auf", "für", "im", "dem", "auch", "es",
+		"an", "werden", "aus", "er", "hat", "dass", "sie", "nach", "wird", "bei",
+	},
+	"pt": {
+		"o", "a", "os", "as", "de", "do", "da", "dos", "das", "e",
+		"que", "em", "um", "uma", "para", "com", "não", "por", "mais", "se",
+		"no", "na", "como", "mas", "foi", "ao", "ele", "ela", "seu", "sua",
+	},
+}
+
+var stopwordSets = buildStopwordSets()
+
+// minMatches is the fewest stopword hits needed before a guess is made
+const minMatches = 3
+
+// sampleTokens bounds how much text is examined
+const sampleTokens = 2000
+
+func buildStopwordSets() map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(stopwords))
+	for code, words := range stopwords {
+		set := make(map[string]bool, len(words))
+		for _, w := range words {
+			set[w] = true
+		}
+		sets[code] = set
+	}
+	return sets
+}
+
+// Detect returns the ISO 639-1 code of the language text is most likely
+// written in, with a confidence between 0 and 1. It returns an empty code
+// when the text is too short or matches no known language.
+func Detect(text string) (string, float64) {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(tokens) > sampleTokens {
+		tokens = tokens[:sampleTokens]
+	}
+
+	scores := make(map[string]int, len(stopwordSets))
+	total := 0
+	for _, token := range tokens {
+		for code, set := range stopwordSets {
+			if set[token] {
+				scores[code]++
+				total++
+			}
+		}
+	}
+
+	best, bestScore := "", 0
+	for code, score := range scores {
+		if score > bestScore || (score == bestScore && code < best) {
+			best, bestScore = code, score
+		}
+	}
+
+	if bestScore < minMatches {
+		return "", 0
+	}
+
+	return best, float64(bestScore) / float64(total)
+}
+
+// Name returns the English name of a supported language code
+func Name(code string) (string, bool) {
+	name, ok := names[strings.ToLower(code)]
+	return name, ok
+}
+
+// IsSupported reports whether code can be used as a target language
+func IsSupported(code string) bool {
+	_, ok := Name(code)
+	return ok
+}
+
+// IsDetectable reports whether Detect can return code, so whether documents
+// can be in that language
+func IsDetectable(code string) bool {
+	_, ok := stopwords[strings.ToLower(code)]
+	return ok
+}
+
+// Detectable returns the codes Detect can return, sorted
+func Detectable() []string {
+	codes := make([]string, 0, len(stopwords))
+	for code := range stopwords {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
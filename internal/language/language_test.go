@@ -0,0 +1,57 @@
+package language
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "english",
+			text: "This agreement is made between the parties and will be governed by the laws of the state.",
+			want: "en",
+		},
+		{
+			name: "french",
+			text: "La facture est payable dans les trente jours et le montant doit être versé sur le compte de la société.",
+			want: "fr",
+		},
+		{
+			name: "swahili",
+			text: "Mkataba huu ni kati ya kampuni na mteja wake, na malipo yatafanywa kwa kila mwezi katika benki ya taifa.",
+			want: "sw",
+		},
+		{
+			name: "too short",
+			text: "Invoice 42",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := Detect(tt.text)
+			if got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectableAndTargetLanguages(t *testing.T) {
+	for _, code := range Detectable() {
+		if !IsSupported(code) {
+			t.Errorf("detectable language %s is not a target language", code)
+		}
+	}
+	if !IsDetectable("SW") {
+		t.Error("sw is not detectable")
+	}
+	// Japanese can be asked for as a target language, but documents are
+	// never detected as Japanese
+	if !IsSupported("ja") || IsDetectable("ja") {
+		t.Errorf("ja: IsSupported = %v, IsDetectable = %v, want true, false", IsSupported("ja"), IsDetectable("ja"))
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Analysis is one recorded run of the analyzer against a document
+type Analysis struct {
+	ID               string                 `json:"id" db:"id"`
+	DocumentID       string                 `json:"document_id" db:"document_id"`
+	Model            string                 `json:"model" db:"model"`
+	PromptVersion    string                 `json:"prompt_version" db:"prompt_version"`
+	Summary          string                 `json:"summary" db:"summary"`
+	SummaryLanguage  string                 `json:"summary_language,omitempty" db:"summary_language"`
+	SummaryStyle     string                 `json:"summary_style,omitempty" db:"summary_style"`
+	SummaryLength    string                 `json:"summary_length,omitempty" db:"summary_length"`
+	DocumentType     string                 `json:"document_type" db:"document_type"`
+	Metadata         map[string]interface{} `json:"metadata" db:"metadata"`
+	LatencyMS        int64                  `json:"latency_ms" db:"latency_ms"`
+	PromptTokens     int64                  `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int64                  `json:"completion_tokens" db:"completion_tokens"`
+	CostUSD          *float64               `json:"cost_usd,omitempty" db:"cost_usd"`
+	TypeConfidence   *float64               `json:"type_confidence,omitempty" db:"type_confidence"`
+	TypeAlternatives []TypeScore            `json:"type_alternatives,omitempty" db:"type_alternatives"`
+	Suspicious       bool                   `json:"suspicious" db:"suspicious"`
+	InjectionSignals []string               `json:"injection_signals,omitempty" db:"injection_signals"`
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+}
+
+type AnalysisListResponse struct {
+	DocumentID string      `json:"document_id"`
+	Analyses   []*Analysis `json:"analyses"`
+}
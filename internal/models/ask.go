@@ -0,0 +1,53 @@
+package models
+
+// AskRequest is a question about a single document. TopK bounds how many
+// passages are sent to the model.
+type AskRequest struct {
+	Question string `json:"question"`
+	TopK     int    `json:"top_k,omitempty"`
+	// Redact is the PII redaction policy; empty uses the configured default
+	Redact string `json:"redact,omitempty"`
+}
+
+// Passage is a numbered excerpt of a document given to the model as context
+type Passage struct {
+	Number int
+	Text   string
+}
+
+// Quote is a span the model cites from a numbered passage
+type Quote struct {
+	Passage int
+	Text    string
+}
+
+// AnswerResult is what the analyzer returns for a question
+type AnswerResult struct {
+	Answer        string
+	Found         bool
+	Quotes        []Quote
+	Model         string
+	PromptVersion string
+	Usage         TokenUsage
+}
+
+// Citation is a supporting span of the document. Start and End are
+// character offsets into the document's extracted text.
+type Citation struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type AskResponse struct {
+	DocumentID    string         `json:"document_id"`
+	Question      string         `json:"question"`
+	Answer        string         `json:"answer"`
+	Found         bool           `json:"found"`
+	Citations     []Citation     `json:"citations"`
+	Model         string         `json:"model"`
+	PromptVersion string         `json:"prompt_version"`
+	Usage         TokenUsage     `json:"usage"`
+	CostUSD       *float64       `json:"cost_usd,omitempty"`
+	Redacted      map[string]int `json:"redacted,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+// CompareRequest names the two versions of a document to compare
+type CompareRequest struct {
+	OriginalID string `json:"original_id"`
+	RevisedID  string `json:"revised_id"`
+	// Redact is the PII redaction policy; empty uses the configured default
+	Redact string `json:"redact,omitempty"`
+}
+
+// DiffBlock is one paragraph of a comparison. Op is unchanged, added,
+// removed, changed or moved; OldIndex and NewIndex are the paragraph's
+// position in the original and revised text, absent when it is not there.
+type DiffBlock struct {
+	Op       string `json:"op"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	OldIndex *int   `json:"old_index,omitempty"`
+	NewIndex *int   `json:"new_index,omitempty"`
+}
+
+type DiffStats struct {
+	Unchanged int `json:"unchanged"`
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Moved     int `json:"moved"`
+}
+
+// MaterialChange is a difference that matters to a reader of the document.
+// Significance is high, medium or low.
+type MaterialChange struct {
+	Description  string `json:"description"`
+	Significance string `json:"significance"`
+}
+
+// ComparisonResult is what the analyzer returns for a comparison
+type ComparisonResult struct {
+	Summary       string
+	Changes       []MaterialChange
+	Model         string
+	PromptVersion string
+	Usage         TokenUsage
+}
+
+// CompareResponse is the diff of two documents with a summary of their
+// material differences. Model fields are absent when the texts are
+// identical, since nothing is sent to the model.
+type CompareResponse struct {
+	OriginalID    string           `json:"original_id"`
+	RevisedID     string           `json:"revised_id"`
+	Identical     bool             `json:"identical"`
+	Summary       string           `json:"summary"`
+	Changes       []MaterialChange `json:"changes"`
+	Stats         DiffStats        `json:"stats"`
+	Diff          []DiffBlock      `json:"diff"`
+	Model         string           `json:"model,omitempty"`
+	PromptVersion string           `json:"prompt_version,omitempty"`
+	Usage         *TokenUsage      `json:"usage,omitempty"`
+	CostUSD       *float64         `json:"cost_usd,omitempty"`
+	Redacted      map[string]int   `json:"redacted,omitempty"`
+}
@@ -1,28 +1,90 @@
 package models
 
 import (
+	"io"
 	"time"
 )
 
 type Document struct {
-	ID            string                 `json:"id" db:"id"`
-	Filename      string                 `json:"filename" db:"filename"`
-	FileSize      int64                  `json:"file_size" db:"file_size"`
-	ContentType   string                 `json:"content_type" db:"content_type"`
-	S3Key         string                 `json:"s3_key" db:"s3_key"`
-	ExtractedText string                 `json:"extracted_text,omitempty" db:"extracted_text"`
-	Summary       *string                `json:"summary,omitempty" db:"summary"`
-	DocumentType  *string                `json:"document_type,omitempty" db:"document_type"`
-	Metadata      map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
-	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at" db:"updated_at"`
-	AnalyzedAt    *time.Time             `json:"analyzed_at,omitempty" db:"analyzed_at"`
+	ID              string                 `json:"id" db:"id"`
+	Filename        string                 `json:"filename" db:"filename"`
+	FileSize        int64                  `json:"file_size" db:"file_size"`
+	ContentType     string                 `json:"content_type" db:"content_type"`
+	S3Key           string                 `json:"s3_key" db:"s3_key"`
+	ContentHash     string                 `json:"content_hash,omitempty" db:"content_hash"`
+	ExtractedText   string                 `json:"extracted_text,omitempty" db:"extracted_text"`
+	Language        *string                `json:"language,omitempty" db:"language"`
+	Summary         *string                `json:"summary,omitempty" db:"summary"`
+	DocumentType    *string                `json:"document_type,omitempty" db:"document_type"`
+	SummaryLanguage *string                `json:"summary_language,omitempty" db:"summary_language"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	// TypeConfidence is the classifier's confidence in DocumentType, and
+	// TypeAlternatives the runner-up types
+	TypeConfidence   *float64    `json:"type_confidence,omitempty" db:"type_confidence"`
+	TypeAlternatives []TypeScore `json:"type_alternatives,omitempty" db:"type_alternatives"`
+	// VerifiedFields are the fields a reviewer corrected or confirmed,
+	// which analyses no longer change; see CorrectionRequest
+	VerifiedFields []string   `json:"verified_fields,omitempty" db:"verified_fields"`
+	ReviewedBy     *string    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	// Revision counts the analyses and corrections stored on the document,
+	// so a correction can tell that an analysis finished in the meantime
+	Revision int64 `json:"-" db:"revision"`
+	// Summaries holds the latest summary in each style and length, keyed by
+	// SummaryKey; Summary is the most recent of them
+	Summaries  map[string]*Summary `json:"summaries,omitempty" db:"-"`
+	Structure  *DocumentStructure  `json:"-" db:"structure"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at" db:"updated_at"`
+	AnalyzedAt *time.Time          `json:"analyzed_at,omitempty" db:"analyzed_at"`
 }
 
+// UploadRequest carries an uploaded file that is read on demand, typically
+// from a temporary file, so uploads never have to fit in memory
 type UploadRequest struct {
-	File        []byte
+	File        io.ReaderAt
+	Size        int64
 	Filename    string
 	ContentType string
+
+	// Dedupe returns an existing document with identical content instead of
+	// storing the file again
+	Dedupe bool
+}
+
+type AnalyzeRequest struct {
+	// TargetLanguage is the ISO 639-1 code the summary should be written in.
+	// When empty the summary is written in the document's own language.
+	TargetLanguage string
+
+	// Force runs the analyzer even when a cached result exists
+	Force bool
+
+	// Redact is the PII redaction policy: all, none or a list of kinds.
+	// Empty uses the configured default.
+	Redact string
+
+	// Style and Length choose the summary variant; empty means standard
+	// and medium
+	Style  string
+	Length string
+}
+
+type DocumentFilter struct {
+	Language     string
+	DocumentType string
+	// NeedsReview keeps documents classified with a confidence below
+	// ReviewBelow
+	NeedsReview bool
+	ReviewBelow float64
+	Limit       int
+	Offset      int
+}
+
+type DocumentListResponse struct {
+	Documents []*Document `json:"documents"`
+	Limit     int         `json:"limit"`
+	Offset    int         `json:"offset"`
 }
 
 type UploadResponse struct {
@@ -30,20 +92,60 @@ type UploadResponse struct {
 	Filename    string    `json:"filename"`
 	FileSize    int64     `json:"file_size"`
 	ContentType string    `json:"content_type"`
+	Language    string    `json:"language,omitempty"`
+	Duplicate   bool      `json:"duplicate,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	Message     string    `json:"message"`
 }
 
 type AnalysisResponse struct {
-	ID           string                 `json:"id"`
-	Summary      string                 `json:"summary"`
-	DocumentType string                 `json:"document_type"`
-	Metadata     map[string]interface{} `json:"metadata"`
-	AnalyzedAt   time.Time              `json:"analyzed_at"`
+	ID              string                 `json:"id"`
+	Summary         string                 `json:"summary"`
+	SummaryLanguage string                 `json:"summary_language,omitempty"`
+	SummaryStyle    string                 `json:"summary_style"`
+	SummaryLength   string                 `json:"summary_length"`
+	DocumentType    string                 `json:"document_type"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	AnalysisID      string                 `json:"analysis_id,omitempty"`
+	Model           string                 `json:"model,omitempty"`
+	PromptVersion   string                 `json:"prompt_version,omitempty"`
+	Usage           *TokenUsage            `json:"usage,omitempty"`
+	CostUSD         *float64               `json:"cost_usd,omitempty"`
+	Redacted        map[string]int         `json:"redacted,omitempty"`
+	// TypeConfidence and TypeAlternatives come from the classifier;
+	// NeedsReview is set when the confidence is below the review threshold
+	TypeConfidence   *float64    `json:"type_confidence,omitempty"`
+	TypeAlternatives []TypeScore `json:"type_alternatives,omitempty"`
+	NeedsReview      bool        `json:"needs_review"`
+	// Suspicious is set when the document contains text that looks like
+	// instructions to the model; InjectionSignals names what was found
+	Suspicious       bool     `json:"suspicious,omitempty"`
+	InjectionSignals []string `json:"injection_signals,omitempty"`
+	// CacheHit is set when an analysis of identical text was reused
+	CacheHit   bool      `json:"cache_hit,omitempty"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
 }
 
 type LLMAnalysisResult struct {
 	Summary      string                 `json:"summary"`
 	DocumentType string                 `json:"document_type"`
 	Metadata     map[string]interface{} `json:"metadata"`
+
+	// Set by the analyzer, not parsed from the model output
+	Model         string     `json:"-"`
+	PromptVersion string     `json:"-"`
+	Usage         TokenUsage `json:"-"`
+	// Confidence is the classifier's confidence in DocumentType, from 0 to
+	// 1, and Alternatives the runner-up types, most likely first
+	Confidence   float64     `json:"-"`
+	Alternatives []TypeScore `json:"-"`
+	// CacheHit is set when the result comes from the analysis cache, so no
+	// tokens were spent on it
+	CacheHit bool `json:"-"`
+}
+
+// TypeScore is a document type with the classifier's confidence in it
+type TypeScore struct {
+	DocumentType string  `json:"document_type"`
+	Confidence   float64 `json:"confidence"`
 }
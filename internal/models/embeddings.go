@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Embedding is the vector of a chunk of a document's extracted text. Start
+// and End are byte offsets into the text.
+type Embedding struct {
+	ID         string
+	DocumentID string
+	ChunkIndex int
+	Start      int
+	End        int
+	Model      string
+	Vector     []float32
+	CreatedAt  time.Time
+}
+
+// MatchedPassage is the chunk of a document that best matched a query.
+// Start and End are character offsets into the extracted text.
+type MatchedPassage struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// SemanticMatch is a document found by similarity of meaning. Score is the
+// cosine similarity of its best matching chunk, from -1 to 1.
+type SemanticMatch struct {
+	DocumentID   string         `json:"document_id"`
+	Filename     string         `json:"filename"`
+	DocumentType *string        `json:"document_type,omitempty"`
+	Score        float64        `json:"score"`
+	Passage      MatchedPassage `json:"passage"`
+}
+
+type SemanticSearchResponse struct {
+	Query   string          `json:"query"`
+	Model   string          `json:"model"`
+	Results []SemanticMatch `json:"results"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+}
+
+type SimilarDocumentsResponse struct {
+	DocumentID string          `json:"document_id"`
+	Model      string          `json:"model"`
+	Documents  []SemanticMatch `json:"documents"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+}
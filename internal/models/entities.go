@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Entity types
+const (
+	EntityPerson       = "person"
+	EntityOrganization = "organization"
+	EntityLocation     = "location"
+	EntityDate         = "date"
+	EntityMoney        = "money"
+)
+
+// EntityTypes lists every entity type
+var EntityTypes = []string{EntityPerson, EntityOrganization, EntityLocation, EntityDate, EntityMoney}
+
+// EntityCandidate is an entity the analyzer found, as written in the text
+type EntityCandidate struct {
+	Type string
+	Text string
+}
+
+// EntityResult is what the analyzer returns for entity extraction
+type EntityResult struct {
+	Entities      []EntityCandidate
+	Model         string
+	PromptVersion string
+	Usage         TokenUsage
+}
+
+// Entity is one mention of a named entity in a document. Value is the
+// normalized form mentions are searched by, e.g. "acme" for "ACME Ltd." or
+// "2024-03-05" for "5 March 2024". Start and End are character offsets into
+// the document's extracted text.
+type Entity struct {
+	ID         string    `json:"id" db:"id"`
+	DocumentID string    `json:"document_id" db:"document_id"`
+	Type       string    `json:"type" db:"type"`
+	Value      string    `json:"value" db:"value"`
+	Text       string    `json:"text" db:"text"`
+	Start      int       `json:"start" db:"start_offset"`
+	End        int       `json:"end" db:"end_offset"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type EntityListResponse struct {
+	DocumentID string    `json:"document_id"`
+	Entities   []*Entity `json:"entities"`
+}
+
+// EntityFilter selects entities by type and normalized value; an empty
+// type matches every type
+type EntityFilter struct {
+	Type   string
+	Value  string
+	Limit  int
+	Offset int
+}
+
+// EntityDocument is a document mentioning a searched entity
+type EntityDocument struct {
+	DocumentID   string    `json:"document_id"`
+	Filename     string    `json:"filename"`
+	DocumentType *string   `json:"document_type,omitempty"`
+	Mentions     []*Entity `json:"mentions"`
+}
+
+type EntitySearchResponse struct {
+	Type      string            `json:"type,omitempty"`
+	Value     string            `json:"value"`
+	Documents []*EntityDocument `json:"documents"`
+	Limit     int               `json:"limit"`
+	Offset    int               `json:"offset"`
+}
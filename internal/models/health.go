@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ProviderStatus describes the circuit breaker of one LLM provider
+type ProviderStatus struct {
+	Provider            string     `json:"provider"`
+	Model               string     `json:"model"`
+	CircuitState        string     `json:"circuit_state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// HealthResponse reports "degraded" when any provider circuit is not closed
+type HealthResponse struct {
+	Status    string           `json:"status"`
+	Providers []ProviderStatus `json:"providers,omitempty"`
+}
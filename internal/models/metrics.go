@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// CacheEntry is a cached analysis result. Key is a hash of the text, model,
+// prompt version and options; Result is the encoded result.
+type CacheEntry struct {
+	Key           string
+	Model         string
+	PromptVersion string
+	Result        string
+	Hits          int64
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// CacheMetrics describes the analysis cache. Hits and misses count lookups
+// since the server started; entries and size are what is stored now.
+type CacheMetrics struct {
+	Enabled   bool    `json:"enabled"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hit_rate"`
+	Entries   int64   `json:"entries"`
+	SizeBytes int64   `json:"size_bytes"`
+}
+
+type MetricsResponse struct {
+	AnalysisCache CacheMetrics `json:"analysis_cache"`
+}
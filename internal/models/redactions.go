@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Redaction records what personal data was masked before a document's text
+// was sent to the analyzer. The masked values themselves are never stored.
+type Redaction struct {
+	ID         string `json:"id" db:"id"`
+	DocumentID string `json:"document_id" db:"document_id"`
+	// Operation is analyze, extract or ask
+	Operation string `json:"operation" db:"operation"`
+	// Policy lists the kinds that were masked, or "none"
+	Policy string `json:"policy" db:"policy"`
+	// Counts holds the number of distinct values masked per kind
+	Counts    map[string]int `json:"counts" db:"counts"`
+	Total     int            `json:"total" db:"total"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+type RedactionListResponse struct {
+	DocumentID string       `json:"document_id"`
+	Redactions []*Redaction `json:"redactions"`
+}
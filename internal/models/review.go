@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+const (
+	// FieldDocumentType names the document type in corrections and
+	// verified fields. Metadata fields are named MetadataField plus their
+	// key, e.g. metadata.vendor.
+	FieldDocumentType = "document_type"
+	MetadataField     = "metadata."
+)
+
+// Correction actions: a corrected field was given a new value, a verified
+// field was confirmed as it was
+const (
+	CorrectionCorrected = "corrected"
+	CorrectionVerified  = "verified"
+)
+
+// Reasons a document is in the review queue
+const (
+	ReviewUnreviewed    = "unreviewed"
+	ReviewLowConfidence = "low_confidence"
+)
+
+// CorrectionRequest is a reviewer's correction of a document's analysis.
+// Every field it sets or lists in Verify becomes human-verified, so later
+// analyses keep its value.
+type CorrectionRequest struct {
+	Reviewer     string  `json:"reviewer"`
+	DocumentType *string `json:"document_type,omitempty"`
+	// Metadata sets metadata fields by key; null removes a field
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Verify names fields that are correct as they are, e.g.
+	// ["document_type", "metadata.amount"]
+	Verify []string `json:"verify,omitempty"`
+	Note   string   `json:"note,omitempty"`
+}
+
+// Correction is an entry in the audit trail of a document's analysis.
+// OldValue and NewValue are equal when a field was only verified, and
+// absent when the field did not exist or was removed.
+type Correction struct {
+	ID         string      `json:"id" db:"id"`
+	DocumentID string      `json:"document_id" db:"document_id"`
+	Field      string      `json:"field" db:"field"`
+	Action     string      `json:"action" db:"action"`
+	OldValue   interface{} `json:"old_value" db:"old_value"`
+	NewValue   interface{} `json:"new_value" db:"new_value"`
+	Reviewer   string      `json:"reviewer" db:"reviewer"`
+	Note       string      `json:"note,omitempty" db:"note"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CorrectionResponse is a document's analysis after a correction, with the
+// audit entries the correction added
+type CorrectionResponse struct {
+	ID             string                 `json:"id"`
+	DocumentType   string                 `json:"document_type"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	VerifiedFields []string               `json:"verified_fields"`
+	TypeConfidence *float64               `json:"type_confidence,omitempty"`
+	NeedsReview    bool                   `json:"needs_review"`
+	ReviewedBy     string                 `json:"reviewed_by"`
+	ReviewedAt     time.Time              `json:"reviewed_at"`
+	Corrections    []*Correction          `json:"corrections"`
+}
+
+type CorrectionListResponse struct {
+	DocumentID  string        `json:"document_id"`
+	Corrections []*Correction `json:"corrections"`
+}
+
+// ReviewFilter selects analyzed documents nobody has reviewed, and those
+// classified with a confidence below ReviewBelow whose type nobody has
+// verified
+type ReviewFilter struct {
+	ReviewBelow float64
+	Limit       int
+	Offset      int
+}
+
+// ReviewItem is a document waiting for review. Reasons says why: it is
+// unreviewed, its classification has low confidence, or both.
+type ReviewItem struct {
+	ID             string     `json:"id"`
+	Filename       string     `json:"filename"`
+	DocumentType   string     `json:"document_type"`
+	TypeConfidence *float64   `json:"type_confidence,omitempty"`
+	VerifiedFields []string   `json:"verified_fields,omitempty"`
+	Reasons        []string   `json:"reasons"`
+	AnalyzedAt     time.Time  `json:"analyzed_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type ReviewQueueResponse struct {
+	Documents []*ReviewItem `json:"documents"`
+	Limit     int           `json:"limit"`
+	Offset    int           `json:"offset"`
+}
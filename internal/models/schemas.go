@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// FieldType is the type of a field in a user-defined extraction schema
+type FieldType string
+
+const (
+	FieldString   FieldType = "string"
+	FieldNumber   FieldType = "number"
+	FieldInteger  FieldType = "integer"
+	FieldBoolean  FieldType = "boolean"
+	FieldDate     FieldType = "date"
+	FieldAmount   FieldType = "amount"
+	FieldCurrency FieldType = "currency"
+	FieldList     FieldType = "list"
+)
+
+// FieldTypes lists every supported field type
+var FieldTypes = []FieldType{
+	FieldString, FieldNumber, FieldInteger, FieldBoolean,
+	FieldDate, FieldAmount, FieldCurrency, FieldList,
+}
+
+type SchemaField struct {
+	Name        string    `json:"name"`
+	Type        FieldType `json:"type"`
+	Description string    `json:"description,omitempty"`
+	Required    bool      `json:"required,omitempty"`
+}
+
+// ExtractionSchema is a named set of fields teams define to pull their own
+// data out of documents
+type ExtractionSchema struct {
+	ID          string        `json:"id" db:"id"`
+	Name        string        `json:"name" db:"name"`
+	Description string        `json:"description,omitempty" db:"description"`
+	Fields      []SchemaField `json:"fields" db:"fields"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+type CreateSchemaRequest struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Fields      []SchemaField `json:"fields"`
+}
+
+type SchemaListResponse struct {
+	Schemas []*ExtractionSchema `json:"schemas"`
+}
+
+// ExtractionResult is what the analyzer returns for a schema extraction
+type ExtractionResult struct {
+	Data          map[string]interface{}
+	Model         string
+	PromptVersion string
+	Usage         TokenUsage
+}
+
+// Extraction is a stored schema extraction for a document. Data holds one
+// typed value per schema field.
+type Extraction struct {
+	ID               string                 `json:"id" db:"id"`
+	DocumentID       string                 `json:"document_id" db:"document_id"`
+	SchemaID         string                 `json:"schema_id" db:"schema_id"`
+	SchemaName       string                 `json:"schema" db:"schema_name"`
+	Data             map[string]interface{} `json:"data" db:"data"`
+	Model            string                 `json:"model" db:"model"`
+	PromptVersion    string                 `json:"prompt_version" db:"prompt_version"`
+	LatencyMS        int64                  `json:"latency_ms" db:"latency_ms"`
+	PromptTokens     int64                  `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int64                  `json:"completion_tokens" db:"completion_tokens"`
+	CostUSD          *float64               `json:"cost_usd,omitempty" db:"cost_usd"`
+	Redacted         map[string]int         `json:"redacted,omitempty" db:"-"`
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+}
+
+type ExtractionListResponse struct {
+	DocumentID  string        `json:"document_id"`
+	Extractions []*Extraction `json:"extractions"`
+}
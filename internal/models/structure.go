@@ -0,0 +1,37 @@
+package models
+
+// BlockType identifies the kind of content a Block holds
+type BlockType string
+
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockListItem  BlockType = "list_item"
+	BlockTable     BlockType = "table"
+)
+
+// Block is a single structural element of a document. Level is the heading
+// or list nesting level (1-based), Page is the 1-based page number when the
+// source format has pages, and Cells holds the rows of a table block.
+type Block struct {
+	Type  BlockType  `json:"type"`
+	Level int        `json:"level,omitempty"`
+	Page  int        `json:"page,omitempty"`
+	Text  string     `json:"text"`
+	Cells [][]string `json:"cells,omitempty"`
+}
+
+// DocumentStructure is the structured intermediate representation produced
+// by the extractors alongside the plain text. Error records why extraction
+// failed, so a failed document is not extracted again on every request.
+type DocumentStructure struct {
+	PageCount int     `json:"page_count,omitempty"`
+	Blocks    []Block `json:"blocks"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type StructureResponse struct {
+	ID        string  `json:"id"`
+	PageCount int     `json:"page_count,omitempty"`
+	Blocks    []Block `json:"blocks"`
+}
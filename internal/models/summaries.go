@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Summary styles
+const (
+	// SummaryStandard is a short paragraph, the summary analysis has always
+	// written
+	SummaryStandard  = "standard"
+	SummaryHeadline  = "headline"
+	SummaryExecutive = "executive"
+	SummaryBullets   = "bullets"
+	SummaryDetailed  = "detailed"
+)
+
+// Summary lengths
+const (
+	SummaryShort  = "short"
+	SummaryMedium = "medium"
+	SummaryLong   = "long"
+)
+
+// SummaryStyles and SummaryLengths list every style and length
+var (
+	SummaryStyles  = []string{SummaryStandard, SummaryHeadline, SummaryExecutive, SummaryBullets, SummaryDetailed}
+	SummaryLengths = []string{SummaryShort, SummaryMedium, SummaryLong}
+)
+
+// SummaryKey names a style and length variant in the summaries of a
+// document, e.g. executive:long
+func SummaryKey(style, length string) string {
+	return style + ":" + length
+}
+
+// Summary is the latest summary of a document in one style and length
+type Summary struct {
+	ID              string    `json:"-" db:"id"`
+	DocumentID      string    `json:"-" db:"document_id"`
+	Style           string    `json:"style" db:"style"`
+	Length          string    `json:"length" db:"length"`
+	Summary         string    `json:"summary" db:"summary"`
+	SummaryLanguage string    `json:"summary_language,omitempty" db:"summary_language"`
+	AnalysisID      string    `json:"analysis_id,omitempty" db:"analysis_id"`
+	Model           string    `json:"model,omitempty" db:"model"`
+	PromptVersion   string    `json:"prompt_version,omitempty" db:"prompt_version"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
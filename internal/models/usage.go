@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// TokenUsage counts the tokens billed for one or more LLM calls
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Add accumulates other into u
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// LLM call operations recorded with LLMCall
+const (
+	OperationAnalyze = "analyze"
+	OperationAsk     = "ask"
+	OperationCompare = "compare"
+)
+
+// LLMCall is the usage of a question or comparison, or of a failed
+// analysis, which is billed but leaves no analysis behind. A comparison is
+// recorded on the original document with the revision as the related one.
+type LLMCall struct {
+	ID                string
+	DocumentID        string
+	RelatedDocumentID string
+	Operation         string
+	Model             string
+	PromptVersion     string
+	PromptTokens      int64
+	CompletionTokens  int64
+	CostUSD           *float64
+	Failed            bool
+	CreatedAt         time.Time
+}
+
+// UsageFilter bounds a usage report to an inclusive range of days
+// (YYYY-MM-DD); empty bounds are open
+type UsageFilter struct {
+	From string
+	To   string
+}
+
+// UsageBucket is the usage of one model on one day
+type UsageBucket struct {
+	Day              string
+	Model            string
+	Analyses         int64
+	Extractions      int64
+	Translations     int64
+	Questions        int64
+	Comparisons      int64
+	FailedCalls      int64
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+}
+
+// UsageTotals aggregates a set of analyses, schema extractions,
+// translations, questions and comparisons, and the failed calls that were
+// billed. Cost only covers models with a configured price.
+type UsageTotals struct {
+	Analyses         int64   `json:"analyses"`
+	Extractions      int64   `json:"extractions"`
+	Translations     int64   `json:"translations"`
+	Questions        int64   `json:"questions"`
+	Comparisons      int64   `json:"comparisons"`
+	FailedCalls      int64   `json:"failed_calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// AddBucket accumulates bucket into t
+func (t *UsageTotals) AddBucket(bucket *UsageBucket) {
+	t.Analyses += bucket.Analyses
+	t.Extractions += bucket.Extractions
+	t.Translations += bucket.Translations
+	t.Questions += bucket.Questions
+	t.Comparisons += bucket.Comparisons
+	t.FailedCalls += bucket.FailedCalls
+	t.PromptTokens += bucket.PromptTokens
+	t.CompletionTokens += bucket.CompletionTokens
+	t.TotalTokens += bucket.PromptTokens + bucket.CompletionTokens
+	t.CostUSD += bucket.CostUSD
+}
+
+type DailyUsage struct {
+	Date string `json:"date"`
+	UsageTotals
+}
+
+type ModelUsage struct {
+	Model string `json:"model"`
+	UsageTotals
+}
+
+type UsageResponse struct {
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Total   UsageTotals  `json:"total"`
+	ByDay   []DailyUsage `json:"by_day"`
+	ByModel []ModelUsage `json:"by_model"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// TextVersionTranslation is the kind of text version a translation is
+const TextVersionTranslation = "translation"
+
+// Download formats of text versions
+const (
+	FormatTXT  = "txt"
+	FormatDOCX = "docx"
+)
+
+// TranslateRequest asks for the extracted text of a document in another
+// language
+type TranslateRequest struct {
+	// TargetLanguage is the ISO 639-1 code to translate into
+	TargetLanguage string
+
+	// Force translates again even when a translation exists
+	Force bool
+
+	// Redact is the PII redaction policy; empty uses the configured default
+	Redact string
+}
+
+// TranslationResult is what the analyzer returns for a batch of
+// paragraphs, one translation per paragraph in the same order
+type TranslationResult struct {
+	Paragraphs    []string
+	Model         string
+	PromptVersion string
+	Usage         TokenUsage
+}
+
+// TextVersion is a text derived from a document's extracted text, such as
+// a translation. Paragraphs are separated by blank lines. Model lists every
+// model that contributed when a fallback took over part way.
+type TextVersion struct {
+	ID               string    `json:"id" db:"id"`
+	DocumentID       string    `json:"document_id" db:"document_id"`
+	Kind             string    `json:"kind" db:"kind"`
+	Language         string    `json:"language" db:"language"`
+	SourceLanguage   string    `json:"source_language,omitempty" db:"source_language"`
+	Text             string    `json:"text,omitempty" db:"text"`
+	Model            string    `json:"model,omitempty" db:"model"`
+	PromptVersion    string    `json:"prompt_version,omitempty" db:"prompt_version"`
+	PromptTokens     int64     `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens" db:"completion_tokens"`
+	CostUSD          *float64  `json:"cost_usd,omitempty" db:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+type TranslateResponse struct {
+	*TextVersion
+	// Cached is set when an existing translation was returned
+	Cached bool `json:"cached"`
+	// Downloads maps each download format to its URL
+	Downloads map[string]string `json:"downloads"`
+	Redacted  map[string]int    `json:"redacted,omitempty"`
+}
+
+type TextVersionListResponse struct {
+	DocumentID string         `json:"document_id"`
+	Versions   []*TextVersion `json:"versions"`
+}
+
+// FileDownload is a rendered file ready to be sent to the client
+type FileDownload struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
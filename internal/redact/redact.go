@@ -0,0 +1,306 @@
+package redact
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kind is a category of personal data
+type Kind string
+
+const (
+	Email      Kind = "email"
+	Phone      Kind = "phone"
+	NationalID Kind = "national_id"
+	IBAN       Kind = "iban"
+	Card       Kind = "card"
+)
+
+// Kinds lists every kind in the order they are matched. Longer, checksummed
+// numbers go before phone numbers so a card is not masked as a phone.
+var Kinds = []Kind{Email, IBAN, Card, NationalID, Phone}
+
+// Policy is the set of kinds to mask
+type Policy map[Kind]bool
+
+// All masks every kind
+func All() Policy {
+	p := Policy{}
+	for _, kind := range Kinds {
+		p[kind] = true
+	}
+	return p
+}
+
+// ParsePolicy reads "all", "none" or a comma separated list of kinds
+func ParsePolicy(value string) (Policy, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "all":
+		return All(), nil
+	case "none", "off", "":
+		return Policy{}, nil
+	}
+
+	known := All()
+	p := Policy{}
+	for _, name := range strings.Split(value, ",") {
+		kind := Kind(strings.TrimSpace(name))
+		if !known[kind] {
+			return nil, fmt.Errorf("unknown PII kind %q; use all, none or a list of email, phone, national_id, iban, card", name)
+		}
+		p[kind] = true
+	}
+	return p, nil
+}
+
+// String lists the kinds of the policy in match order, or "none"
+func (p Policy) String() string {
+	var names []string
+	for _, kind := range Kinds {
+		if p[kind] {
+			names = append(names, string(kind))
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	ibanPattern  = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+	// nationalIDPatterns match US social security numbers, UK national
+	// insurance numbers and numbers labelled as national or passport IDs
+	nationalIDPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		regexp.MustCompile(`(?i)(?:national id|id (?:no\.?|number)|identity (?:card )?(?:no\.?|number)|passport (?:no\.?|number))\s*[:#]?\s*([A-Z0-9]{6,12})\b`),
+	}
+	phonePattern = regexp.MustCompile(`(?:\+|\b)\d[\d \-().]{7,}\d\b`)
+)
+
+// minPhoneDigits and maxPhoneDigits bound the digits of a phone number, so years, amounts and
+// dates are not taken for one
+const (
+	minPhoneDigits = 9
+	maxPhoneDigits = 15
+)
+
+// Match is a masked value
+type Match struct {
+	Kind        Kind
+	Placeholder string
+	Value       string
+}
+
+// Redactor masks personal data with placeholders such as [EMAIL_1] and
+// puts the values back afterwards. A value gets the same placeholder every
+// time it occurs, across every text given to the same Redactor, so the
+// model can still tell that two mentions are the same person.
+type Redactor struct {
+	policy       Policy
+	placeholders map[Kind]map[string]string
+	matches      []Match
+}
+
+func New(policy Policy) *Redactor {
+	return &Redactor{
+		policy:       policy,
+		placeholders: map[Kind]map[string]string{},
+	}
+}
+
+// Matches returns every value masked so far
+func (r *Redactor) Matches() []Match {
+	return r.matches
+}
+
+// Counts returns how many distinct values of each kind were masked
+func (r *Redactor) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, m := range r.matches {
+		counts[string(m.Kind)]++
+	}
+	return counts
+}
+
+// Redact replaces the values of the policy's kinds in text
+func (r *Redactor) Redact(text string) string {
+	for _, kind := range Kinds {
+		if r.policy[kind] {
+			text = r.mask(kind, text)
+		}
+	}
+	return text
+}
+
+func (r *Redactor) mask(kind Kind, text string) string {
+	if r.placeholders[kind] == nil {
+		r.placeholders[kind] = map[string]string{}
+	}
+	placeholders := r.placeholders[kind]
+
+	replace := func(value string) string {
+		if !valid(kind, value) {
+			return value
+		}
+		key := normalize(kind, value)
+		placeholder, ok := placeholders[key]
+		if !ok {
+			placeholder = fmt.Sprintf("[%s_%d]", strings.ToUpper(string(kind)), len(placeholders)+1)
+			placeholders[key] = placeholder
+			r.matches = append(r.matches, Match{Kind: kind, Placeholder: placeholder, Value: value})
+		}
+		return placeholder
+	}
+
+	switch kind {
+	case Email:
+		return emailPattern.ReplaceAllStringFunc(text, replace)
+	case IBAN:
+		return ibanPattern.ReplaceAllStringFunc(text, replace)
+	case Card:
+		return cardPattern.ReplaceAllStringFunc(text, replace)
+	case Phone:
+		return phonePattern.ReplaceAllStringFunc(text, replace)
+	case NationalID:
+		for _, pattern := range nationalIDPatterns {
+			text = replaceGroup(pattern, text, replace)
+		}
+	}
+	return text
+}
+
+// replaceGroup is ReplaceAllStringFunc for the first capture group when
+// the pattern has one, so labels such as "ID No:" stay in the text
+func replaceGroup(pattern *regexp.Regexp, text string, replace func(string) string) string {
+	if pattern.NumSubexp() == 0 {
+		return pattern.ReplaceAllStringFunc(text, replace)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:m[2]])
+		b.WriteString(replace(text[m[2]:m[3]]))
+		last = m[3]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func valid(kind Kind, value string) bool {
+	switch kind {
+	case Card:
+		return luhn(digitsOf(value))
+	case IBAN:
+		return ibanChecksum(strings.ReplaceAll(value, " ", ""))
+	case Phone:
+		n := len(digitsOf(value))
+		return n >= minPhoneDigits && n <= maxPhoneDigits
+	case NationalID:
+		// Labelled IDs must contain a digit, so "ID number: pending" is kept
+		return digitsOf(value) != ""
+	}
+	return true
+}
+
+func normalize(kind Kind, value string) string {
+	switch kind {
+	case Email:
+		return strings.ToLower(value)
+	case Phone, Card:
+		return digitsOf(value)
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(value), ""))
+}
+
+func digitsOf(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// luhn checks the Luhn checksum used by payment card numbers
+func luhn(digits string) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanChecksum checks the ISO 13616 mod 97 checksum of an IBAN
+func ibanChecksum(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			numeric.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			fmt.Fprint(&numeric, int(r-'A')+10)
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// Restore puts the original values back in place of placeholders
+func (r *Redactor) Restore(s string) string {
+	if len(r.matches) == 0 {
+		return s
+	}
+
+	pairs := make([]string, 0, 2*len(r.matches))
+	for _, m := range r.matches {
+		pairs = append(pairs, m.Placeholder, m.Value)
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+// RestoreValue restores placeholders in every string of a decoded JSON value
+func (r *Redactor) RestoreValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Restore(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = r.RestoreValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.RestoreValue(item)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `Jane Doe, jane.doe@example.com, +254 712 345 678
+Card: 4111 1111 1111 1111 (expires 09/27), IBAN GB82 WEST 1234 5698 7654 32.
+SSN 123-45-6789, National ID: 12345678.
+Contact Jane.Doe@example.com or call (0712) 345-678 again.
+Invoice total 1,392.00 due 2024-04-04, ref 4111 1111 1111 1112.`
+
+func TestRedactMasksEachKind(t *testing.T) {
+	r := New(All())
+	text := r.Redact(sample)
+
+	for _, value := range []string{
+		"jane.doe@example.com", "Jane.Doe@example.com", "+254 712 345 678",
+		"4111 1111 1111 1111", "GB82 WEST 1234 5698 7654 32", "123-45-6789", "12345678",
+	} {
+		if strings.Contains(text, value) {
+			t.Errorf("%q was not masked:\n%s", value, text)
+		}
+	}
+
+	// Amounts, dates and numbers failing the Luhn check are not PII
+	for _, kept := range []string{"1,392.00", "2024-04-04", "4111 1111 1111 1112", "National ID: "} {
+		if !strings.Contains(text, kept) {
+			t.Errorf("%q was masked:\n%s", kept, text)
+		}
+	}
+
+	want := map[string]int{"email": 1, "phone": 2, "card": 1, "iban": 1, "national_id": 2}
+	counts := r.Counts()
+	for kind, n := range want {
+		if counts[kind] != n {
+			t.Errorf("counts[%s] = %d, want %d (%v)", kind, counts[kind], n, counts)
+		}
+	}
+
+	// The same address in another case keeps its placeholder
+	if strings.Count(text, "[EMAIL_1]") != 2 {
+		t.Errorf("email placeholder used %d times, want 2:\n%s", strings.Count(text, "[EMAIL_1]"), text)
+	}
+
+	// Later texts reuse the placeholders of earlier ones
+	if got := r.Redact("Write to JANE.DOE@example.com"); got != "Write to [EMAIL_1]" {
+		t.Errorf("second text = %q, want the same placeholder", got)
+	}
+}
+
+func TestRedactFollowsPolicy(t *testing.T) {
+	policy, err := ParsePolicy("email, card")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := New(policy).Redact(sample)
+	if !strings.Contains(text, "+254 712 345 678") || !strings.Contains(text, "123-45-6789") {
+		t.Errorf("kinds outside the policy were masked:\n%s", text)
+	}
+	if strings.Contains(text, "jane.doe@example.com") || strings.Contains(text, "4111 1111 1111 1111") {
+		t.Errorf("kinds in the policy were not masked:\n%s", text)
+	}
+
+	if r := New(Policy{}); r.Redact(sample) != sample || len(r.Matches()) != 0 {
+		t.Error("empty policy changed the text")
+	}
+	if _, err := ParsePolicy("email,passport"); err == nil {
+		t.Error("ParsePolicy accepted an unknown kind")
+	}
+	if got := policy.String(); got != "email,card" {
+		t.Errorf("String() = %q, want email,card", got)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	r := New(All())
+
+	if got := r.Restore(r.Redact(sample)); got != strings.Replace(sample, "Jane.Doe@example.com", "jane.doe@example.com", 1) {
+		t.Errorf("Restore did not round trip:\n%s", got)
+	}
+
+	metadata := map[string]interface{}{
+		"sender": "Jane Doe <[EMAIL_1]>",
+		"phones": []interface{}{"[PHONE_1]", nil},
+		"amount": "1392.00",
+	}
+	r.RestoreValue(metadata)
+	if metadata["sender"] != "Jane Doe <jane.doe@example.com>" {
+		t.Errorf("sender = %v", metadata["sender"])
+	}
+	if phones := metadata["phones"].([]interface{}); phones[0] != "+254 712 345 678" || phones[1] != nil {
+		t.Errorf("phones = %v", phones)
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	if !luhn("4111111111111111") || luhn("4111111111111112") {
+		t.Error("luhn check is wrong")
+	}
+	if !ibanChecksum("GB82WEST12345698765432") || ibanChecksum("GB82WEST12345698765433") {
+		t.Error("IBAN check is wrong")
+	}
+}
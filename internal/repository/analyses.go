@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func (r *repository) CreateAnalysis(ctx context.Context, analysis *models.Analysis) error {
+	metadataJSON, err := json.Marshal(analysis.Metadata)
+	if err != nil {
+		return err
+	}
+
+	alternativesJSON, err := marshalTypeScores(analysis.TypeAlternatives)
+	if err != nil {
+		return err
+	}
+
+	var signalsJSON []byte
+	if len(analysis.InjectionSignals) > 0 {
+		if signalsJSON, err = json.Marshal(analysis.InjectionSignals); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO analyses (id, document_id, model, prompt_version, summary, summary_language,
+		                      summary_style, summary_length, document_type, metadata, latency_ms,
+		                      prompt_tokens, completion_tokens, cost_usd, suspicious, injection_signals,
+		                      type_confidence, type_alternatives, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		analysis.ID,
+		analysis.DocumentID,
+		analysis.Model,
+		analysis.PromptVersion,
+		analysis.Summary,
+		nullIfEmpty(analysis.SummaryLanguage),
+		nullIfEmpty(analysis.SummaryStyle),
+		nullIfEmpty(analysis.SummaryLength),
+		analysis.DocumentType,
+		metadataJSON,
+		analysis.LatencyMS,
+		analysis.PromptTokens,
+		analysis.CompletionTokens,
+		analysis.CostUSD,
+		analysis.Suspicious,
+		signalsJSON,
+		analysis.TypeConfidence,
+		alternativesJSON,
+		analysis.CreatedAt,
+	)
+
+	return err
+}
+
+// ListAnalyses returns every analysis run for a document, newest first
+func (r *repository) ListAnalyses(ctx context.Context, documentID string) ([]*models.Analysis, error) {
+	query := `
+		SELECT id, document_id, model, prompt_version, summary, summary_language,
+		       summary_style, summary_length, document_type, metadata, latency_ms, prompt_tokens, completion_tokens,
+		       cost_usd, suspicious, injection_signals, type_confidence, type_alternatives, created_at
+		FROM analyses
+		WHERE document_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	analyses := []*models.Analysis{}
+	for rows.Next() {
+		var analysis models.Analysis
+		var summary, summaryLanguage, summaryStyle, summaryLength, docType, metadataJSON, signalsJSON, alternativesJSON sql.NullString
+		var cost sql.NullFloat64
+
+		if err := rows.Scan(
+			&analysis.ID,
+			&analysis.DocumentID,
+			&analysis.Model,
+			&analysis.PromptVersion,
+			&summary,
+			&summaryLanguage,
+			&summaryStyle,
+			&summaryLength,
+			&docType,
+			&metadataJSON,
+			&analysis.LatencyMS,
+			&analysis.PromptTokens,
+			&analysis.CompletionTokens,
+			&cost,
+			&analysis.Suspicious,
+			&signalsJSON,
+			&analysis.TypeConfidence,
+			&alternativesJSON,
+			&analysis.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		analysis.Summary = summary.String
+		analysis.SummaryLanguage = summaryLanguage.String
+		analysis.SummaryStyle = summaryStyle.String
+		analysis.SummaryLength = summaryLength.String
+		analysis.DocumentType = docType.String
+		if cost.Valid {
+			analysis.CostUSD = &cost.Float64
+		}
+
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			if err := json.Unmarshal([]byte(metadataJSON.String), &analysis.Metadata); err != nil {
+				return nil, err
+			}
+		}
+
+		if signalsJSON.Valid && signalsJSON.String != "" {
+			if err := json.Unmarshal([]byte(signalsJSON.String), &analysis.InjectionSignals); err != nil {
+				return nil, err
+			}
+		}
+
+		if analysis.TypeAlternatives, err = unmarshalTypeScores(alternativesJSON); err != nil {
+			return nil, err
+		}
+
+		analyses = append(analyses, &analysis)
+	}
+
+	return analyses, rows.Err()
+}
+
+// AggregateUsage sums tokens and cost of analyses, extractions,
+// translations, questions and comparisons per day and model, oldest day
+// first. Days come from the
+// stored timestamp, which starts YYYY-MM-DD.
+func (r *repository) AggregateUsage(ctx context.Context, filter models.UsageFilter) ([]*models.UsageBucket, error) {
+	query := `
+		SELECT substr(created_at, 1, 10) AS day, model,
+		       SUM(kind = 'analysis'), SUM(kind = 'extraction'), SUM(kind = 'translation'),
+		       SUM(kind = 'ask'), SUM(kind = 'compare'), SUM(failed),
+		       SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd)
+		FROM (
+			SELECT 'analysis' AS kind, 0 AS failed, created_at, model, prompt_tokens, completion_tokens, cost_usd
+			FROM analyses
+			UNION ALL
+			SELECT 'extraction', 0, created_at, model, prompt_tokens, completion_tokens, cost_usd
+			FROM extractions
+			UNION ALL
+			SELECT 'translation', 0, created_at, model, prompt_tokens, completion_tokens, cost_usd
+			FROM text_versions
+			WHERE kind = 'translation'
+			UNION ALL
+			SELECT operation, failed, created_at, model, prompt_tokens, completion_tokens, cost_usd
+			FROM llm_calls
+		)
+		WHERE ($1 = '' OR substr(created_at, 1, 10) >= $1)
+		  AND ($2 = '' OR substr(created_at, 1, 10) <= $2)
+		GROUP BY day, model
+		ORDER BY day, model
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []*models.UsageBucket{}
+	for rows.Next() {
+		var bucket models.UsageBucket
+		var cost sql.NullFloat64
+
+		if err := rows.Scan(
+			&bucket.Day,
+			&bucket.Model,
+			&bucket.Analyses,
+			&bucket.Extractions,
+			&bucket.Translations,
+			&bucket.Questions,
+			&bucket.Comparisons,
+			&bucket.FailedCalls,
+			&bucket.PromptTokens,
+			&bucket.CompletionTokens,
+			&cost,
+		); err != nil {
+			return nil, err
+		}
+
+		bucket.CostUSD = cost.Float64
+		buckets = append(buckets, &bucket)
+	}
+
+	return buckets, rows.Err()
+}
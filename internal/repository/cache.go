@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// GetCachedAnalysis returns the unexpired cache entry with key, or nil. A
+// hit counts towards the entry's hits and keeps it from being evicted.
+func (r *repository) GetCachedAnalysis(ctx context.Context, key string, now time.Time) (*models.CacheEntry, error) {
+	query := `
+		UPDATE analysis_cache SET hits = hits + 1, last_used_at = $2
+		WHERE key = $1 AND expires_at > $2
+		RETURNING key, model, prompt_version, result, hits, created_at, expires_at
+	`
+
+	var entry models.CacheEntry
+	err := r.db.QueryRowContext(ctx, query, key, now).Scan(
+		&entry.Key,
+		&entry.Model,
+		&entry.PromptVersion,
+		&entry.Result,
+		&entry.Hits,
+		&entry.CreatedAt,
+		&entry.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// PutCachedAnalysis stores a cache entry, then drops expired entries and
+// the least recently used ones beyond maxEntries or maxBytes of results
+func (r *repository) PutCachedAnalysis(ctx context.Context, entry *models.CacheEntry, maxEntries int, maxBytes int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO analysis_cache (key, model, prompt_version, result, size_bytes, hits, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $6, $7)
+		ON CONFLICT (key) DO UPDATE SET
+			result = excluded.result,
+			size_bytes = excluded.size_bytes,
+			hits = 0,
+			created_at = excluded.created_at,
+			last_used_at = excluded.last_used_at,
+			expires_at = excluded.expires_at
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		entry.Key,
+		entry.Model,
+		entry.PromptVersion,
+		entry.Result,
+		len(entry.Result),
+		entry.CreatedAt,
+		entry.ExpiresAt,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM analysis_cache WHERE expires_at <= $1`, entry.CreatedAt); err != nil {
+		return err
+	}
+
+	evict := `
+		DELETE FROM analysis_cache WHERE key IN (
+			SELECT key FROM analysis_cache
+			ORDER BY last_used_at DESC
+			LIMIT -1 OFFSET $1
+		)
+	`
+	if _, err := tx.ExecContext(ctx, evict, maxEntries); err != nil {
+		return err
+	}
+
+	// Keep the most recently used entries whose results fit in maxBytes
+	evictBytes := `
+		DELETE FROM analysis_cache WHERE key IN (
+			SELECT key FROM (
+				SELECT key, SUM(size_bytes) OVER (ORDER BY last_used_at DESC, key) AS total
+				FROM analysis_cache
+			)
+			WHERE total > $1
+		)
+	`
+	if _, err := tx.ExecContext(ctx, evictBytes, maxBytes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AnalysisCacheSize returns how many entries the analysis cache holds and
+// the size of their results in bytes
+func (r *repository) AnalysisCacheSize(ctx context.Context) (int64, int64, error) {
+	var entries, size int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(size_bytes), 0) FROM analysis_cache`).Scan(&entries, &size)
+	return entries, size, err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/db"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// newTestRepository migrates a fresh SQLite database. Migrations are
+// found relative to the module root.
+func newTestRepository(t *testing.T) Repository {
+	t.Helper()
+	t.Chdir("../..")
+
+	path := t.TempDir() + "/test.db"
+	if err := db.RunMigrations(path); err != nil {
+		t.Fatalf("RunMigrations returned error: %v", err)
+	}
+	conn, err := db.NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB returned error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewRepository(conn)
+}
+
+func putEntry(t *testing.T, r Repository, key string, size int, at time.Time, maxEntries int, maxBytes int64) {
+	t.Helper()
+	entry := &models.CacheEntry{
+		Key:           key,
+		Model:         "test/model",
+		PromptVersion: "classify@1",
+		Result:        strings.Repeat("x", size),
+		CreatedAt:     at,
+		ExpiresAt:     at.Add(time.Hour),
+	}
+	if err := r.PutCachedAnalysis(context.Background(), entry, maxEntries, maxBytes); err != nil {
+		t.Fatalf("PutCachedAnalysis(%s) returned error: %v", key, err)
+	}
+}
+
+func cachedKeys(t *testing.T, r Repository, keys []string, now time.Time) []string {
+	t.Helper()
+	var found []string
+	for _, key := range keys {
+		entry, err := r.GetCachedAnalysis(context.Background(), key, now)
+		if err != nil {
+			t.Fatalf("GetCachedAnalysis(%s) returned error: %v", key, err)
+		}
+		if entry != nil {
+			found = append(found, key)
+		}
+	}
+	return found
+}
+
+func TestAnalysisCacheEvictsLeastRecentlyUsedBeyondMaxEntries(t *testing.T) {
+	r := newTestRepository(t)
+	now := time.Now()
+
+	putEntry(t, r, "a", 10, now, 2, 1000)
+	putEntry(t, r, "b", 10, now.Add(time.Second), 2, 1000)
+	// Reading a makes b the least recently used
+	if entry, _ := r.GetCachedAnalysis(context.Background(), "a", now.Add(2*time.Second)); entry == nil || entry.Hits != 1 {
+		t.Fatalf("entry a = %+v, want one hit", entry)
+	}
+	putEntry(t, r, "c", 10, now.Add(3*time.Second), 2, 1000)
+
+	got := cachedKeys(t, r, []string{"a", "b", "c"}, now.Add(4*time.Second))
+	if strings.Join(got, ",") != "a,c" {
+		t.Errorf("cached keys = %v, want [a c]", got)
+	}
+}
+
+func TestAnalysisCacheEvictsBeyondMaxBytes(t *testing.T) {
+	r := newTestRepository(t)
+	now := time.Now()
+
+	putEntry(t, r, "a", 40, now, 10, 100)
+	putEntry(t, r, "b", 40, now.Add(time.Second), 10, 100)
+	putEntry(t, r, "c", 40, now.Add(2*time.Second), 10, 100)
+
+	entries, size, err := r.AnalysisCacheSize(context.Background())
+	if err != nil {
+		t.Fatalf("AnalysisCacheSize returned error: %v", err)
+	}
+	if entries != 2 || size != 80 {
+		t.Errorf("cache holds %d entries of %d bytes, want 2 of 80", entries, size)
+	}
+	got := cachedKeys(t, r, []string{"a", "b", "c"}, now.Add(3*time.Second))
+	if strings.Join(got, ",") != "b,c" {
+		t.Errorf("cached keys = %v, want [b c]", got)
+	}
+}
+
+func TestAnalysisCachePurgesExpiredEntries(t *testing.T) {
+	r := newTestRepository(t)
+	now := time.Now()
+
+	putEntry(t, r, "old", 10, now, 10, 1000)
+	if got := cachedKeys(t, r, []string{"old"}, now.Add(2*time.Hour)); len(got) != 0 {
+		t.Errorf("expired entry was returned")
+	}
+
+	putEntry(t, r, "new", 10, now.Add(2*time.Hour), 10, 1000)
+	entries, _, err := r.AnalysisCacheSize(context.Background())
+	if err != nil {
+		t.Fatalf("AnalysisCacheSize returned error: %v", err)
+	}
+	if entries != 1 {
+		t.Errorf("cache holds %d entries, want the expired one purged", entries)
+	}
+}
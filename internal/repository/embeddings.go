@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// ReplaceEmbeddings replaces the vectors of a document made by a model
+func (r *repository) ReplaceEmbeddings(ctx context.Context, documentID, model string, embeddings []*models.Embedding) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM embeddings WHERE document_id = $1 AND model = $2`, documentID, model); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO embeddings (id, document_id, chunk_index, start_offset, end_offset, model, dimensions, vector, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for _, embedding := range embeddings {
+		if _, err := tx.ExecContext(ctx, query,
+			embedding.ID,
+			documentID,
+			embedding.ChunkIndex,
+			embedding.Start,
+			embedding.End,
+			model,
+			len(embedding.Vector),
+			encodeVector(embedding.Vector),
+			embedding.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListEmbeddings returns the vectors made by a model, of one document or,
+// when documentID is empty, of every document
+func (r *repository) ListEmbeddings(ctx context.Context, model, documentID string) ([]*models.Embedding, error) {
+	query := `
+		SELECT id, document_id, chunk_index, start_offset, end_offset, model, vector, created_at
+		FROM embeddings
+		WHERE model = $1 AND ($2 = '' OR document_id = $2)
+		ORDER BY document_id, chunk_index
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, model, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	embeddings := []*models.Embedding{}
+	for rows.Next() {
+		var embedding models.Embedding
+		var vector []byte
+		if err := rows.Scan(
+			&embedding.ID,
+			&embedding.DocumentID,
+			&embedding.ChunkIndex,
+			&embedding.Start,
+			&embedding.End,
+			&embedding.Model,
+			&vector,
+			&embedding.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if embedding.Vector, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("embedding %s: %w", embedding.ID, err)
+		}
+		embeddings = append(embeddings, &embedding)
+	}
+
+	return embeddings, rows.Err()
+}
+
+// ListUnembeddedDocuments returns the ids of documents that have no vectors
+// made by a model, oldest first
+func (r *repository) ListUnembeddedDocuments(ctx context.Context, model string) ([]string, error) {
+	query := `
+		SELECT id FROM documents d
+		WHERE NOT EXISTS (SELECT 1 FROM embeddings e WHERE e.document_id = d.id AND e.model = $1)
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// encodeVector stores a vector as little endian float32s
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("vector of %d bytes is not a list of float32", len(buf))
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
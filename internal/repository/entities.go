@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// ReplaceEntities replaces the entities of a document, so re-analysis does
+// not leave mentions behind that the new extraction no longer finds
+func (r *repository) ReplaceEntities(ctx context.Context, documentID string, entities []*models.Entity) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entities WHERE document_id = $1`, documentID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO entities (id, document_id, type, value, text, start_offset, end_offset, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for _, entity := range entities {
+		if _, err := tx.ExecContext(ctx, query,
+			entity.ID,
+			documentID,
+			entity.Type,
+			entity.Value,
+			entity.Text,
+			entity.Start,
+			entity.End,
+			entity.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListEntities returns the entities of a document in the order they appear
+func (r *repository) ListEntities(ctx context.Context, documentID string) ([]*models.Entity, error) {
+	query := `
+		SELECT id, document_id, type, value, text, start_offset, end_offset, created_at
+		FROM entities
+		WHERE document_id = $1
+		ORDER BY start_offset, end_offset
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entities := []*models.Entity{}
+	for rows.Next() {
+		var entity models.Entity
+		if err := rows.Scan(
+			&entity.ID,
+			&entity.DocumentID,
+			&entity.Type,
+			&entity.Value,
+			&entity.Text,
+			&entity.Start,
+			&entity.End,
+			&entity.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entities = append(entities, &entity)
+	}
+
+	return entities, rows.Err()
+}
+
+// SearchEntities returns the documents mentioning an entity, newest first,
+// each with its matching mentions. Limit and offset page over documents
+// rather than mentions.
+func (r *repository) SearchEntities(ctx context.Context, filter models.EntityFilter) ([]*models.EntityDocument, error) {
+	query := `
+		WITH matched AS (
+			SELECT DISTINCT d.id, d.created_at
+			FROM documents d
+			JOIN entities e ON e.document_id = d.id
+			WHERE ($1 = '' OR e.type = $1) AND e.value = $2
+			ORDER BY d.created_at DESC, d.id
+			LIMIT $3 OFFSET $4
+		)
+		SELECT e.id, e.document_id, e.type, e.value, e.text, e.start_offset, e.end_offset, e.created_at,
+		       d.filename, d.document_type
+		FROM matched m
+		JOIN documents d ON d.id = m.id
+		JOIN entities e ON e.document_id = d.id
+		WHERE ($1 = '' OR e.type = $1) AND e.value = $2
+		ORDER BY m.created_at DESC, m.id, e.start_offset
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Type, filter.Value, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*models.EntityDocument{}
+	var current *models.EntityDocument
+	for rows.Next() {
+		var entity models.Entity
+		var filename string
+		var docType sql.NullString
+		if err := rows.Scan(
+			&entity.ID,
+			&entity.DocumentID,
+			&entity.Type,
+			&entity.Value,
+			&entity.Text,
+			&entity.Start,
+			&entity.End,
+			&entity.CreatedAt,
+			&filename,
+			&docType,
+		); err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.DocumentID != entity.DocumentID {
+			current = &models.EntityDocument{DocumentID: entity.DocumentID, Filename: filename}
+			if docType.Valid {
+				current.DocumentType = &docType.String
+			}
+			documents = append(documents, current)
+		}
+		current.Mentions = append(current.Mentions, &entity)
+	}
+
+	return documents, rows.Err()
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// CreateLLMCall records the usage of a model call that leaves no other
+// record, such as a question, a comparison or a failed analysis
+func (r *repository) CreateLLMCall(ctx context.Context, call *models.LLMCall) error {
+	query := `
+		INSERT INTO llm_calls (id, document_id, related_document_id, operation, model, prompt_version,
+		                       prompt_tokens, completion_tokens, cost_usd, failed, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		call.ID,
+		call.DocumentID,
+		nullIfEmpty(call.RelatedDocumentID),
+		call.Operation,
+		call.Model,
+		nullIfEmpty(call.PromptVersion),
+		call.PromptTokens,
+		call.CompletionTokens,
+		call.CostUSD,
+		call.Failed,
+		call.CreatedAt,
+	)
+
+	return err
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func (r *repository) CreateRedaction(ctx context.Context, redaction *models.Redaction) error {
+	countsJSON, err := json.Marshal(redaction.Counts)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO redactions (id, document_id, operation, policy, counts, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		redaction.ID,
+		redaction.DocumentID,
+		redaction.Operation,
+		redaction.Policy,
+		countsJSON,
+		redaction.Total,
+		redaction.CreatedAt,
+	)
+
+	return err
+}
+
+// ListRedactions returns the redaction audit of a document, newest first
+func (r *repository) ListRedactions(ctx context.Context, documentID string) ([]*models.Redaction, error) {
+	query := `
+		SELECT id, document_id, operation, policy, counts, total, created_at
+		FROM redactions
+		WHERE document_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redactions := []*models.Redaction{}
+	for rows.Next() {
+		var redaction models.Redaction
+		var countsJSON string
+
+		if err := rows.Scan(
+			&redaction.ID,
+			&redaction.DocumentID,
+			&redaction.Operation,
+			&redaction.Policy,
+			&countsJSON,
+			&redaction.Total,
+			&redaction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(countsJSON), &redaction.Counts); err != nil {
+			return nil, err
+		}
+
+		redactions = append(redactions, &redaction)
+	}
+
+	return redactions, rows.Err()
+}
@@ -13,8 +13,51 @@ import (
 type Repository interface {
 	Create(ctx context.Context, doc *models.Document) error
 	GetByID(ctx context.Context, id string) (*models.Document, error)
+	GetByContentHash(ctx context.Context, hash string) (*models.Document, error)
 	Update(ctx context.Context, doc *models.Document) error
-	UpdateAnalysis(ctx context.Context, id, summary, docType string, metadata map[string]interface{}) error
+	List(ctx context.Context, filter models.DocumentFilter) ([]*models.Document, error)
+	UpdateAnalysis(ctx context.Context, analysis *models.Analysis, revision int64) error
+	GetStructure(ctx context.Context, id string) (*models.DocumentStructure, error)
+	UpdateStructure(ctx context.Context, id string, structure *models.DocumentStructure) error
+
+	CreateAnalysis(ctx context.Context, analysis *models.Analysis) error
+	ListAnalyses(ctx context.Context, documentID string) ([]*models.Analysis, error)
+	AggregateUsage(ctx context.Context, filter models.UsageFilter) ([]*models.UsageBucket, error)
+	CreateLLMCall(ctx context.Context, call *models.LLMCall) error
+
+	CreateSchema(ctx context.Context, schema *models.ExtractionSchema) error
+	GetSchemaByName(ctx context.Context, name string) (*models.ExtractionSchema, error)
+	ListSchemas(ctx context.Context) ([]*models.ExtractionSchema, error)
+	CreateExtraction(ctx context.Context, extraction *models.Extraction) error
+	ListExtractions(ctx context.Context, documentID string) ([]*models.Extraction, error)
+
+	CreateRedaction(ctx context.Context, redaction *models.Redaction) error
+	ListRedactions(ctx context.Context, documentID string) ([]*models.Redaction, error)
+
+	ReplaceEntities(ctx context.Context, documentID string, entities []*models.Entity) error
+	ListEntities(ctx context.Context, documentID string) ([]*models.Entity, error)
+	SearchEntities(ctx context.Context, filter models.EntityFilter) ([]*models.EntityDocument, error)
+
+	UpsertSummary(ctx context.Context, summary *models.Summary) error
+	GetSummary(ctx context.Context, documentID, style, length string) (*models.Summary, error)
+	ListSummaries(ctx context.Context, documentID string) ([]*models.Summary, error)
+
+	CreateTextVersion(ctx context.Context, version *models.TextVersion) error
+	GetTextVersion(ctx context.Context, documentID, id string) (*models.TextVersion, error)
+	GetLatestTextVersion(ctx context.Context, documentID, kind, language string) (*models.TextVersion, error)
+	ListTextVersions(ctx context.Context, documentID string) ([]*models.TextVersion, error)
+
+	ReplaceEmbeddings(ctx context.Context, documentID, model string, embeddings []*models.Embedding) error
+	ListEmbeddings(ctx context.Context, model, documentID string) ([]*models.Embedding, error)
+	ListUnembeddedDocuments(ctx context.Context, model string) ([]string, error)
+
+	GetCachedAnalysis(ctx context.Context, key string, now time.Time) (*models.CacheEntry, error)
+	PutCachedAnalysis(ctx context.Context, entry *models.CacheEntry, maxEntries int, maxBytes int64) error
+	AnalysisCacheSize(ctx context.Context) (int64, int64, error)
+
+	CorrectAnalysis(ctx context.Context, doc *models.Document, corrections []*models.Correction) error
+	ListCorrections(ctx context.Context, documentID string) ([]*models.Correction, error)
+	ListReviewQueue(ctx context.Context, filter models.ReviewFilter) ([]*models.Document, error)
 }
 
 type repository struct {
@@ -26,9 +69,18 @@ func NewRepository(db *sqlx.DB) Repository {
 }
 
 func (r *repository) Create(ctx context.Context, doc *models.Document) error {
+	var structureJSON sql.NullString
+	if doc.Structure != nil {
+		data, err := json.Marshal(doc.Structure)
+		if err != nil {
+			return err
+		}
+		structureJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
 	query := `
-		INSERT INTO documents (id, filename, file_size, content_type, s3_key, extracted_text, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO documents (id, filename, file_size, content_type, s3_key, content_hash, extracted_text, language, structure, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -37,7 +89,10 @@ func (r *repository) Create(ctx context.Context, doc *models.Document) error {
 		doc.FileSize,
 		doc.ContentType,
 		doc.S3Key,
+		nullIfEmpty(doc.ContentHash),
 		doc.ExtractedText,
+		doc.Language,
+		structureJSON,
 		doc.CreatedAt,
 		doc.UpdatedAt,
 	)
@@ -46,26 +101,57 @@ func (r *repository) Create(ctx context.Context, doc *models.Document) error {
 }
 
 func (r *repository) GetByID(ctx context.Context, id string) (*models.Document, error) {
-	var doc models.Document
-	var metadataJSON sql.NullString
-
 	query := `
-		SELECT id, filename, file_size, content_type, s3_key, extracted_text, 
-		       summary, document_type, metadata, created_at, updated_at, analyzed_at
+		SELECT id, filename, file_size, content_type, s3_key, content_hash, extracted_text, language,
+		       summary, summary_language, document_type, metadata, type_confidence, type_alternatives,
+		       verified_fields, reviewed_by, reviewed_at, revision, created_at, updated_at, analyzed_at
 		FROM documents
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	return r.getOne(ctx, query, id)
+}
+
+// GetByContentHash returns the earliest document uploaded with the given
+// SHA-256, or nil when the content has not been seen before
+func (r *repository) GetByContentHash(ctx context.Context, hash string) (*models.Document, error) {
+	query := `
+		SELECT id, filename, file_size, content_type, s3_key, content_hash, extracted_text, language,
+		       summary, summary_language, document_type, metadata, type_confidence, type_alternatives,
+		       verified_fields, reviewed_by, reviewed_at, revision, created_at, updated_at, analyzed_at
+		FROM documents
+		WHERE content_hash = $1
+		ORDER BY created_at ASC
+		LIMIT 1
+	`
+
+	return r.getOne(ctx, query, hash)
+}
+
+func (r *repository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Document, error) {
+	var doc models.Document
+	var contentHash sql.NullString
+	var metadataJSON, alternativesJSON, verifiedJSON sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&doc.ID,
 		&doc.Filename,
 		&doc.FileSize,
 		&doc.ContentType,
 		&doc.S3Key,
+		&contentHash,
 		&doc.ExtractedText,
+		&doc.Language,
 		&doc.Summary,
+		&doc.SummaryLanguage,
 		&doc.DocumentType,
 		&metadataJSON,
+		&doc.TypeConfidence,
+		&alternativesJSON,
+		&verifiedJSON,
+		&doc.ReviewedBy,
+		&doc.ReviewedAt,
+		&doc.Revision,
 		&doc.CreatedAt,
 		&doc.UpdatedAt,
 		&doc.AnalyzedAt,
@@ -78,15 +164,94 @@ func (r *repository) GetByID(ctx context.Context, id string) (*models.Document,
 		return nil, err
 	}
 
+	doc.ContentHash = contentHash.String
+
 	if metadataJSON.Valid && metadataJSON.String != "" {
 		if err := json.Unmarshal([]byte(metadataJSON.String), &doc.Metadata); err != nil {
 			return nil, err
 		}
 	}
+	if doc.TypeAlternatives, err = unmarshalTypeScores(alternativesJSON); err != nil {
+		return nil, err
+	}
+	if doc.VerifiedFields, err = unmarshalStrings(verifiedJSON); err != nil {
+		return nil, err
+	}
 
 	return &doc, nil
 }
 
+// typeVerified is true for documents whose type a reviewer verified
+const typeVerified = `EXISTS (SELECT 1 FROM json_each(COALESCE(verified_fields, '[]')) WHERE value = '` + models.FieldDocumentType + `')`
+
+// List returns documents matching the filter, newest first. Extracted text is
+// not loaded; fetch a single document for the full content.
+func (r *repository) List(ctx context.Context, filter models.DocumentFilter) ([]*models.Document, error) {
+	query := `
+		SELECT id, filename, file_size, content_type, s3_key, language,
+		       summary, summary_language, document_type, metadata, type_confidence, type_alternatives,
+		       verified_fields, reviewed_by, reviewed_at, created_at, updated_at, analyzed_at
+		FROM documents
+		WHERE ($1 = '' OR language = $1)
+		  AND ($2 = '' OR document_type = $2)
+		  AND (NOT $3 OR (type_confidence < $4 AND NOT ` + typeVerified + `))
+		ORDER BY created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Language, filter.DocumentType,
+		filter.NeedsReview, filter.ReviewBelow, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*models.Document{}
+	for rows.Next() {
+		var doc models.Document
+		var metadataJSON, alternativesJSON, verifiedJSON sql.NullString
+
+		if err := rows.Scan(
+			&doc.ID,
+			&doc.Filename,
+			&doc.FileSize,
+			&doc.ContentType,
+			&doc.S3Key,
+			&doc.Language,
+			&doc.Summary,
+			&doc.SummaryLanguage,
+			&doc.DocumentType,
+			&metadataJSON,
+			&doc.TypeConfidence,
+			&alternativesJSON,
+			&verifiedJSON,
+			&doc.ReviewedBy,
+			&doc.ReviewedAt,
+			&doc.CreatedAt,
+			&doc.UpdatedAt,
+			&doc.AnalyzedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			if err := json.Unmarshal([]byte(metadataJSON.String), &doc.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		if doc.TypeAlternatives, err = unmarshalTypeScores(alternativesJSON); err != nil {
+			return nil, err
+		}
+		if doc.VerifiedFields, err = unmarshalStrings(verifiedJSON); err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, &doc)
+	}
+
+	return docs, rows.Err()
+}
+
 func (r *repository) Update(ctx context.Context, doc *models.Document) error {
 	query := `
 		UPDATE documents
@@ -106,20 +271,130 @@ func (r *repository) Update(ctx context.Context, doc *models.Document) error {
 	return err
 }
 
-func (r *repository) UpdateAnalysis(ctx context.Context, id, summary, docType string, metadata map[string]interface{}) error {
-	metadataJSON, err := json.Marshal(metadata)
+// UpdateAnalysis stores the result of an analysis on its document. It
+// returns ErrConflict when the document's revision is no longer revision,
+// the one the analysis was made from.
+func (r *repository) UpdateAnalysis(ctx context.Context, analysis *models.Analysis, revision int64) error {
+	metadataJSON, err := json.Marshal(analysis.Metadata)
+	if err != nil {
+		return err
+	}
+	alternativesJSON, err := marshalTypeScores(analysis.TypeAlternatives)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE documents
+		SET summary = $2, summary_language = $3, document_type = $4, metadata = $5,
+		    type_confidence = $6, type_alternatives = $7, analyzed_at = $8, updated_at = $9,
+		    revision = revision + 1
+		WHERE id = $1 AND revision = $10
+	`
+
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, query,
+		analysis.DocumentID,
+		analysis.Summary,
+		nullIfEmpty(analysis.SummaryLanguage),
+		analysis.DocumentType,
+		metadataJSON,
+		analysis.TypeConfidence,
+		alternativesJSON,
+		now,
+		now,
+		revision,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// marshalTypeScores stores alternatives as JSON, or NULL when there are none
+func marshalTypeScores(scores []models.TypeScore) (sql.NullString, error) {
+	if len(scores) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalTypeScores(data sql.NullString) ([]models.TypeScore, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	var scores []models.TypeScore
+	if err := json.Unmarshal([]byte(data.String), &scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+func unmarshalStrings(data sql.NullString) ([]string, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(data.String), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// GetStructure returns the stored document structure. It returns nil when the
+// document does not exist or was uploaded before structures were recorded.
+func (r *repository) GetStructure(ctx context.Context, id string) (*models.DocumentStructure, error) {
+	var structureJSON sql.NullString
+
+	query := `SELECT structure FROM documents WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&structureJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !structureJSON.Valid || structureJSON.String == "" {
+		return nil, nil
+	}
+
+	var structure models.DocumentStructure
+	if err := json.Unmarshal([]byte(structureJSON.String), &structure); err != nil {
+		return nil, err
+	}
+
+	return &structure, nil
+}
+
+func (r *repository) UpdateStructure(ctx context.Context, id string, structure *models.DocumentStructure) error {
+	structureJSON, err := json.Marshal(structure)
 	if err != nil {
 		return err
 	}
 
 	query := `
 		UPDATE documents
-		SET summary = $2, document_type = $3, metadata = $4, analyzed_at = $5, updated_at = $6
+		SET structure = $2, updated_at = $3
 		WHERE id = $1
 	`
 
-	now := time.Now()
-	_, err = r.db.ExecContext(ctx, query, id, summary, docType, metadataJSON, now, now)
+	_, err = r.db.ExecContext(ctx, query, id, structureJSON, time.Now())
 
 	return err
 }
+
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
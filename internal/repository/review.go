@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// ErrConflict is returned when a document changed after it was read
+var ErrConflict = errors.New("document was changed by another request")
+
+// CorrectAnalysis stores a reviewer's changes to a document's type,
+// metadata and verified fields together with their audit entries. It
+// returns ErrConflict when the document's revision is no longer
+// doc.Revision.
+func (r *repository) CorrectAnalysis(ctx context.Context, doc *models.Document, corrections []*models.Correction) error {
+	metadataJSON, err := json.Marshal(doc.Metadata)
+	if err != nil {
+		return err
+	}
+	verifiedJSON, err := json.Marshal(doc.VerifiedFields)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The update only applies to the revision the corrections were made
+	// on, so an analysis stored since then is not overwritten
+	query := `
+		UPDATE documents
+		SET document_type = $2, metadata = $3, verified_fields = $4,
+		    reviewed_by = $5, reviewed_at = $6, updated_at = $6, revision = revision + 1
+		WHERE id = $1 AND revision = $7
+	`
+	res, err := tx.ExecContext(ctx, query,
+		doc.ID,
+		doc.DocumentType,
+		metadataJSON,
+		verifiedJSON,
+		doc.ReviewedBy,
+		doc.ReviewedAt,
+		doc.Revision,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrConflict
+	}
+
+	insert := `
+		INSERT INTO corrections (id, document_id, field, action, old_value, new_value, reviewer, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for _, correction := range corrections {
+		oldJSON, err := marshalValue(correction.OldValue)
+		if err != nil {
+			return err
+		}
+		newJSON, err := marshalValue(correction.NewValue)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, insert,
+			correction.ID,
+			correction.DocumentID,
+			correction.Field,
+			correction.Action,
+			oldJSON,
+			newJSON,
+			correction.Reviewer,
+			nullIfEmpty(correction.Note),
+			correction.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListCorrections returns the correction audit of a document, newest first
+func (r *repository) ListCorrections(ctx context.Context, documentID string) ([]*models.Correction, error) {
+	query := `
+		SELECT id, document_id, field, action, old_value, new_value, reviewer, note, created_at
+		FROM corrections
+		WHERE document_id = $1
+		ORDER BY created_at DESC, field ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	corrections := []*models.Correction{}
+	for rows.Next() {
+		var correction models.Correction
+		var oldJSON, newJSON, note sql.NullString
+
+		if err := rows.Scan(
+			&correction.ID,
+			&correction.DocumentID,
+			&correction.Field,
+			&correction.Action,
+			&oldJSON,
+			&newJSON,
+			&correction.Reviewer,
+			&note,
+			&correction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if correction.OldValue, err = unmarshalValue(oldJSON); err != nil {
+			return nil, err
+		}
+		if correction.NewValue, err = unmarshalValue(newJSON); err != nil {
+			return nil, err
+		}
+		correction.Note = note.String
+
+		corrections = append(corrections, &correction)
+	}
+
+	return corrections, rows.Err()
+}
+
+// ListReviewQueue returns the analyzed documents that need a reviewer,
+// least confident classifications first and then oldest analyses first.
+// Extracted text and metadata are not loaded.
+func (r *repository) ListReviewQueue(ctx context.Context, filter models.ReviewFilter) ([]*models.Document, error) {
+	query := `
+		SELECT id, filename, document_type, type_confidence, verified_fields, reviewed_by, reviewed_at, analyzed_at
+		FROM documents
+		WHERE analyzed_at IS NOT NULL
+		  AND (reviewed_at IS NULL OR (type_confidence < $1 AND NOT ` + typeVerified + `))
+		ORDER BY type_confidence IS NULL, type_confidence ASC, analyzed_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.ReviewBelow, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*models.Document{}
+	for rows.Next() {
+		var doc models.Document
+		var verifiedJSON sql.NullString
+
+		if err := rows.Scan(
+			&doc.ID,
+			&doc.Filename,
+			&doc.DocumentType,
+			&doc.TypeConfidence,
+			&verifiedJSON,
+			&doc.ReviewedBy,
+			&doc.ReviewedAt,
+			&doc.AnalyzedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if doc.VerifiedFields, err = unmarshalStrings(verifiedJSON); err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, &doc)
+	}
+
+	return docs, rows.Err()
+}
+
+// marshalValue stores a field value as JSON, or NULL when there is none
+func marshalValue(value interface{}) (sql.NullString, error) {
+	if value == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalValue(data sql.NullString) (interface{}, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(data.String), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
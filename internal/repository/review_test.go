@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestCorrectAnalysisRejectsStaleRevision(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	doc := &models.Document{ID: "doc", Filename: "invoice.pdf", S3Key: "k", CreatedAt: now, UpdatedAt: now}
+	if err := r.Create(ctx, doc); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	analysis := &models.Analysis{DocumentID: "doc", DocumentType: "invoice", Metadata: map[string]interface{}{"vendor": "ACME"}}
+	if err := r.UpdateAnalysis(ctx, analysis, 0); err != nil {
+		t.Fatalf("UpdateAnalysis returned error: %v", err)
+	}
+
+	read, err := r.GetByID(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+
+	// An analysis finishes between the reviewer's read and write
+	analysis.Metadata = map[string]interface{}{"vendor": "ACME", "amount": "10.00"}
+	if err := r.UpdateAnalysis(ctx, analysis, read.Revision); err != nil {
+		t.Fatalf("UpdateAnalysis returned error: %v", err)
+	}
+
+	reviewer := "alice"
+	read.Metadata["vendor"] = "Acme Ltd"
+	read.VerifiedFields = []string{"metadata.vendor"}
+	read.ReviewedBy, read.ReviewedAt = &reviewer, &now
+	correction := &models.Correction{ID: "c1", DocumentID: "doc", Field: "metadata.vendor", Action: models.CorrectionCorrected,
+		OldValue: "ACME", NewValue: "Acme Ltd", Reviewer: reviewer, CreatedAt: now}
+
+	if err := r.CorrectAnalysis(ctx, read, []*models.Correction{correction}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("CorrectAnalysis on a stale document returned %v, want ErrConflict", err)
+	}
+	stored, _ := r.GetByID(ctx, "doc")
+	if stored.Metadata["amount"] != "10.00" || stored.ReviewedAt != nil {
+		t.Errorf("stale correction overwrote the analysis: %+v", stored)
+	}
+	if corrections, _ := r.ListCorrections(ctx, "doc"); len(corrections) != 0 {
+		t.Errorf("stale correction was audited: %+v", corrections)
+	}
+
+	stored.Metadata["vendor"] = "Acme Ltd"
+	stored.VerifiedFields = read.VerifiedFields
+	stored.ReviewedBy, stored.ReviewedAt = &reviewer, &now
+	if err := r.CorrectAnalysis(ctx, stored, []*models.Correction{correction}); err != nil {
+		t.Fatalf("CorrectAnalysis returned error: %v", err)
+	}
+	corrected, _ := r.GetByID(ctx, "doc")
+	if corrected.Metadata["vendor"] != "Acme Ltd" || corrected.Metadata["amount"] != "10.00" || corrected.Revision != stored.Revision+1 {
+		t.Errorf("corrected document = %+v", corrected)
+	}
+}
+
+func TestUpdateAnalysisRejectsStaleRevision(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	doc := &models.Document{ID: "doc", Filename: "invoice.pdf", S3Key: "k", CreatedAt: now, UpdatedAt: now}
+	if err := r.Create(ctx, doc); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	analysis := &models.Analysis{DocumentID: "doc", DocumentType: "invoice", Metadata: map[string]interface{}{}}
+	if err := r.UpdateAnalysis(ctx, analysis, 0); err != nil {
+		t.Fatalf("UpdateAnalysis returned error: %v", err)
+	}
+
+	analysis.DocumentType = "receipt"
+	if err := r.UpdateAnalysis(ctx, analysis, 0); !errors.Is(err, ErrConflict) {
+		t.Fatalf("UpdateAnalysis on a stale revision returned %v, want ErrConflict", err)
+	}
+	if stored, _ := r.GetByID(ctx, "doc"); *stored.DocumentType != "invoice" || stored.Revision != 1 {
+		t.Errorf("stale analysis was stored: type %s, revision %d", *stored.DocumentType, stored.Revision)
+	}
+	analysis.DocumentID = "missing"
+	if err := r.UpdateAnalysis(ctx, analysis, 0); !errors.Is(err, ErrConflict) {
+		t.Fatalf("UpdateAnalysis of a missing document returned %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func (r *repository) CreateSchema(ctx context.Context, schema *models.ExtractionSchema) error {
+	fieldsJSON, err := json.Marshal(schema.Fields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO schemas (id, name, description, fields, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		schema.ID,
+		schema.Name,
+		nullIfEmpty(schema.Description),
+		fieldsJSON,
+		schema.CreatedAt,
+	)
+
+	return err
+}
+
+// GetSchemaByName returns nil when no schema has that name
+func (r *repository) GetSchemaByName(ctx context.Context, name string) (*models.ExtractionSchema, error) {
+	query := `
+		SELECT id, name, description, fields, created_at
+		FROM schemas
+		WHERE name = $1
+	`
+
+	schema, err := scanSchema(r.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return schema, err
+}
+
+func (r *repository) ListSchemas(ctx context.Context) ([]*models.ExtractionSchema, error) {
+	query := `
+		SELECT id, name, description, fields, created_at
+		FROM schemas
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []*models.ExtractionSchema{}
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchema(row rowScanner) (*models.ExtractionSchema, error) {
+	var schema models.ExtractionSchema
+	var description sql.NullString
+	var fieldsJSON string
+
+	if err := row.Scan(&schema.ID, &schema.Name, &description, &fieldsJSON, &schema.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	schema.Description = description.String
+	if err := json.Unmarshal([]byte(fieldsJSON), &schema.Fields); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+func (r *repository) CreateExtraction(ctx context.Context, extraction *models.Extraction) error {
+	dataJSON, err := json.Marshal(extraction.Data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO extractions (id, document_id, schema_id, schema_name, data, model, prompt_version,
+		                         latency_ms, prompt_tokens, completion_tokens, cost_usd, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		extraction.ID,
+		extraction.DocumentID,
+		extraction.SchemaID,
+		extraction.SchemaName,
+		dataJSON,
+		extraction.Model,
+		extraction.PromptVersion,
+		extraction.LatencyMS,
+		extraction.PromptTokens,
+		extraction.CompletionTokens,
+		extraction.CostUSD,
+		extraction.CreatedAt,
+	)
+
+	return err
+}
+
+// ListExtractions returns every extraction for a document, newest first
+func (r *repository) ListExtractions(ctx context.Context, documentID string) ([]*models.Extraction, error) {
+	query := `
+		SELECT id, document_id, schema_id, schema_name, data, model, prompt_version,
+		       latency_ms, prompt_tokens, completion_tokens, cost_usd, created_at
+		FROM extractions
+		WHERE document_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	extractions := []*models.Extraction{}
+	for rows.Next() {
+		var extraction models.Extraction
+		var dataJSON string
+		var cost sql.NullFloat64
+
+		if err := rows.Scan(
+			&extraction.ID,
+			&extraction.DocumentID,
+			&extraction.SchemaID,
+			&extraction.SchemaName,
+			&dataJSON,
+			&extraction.Model,
+			&extraction.PromptVersion,
+			&extraction.LatencyMS,
+			&extraction.PromptTokens,
+			&extraction.CompletionTokens,
+			&cost,
+			&extraction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if cost.Valid {
+			extraction.CostUSD = &cost.Float64
+		}
+		if err := json.Unmarshal([]byte(dataJSON), &extraction.Data); err != nil {
+			return nil, err
+		}
+
+		extractions = append(extractions, &extraction)
+	}
+
+	return extractions, rows.Err()
+}
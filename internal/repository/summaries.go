@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// UpsertSummary stores a summary, replacing the document's previous
+// summary in the same style and length
+func (r *repository) UpsertSummary(ctx context.Context, summary *models.Summary) error {
+	query := `
+		INSERT INTO summaries (id, document_id, style, length, summary, summary_language,
+		                       analysis_id, model, prompt_version, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (document_id, style, length) DO UPDATE SET
+			summary = excluded.summary,
+			summary_language = excluded.summary_language,
+			analysis_id = excluded.analysis_id,
+			model = excluded.model,
+			prompt_version = excluded.prompt_version,
+			created_at = excluded.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		summary.ID,
+		summary.DocumentID,
+		summary.Style,
+		summary.Length,
+		summary.Summary,
+		nullIfEmpty(summary.SummaryLanguage),
+		nullIfEmpty(summary.AnalysisID),
+		nullIfEmpty(summary.Model),
+		nullIfEmpty(summary.PromptVersion),
+		summary.CreatedAt,
+	)
+
+	return err
+}
+
+// GetSummary returns the summary of a document in a style and length, or
+// nil when there is none
+func (r *repository) GetSummary(ctx context.Context, documentID, style, length string) (*models.Summary, error) {
+	query := `
+		SELECT id, document_id, style, length, summary, summary_language,
+		       analysis_id, model, prompt_version, created_at
+		FROM summaries
+		WHERE document_id = $1 AND style = $2 AND length = $3
+	`
+
+	summary, err := scanSummary(r.db.QueryRowContext(ctx, query, documentID, style, length))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return summary, err
+}
+
+// ListSummaries returns every summary variant of a document
+func (r *repository) ListSummaries(ctx context.Context, documentID string) ([]*models.Summary, error) {
+	query := `
+		SELECT id, document_id, style, length, summary, summary_language,
+		       analysis_id, model, prompt_version, created_at
+		FROM summaries
+		WHERE document_id = $1
+		ORDER BY style, length
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []*models.Summary{}
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func scanSummary(row rowScanner) (*models.Summary, error) {
+	var summary models.Summary
+	var summaryLanguage, analysisID, model, promptVersion sql.NullString
+
+	if err := row.Scan(
+		&summary.ID,
+		&summary.DocumentID,
+		&summary.Style,
+		&summary.Length,
+		&summary.Summary,
+		&summaryLanguage,
+		&analysisID,
+		&model,
+		&promptVersion,
+		&summary.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	summary.SummaryLanguage = summaryLanguage.String
+	summary.AnalysisID = analysisID.String
+	summary.Model = model.String
+	summary.PromptVersion = promptVersion.String
+	return &summary, nil
+}
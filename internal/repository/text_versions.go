@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func (r *repository) CreateTextVersion(ctx context.Context, version *models.TextVersion) error {
+	query := `
+		INSERT INTO text_versions (id, document_id, kind, language, source_language, text, model,
+		                           prompt_version, prompt_tokens, completion_tokens, cost_usd, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		version.ID,
+		version.DocumentID,
+		version.Kind,
+		version.Language,
+		nullIfEmpty(version.SourceLanguage),
+		version.Text,
+		nullIfEmpty(version.Model),
+		nullIfEmpty(version.PromptVersion),
+		version.PromptTokens,
+		version.CompletionTokens,
+		version.CostUSD,
+		version.CreatedAt,
+	)
+
+	return err
+}
+
+// GetTextVersion returns a text version of a document with its text, or nil
+// when the document has no version with that id
+func (r *repository) GetTextVersion(ctx context.Context, documentID, id string) (*models.TextVersion, error) {
+	query := `
+		SELECT id, document_id, kind, language, source_language, text, model,
+		       prompt_version, prompt_tokens, completion_tokens, cost_usd, created_at
+		FROM text_versions
+		WHERE document_id = $1 AND id = $2
+	`
+
+	version, err := scanTextVersion(r.db.QueryRowContext(ctx, query, documentID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return version, err
+}
+
+// GetLatestTextVersion returns the newest text version of a kind in a
+// language, or nil when there is none
+func (r *repository) GetLatestTextVersion(ctx context.Context, documentID, kind, language string) (*models.TextVersion, error) {
+	query := `
+		SELECT id, document_id, kind, language, source_language, text, model,
+		       prompt_version, prompt_tokens, completion_tokens, cost_usd, created_at
+		FROM text_versions
+		WHERE document_id = $1 AND kind = $2 AND language = $3
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	version, err := scanTextVersion(r.db.QueryRowContext(ctx, query, documentID, kind, language))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return version, err
+}
+
+// ListTextVersions returns the text versions of a document, newest first.
+// Their text is left out, since it is as long as the document's.
+func (r *repository) ListTextVersions(ctx context.Context, documentID string) ([]*models.TextVersion, error) {
+	query := `
+		SELECT id, document_id, kind, language, source_language, '' AS text, model,
+		       prompt_version, prompt_tokens, completion_tokens, cost_usd, created_at
+		FROM text_versions
+		WHERE document_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*models.TextVersion{}
+	for rows.Next() {
+		version, err := scanTextVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func scanTextVersion(row rowScanner) (*models.TextVersion, error) {
+	var version models.TextVersion
+	var sourceLanguage, model, promptVersion sql.NullString
+	var cost sql.NullFloat64
+
+	if err := row.Scan(
+		&version.ID,
+		&version.DocumentID,
+		&version.Kind,
+		&version.Language,
+		&sourceLanguage,
+		&version.Text,
+		&model,
+		&promptVersion,
+		&version.PromptTokens,
+		&version.CompletionTokens,
+		&cost,
+		&version.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	version.SourceLanguage = sourceLanguage.String
+	version.Model = model.String
+	version.PromptVersion = promptVersion.String
+	if cost.Valid {
+		version.CostUSD = &cost.Float64
+	}
+	return &version, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestAggregateUsageCountsQuestionsAndComparisons(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"v1", "v2"} {
+		if err := r.Create(ctx, &models.Document{ID: id, Filename: id, S3Key: id, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("Create returned error: %v", err)
+		}
+	}
+
+	cost := 0.25
+	calls := []*models.LLMCall{
+		{ID: "a1", DocumentID: "v1", Operation: models.OperationAsk, Model: "m", PromptTokens: 100, CompletionTokens: 10, CostUSD: &cost, CreatedAt: now},
+		{ID: "a2", DocumentID: "v2", Operation: models.OperationAsk, Model: "m", PromptTokens: 50, CompletionTokens: 5, CostUSD: &cost, CreatedAt: now},
+		{ID: "c1", DocumentID: "v1", RelatedDocumentID: "v2", Operation: models.OperationCompare, Model: "m", PromptTokens: 300, CompletionTokens: 40, CreatedAt: now},
+		{ID: "f1", DocumentID: "v1", Operation: models.OperationAnalyze, Model: "m", PromptTokens: 200, CompletionTokens: 20, Failed: true, CreatedAt: now},
+	}
+	for _, call := range calls {
+		if err := r.CreateLLMCall(ctx, call); err != nil {
+			t.Fatalf("CreateLLMCall returned error: %v", err)
+		}
+	}
+
+	buckets, err := r.AggregateUsage(ctx, models.UsageFilter{From: "2024-01-02", To: "2024-01-02"})
+	if err != nil {
+		t.Fatalf("AggregateUsage returned error: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("got %d buckets, want 1", len(buckets))
+	}
+	b := buckets[0]
+	if b.Questions != 2 || b.Comparisons != 1 || b.Analyses != 0 || b.FailedCalls != 1 {
+		t.Errorf("bucket counts = %d questions, %d comparisons, %d analyses, %d failed calls", b.Questions, b.Comparisons, b.Analyses, b.FailedCalls)
+	}
+	if b.PromptTokens != 650 || b.CompletionTokens != 75 || b.CostUSD != 0.5 {
+		t.Errorf("bucket usage = %d/%d tokens, $%v", b.PromptTokens, b.CompletionTokens, b.CostUSD)
+	}
+}
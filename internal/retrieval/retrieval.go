@@ -0,0 +1,247 @@
+package retrieval
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Chunk is a contiguous passage of a text. Start and End are byte offsets
+// into the text the chunk was split from.
+type Chunk struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+type span struct {
+	start, end int
+}
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+// Split cuts text into chunks of at most size bytes. Paragraphs are kept
+// together where they fit; longer ones are cut at sentence ends, then at
+// whitespace.
+func Split(text string, size int) []Chunk {
+	if size <= 0 {
+		size = len(text)
+	}
+
+	var spans []span
+	start := 0
+	for _, brk := range append(paragraphBreak.FindAllStringIndex(text, -1), []int{len(text), len(text)}) {
+		if s, ok := trimSpan(text, start, brk[0]); ok {
+			spans = append(spans, splitLong(text, s, size)...)
+		}
+		start = brk[1]
+	}
+
+	var chunks []Chunk
+	for i := 0; i < len(spans); {
+		current := spans[i]
+		i++
+		for i < len(spans) && spans[i].end-current.start <= size {
+			current.end = spans[i].end
+			i++
+		}
+		chunks = append(chunks, Chunk{
+			Index: len(chunks),
+			Start: current.start,
+			End:   current.end,
+			Text:  text[current.start:current.end],
+		})
+	}
+
+	return chunks
+}
+
+// trimSpan narrows [start, end) to exclude surrounding whitespace
+func trimSpan(text string, start, end int) (span, bool) {
+	segment := text[start:end]
+	trimmed := strings.TrimLeftFunc(segment, unicode.IsSpace)
+	start += len(segment) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	end = start + len(trimmed)
+	return span{start, end}, end > start
+}
+
+// splitLong breaks a paragraph longer than size into pieces
+func splitLong(text string, s span, size int) []span {
+	var pieces []span
+	for s.end-s.start > size {
+		window := text[s.start : s.start+size]
+
+		cut := lastSentenceEnd(window)
+		if cut <= 0 {
+			cut = strings.LastIndexFunc(window, unicode.IsSpace)
+		}
+		if cut <= 0 {
+			// No break point; cut at the last full rune
+			cut = size
+			for cut > 0 && !utf8.RuneStart(text[s.start+cut]) {
+				cut--
+			}
+		}
+
+		if piece, ok := trimSpan(text, s.start, s.start+cut); ok {
+			pieces = append(pieces, piece)
+		}
+		next, ok := trimSpan(text, s.start+cut, s.end)
+		if !ok {
+			return pieces
+		}
+		s = next
+	}
+	return append(pieces, s)
+}
+
+// lastSentenceEnd returns the offset just past the last ". ", "! " or "? "
+// in window, or -1
+func lastSentenceEnd(window string) int {
+	best := -1
+	for _, end := range []string{". ", "! ", "? ", ".\n", "!\n", "?\n"} {
+		if i := strings.LastIndex(window, end); i >= 0 && i+1 > best {
+			best = i + 1
+		}
+	}
+	return best
+}
+
+// Tokenize lowercases text and splits it into words of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// BM25 parameters: k1 controls term frequency saturation, b how strongly
+// scores are normalized by chunk length
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Index ranks chunks against keyword queries with Okapi BM25
+type Index struct {
+	chunks    []Chunk
+	termFreqs []map[string]int
+	lengths   []int
+	docFreq   map[string]int
+	avgLength float64
+}
+
+func NewIndex(chunks []Chunk) *Index {
+	ix := &Index{
+		chunks:    chunks,
+		termFreqs: make([]map[string]int, len(chunks)),
+		lengths:   make([]int, len(chunks)),
+		docFreq:   map[string]int{},
+	}
+
+	total := 0
+	for i, chunk := range chunks {
+		freqs := map[string]int{}
+		tokens := Tokenize(chunk.Text)
+		for _, token := range tokens {
+			freqs[token]++
+		}
+		for term := range freqs {
+			ix.docFreq[term]++
+		}
+		ix.termFreqs[i] = freqs
+		ix.lengths[i] = len(tokens)
+		total += len(tokens)
+	}
+	if len(chunks) > 0 {
+		ix.avgLength = float64(total) / float64(len(chunks))
+	}
+
+	return ix
+}
+
+// Result is a chunk with its relevance score
+type Result struct {
+	Chunk Chunk
+	Score float64
+}
+
+// Search returns up to k chunks that share terms with query, best first
+func (ix *Index) Search(query string, k int) []Result {
+	terms := map[string]bool{}
+	for _, token := range Tokenize(query) {
+		terms[token] = true
+	}
+
+	n := float64(len(ix.chunks))
+	var results []Result
+	for i, chunk := range ix.chunks {
+		score := 0.0
+		for term := range terms {
+			tf := float64(ix.termFreqs[i][term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(ix.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(ix.lengths[i])/ix.avgLength
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 {
+			results = append(results, Result{Chunk: chunk, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	return results
+}
+
+// Locate finds quote in text and returns its byte offsets. The search is
+// exact first, then ignores case and differences in whitespace, since models
+// often reflow quoted text. Matches inside within are preferred.
+func Locate(text, quote string, within Chunk) (int, int, bool) {
+	quote = strings.TrimSpace(quote)
+	if quote == "" {
+		return 0, 0, false
+	}
+
+	if within.End > within.Start && within.End <= len(text) {
+		if i := strings.Index(text[within.Start:within.End], quote); i >= 0 {
+			start := within.Start + i
+			return start, start + len(quote), true
+		}
+	}
+	if i := strings.Index(text, quote); i >= 0 {
+		return i, i + len(quote), true
+	}
+
+	words := strings.Fields(quote)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	pattern, err := regexp.Compile(`(?i)` + strings.Join(words, `\s+`))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if within.End > within.Start && within.End <= len(text) {
+		if m := pattern.FindStringIndex(text[within.Start:within.End]); m != nil {
+			return within.Start + m[0], within.Start + m[1], true
+		}
+	}
+	if m := pattern.FindStringIndex(text); m != nil {
+		return m[0], m[1], true
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,108 @@
+package retrieval
+
+import (
+	"strings"
+	"testing"
+)
+
+const contract = `SERVICE AGREEMENT
+
+This agreement is made between Acme Ltd and Beta Corp.
+
+Payment terms: invoices are payable within 30 days of receipt. Late payments accrue interest at 2% per month.
+
+Either party may terminate this agreement with 60 days written notice.
+
+This agreement is governed by the laws of Kenya.`
+
+func TestSplitKeepsOffsets(t *testing.T) {
+	chunks := Split(contract, 120)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want at least 3", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if chunk.Index != i {
+			t.Errorf("chunk %d has index %d", i, chunk.Index)
+		}
+		if contract[chunk.Start:chunk.End] != chunk.Text {
+			t.Errorf("chunk %d text does not match its offsets", i)
+		}
+		if len(chunk.Text) > 120 {
+			t.Errorf("chunk %d is %d bytes, want at most 120", i, len(chunk.Text))
+		}
+		if strings.TrimSpace(chunk.Text) != chunk.Text {
+			t.Errorf("chunk %d has surrounding whitespace: %q", i, chunk.Text)
+		}
+	}
+}
+
+func TestSplitLongParagraph(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	chunks := Split(text, 100)
+
+	for _, chunk := range chunks {
+		if len(chunk.Text) > 100 {
+			t.Errorf("chunk is %d bytes, want at most 100", len(chunk.Text))
+		}
+		if !strings.HasSuffix(chunk.Text, ".") {
+			t.Errorf("chunk %q was not cut at a sentence end", chunk.Text)
+		}
+	}
+
+	noSpaces := strings.Repeat("é", 80)
+	for _, chunk := range Split(noSpaces, 25) {
+		if !strings.HasPrefix(noSpaces[chunk.Start:], chunk.Text) || len(chunk.Text)%2 != 0 {
+			t.Errorf("chunk %q was cut inside a rune", chunk.Text)
+		}
+	}
+}
+
+func TestSearchRanksRelevantChunk(t *testing.T) {
+	ix := NewIndex(Split(contract, 120))
+
+	results := ix.Search("What are the payment terms?", 2)
+	if len(results) == 0 {
+		t.Fatal("no results")
+	}
+	if !strings.Contains(results[0].Chunk.Text, "payable within 30 days") {
+		t.Errorf("top result = %q, want the payment terms paragraph", results[0].Chunk.Text)
+	}
+
+	results = ix.Search("How can the contract be terminated?", 1)
+	if len(results) != 1 || !strings.Contains(results[0].Chunk.Text, "terminate") {
+		t.Errorf("results = %+v, want the termination paragraph", results)
+	}
+
+	if results := ix.Search("zebra", 3); len(results) != 0 {
+		t.Errorf("results for an unknown term = %+v, want none", results)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	chunks := Split(contract, 120)
+
+	tests := []struct {
+		quote string
+		want  string
+	}{
+		{"payable within 30 days", "payable within 30 days"},
+		{"PAYABLE within\n30   days of receipt", "payable within 30 days of receipt"},
+		{"  governed by the laws of Kenya  ", "governed by the laws of Kenya"},
+	}
+
+	for _, tt := range tests {
+		start, end, ok := Locate(contract, tt.quote, chunks[0])
+		if !ok {
+			t.Errorf("Locate(%q) found nothing", tt.quote)
+			continue
+		}
+		if got := contract[start:end]; got != tt.want {
+			t.Errorf("Locate(%q) = %q, want %q", tt.quote, got, tt.want)
+		}
+	}
+
+	if _, _, ok := Locate(contract, "payable within 90 days", Chunk{}); ok {
+		t.Error("Locate found a quote that is not in the text")
+	}
+}
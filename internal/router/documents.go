@@ -3,6 +3,7 @@ package router
 import (
 	"net/http"
 
+	"github.com/BerylCAtieno/document-summarizer-api/internal/config"
 	"github.com/BerylCAtieno/document-summarizer-api/internal/handlers"
 	"github.com/BerylCAtieno/document-summarizer-api/internal/middleware"
 	"github.com/BerylCAtieno/document-summarizer-api/internal/services"
@@ -11,7 +12,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(docService services.DocumentService, logger *utils.Logger) http.Handler {
+func NewRouter(docService services.DocumentService, cfg *config.Config, logger *utils.Logger) http.Handler {
 	r := mux.NewRouter()
 
 	// Middlewares
@@ -20,21 +21,49 @@ func NewRouter(docService services.DocumentService, logger *utils.Logger) http.H
 	r.Use(middleware.Recovery(logger))
 
 	// Document handler
-	docHandler := handlers.NewDocumentHandler(docService, logger)
+	docHandler := handlers.NewDocumentHandler(docService, cfg.MaxFileSize, logger)
 
 	// Routes
 	api := r.PathPrefix("/api/v1").Subrouter()
 
 	// Health check
-	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
-	}).Methods(http.MethodGet)
+	api.HandleFunc("/health", docHandler.Health).Methods(http.MethodGet)
+
+	// Usage and spend
+	api.HandleFunc("/usage", docHandler.GetUsage).Methods(http.MethodGet)
+
+	// Cache metrics
+	api.HandleFunc("/metrics", docHandler.GetMetrics).Methods(http.MethodGet)
+
+	// Extraction schemas
+	api.HandleFunc("/schemas", docHandler.ListSchemas).Methods(http.MethodGet)
+	api.HandleFunc("/schemas", docHandler.CreateSchema).Methods(http.MethodPost)
+	api.HandleFunc("/schemas/{name}", docHandler.GetSchema).Methods(http.MethodGet)
+
+	// Entity search across documents
+	api.HandleFunc("/entities", docHandler.SearchEntities).Methods(http.MethodGet)
 
 	// Document endpoints
+	api.HandleFunc("/documents", docHandler.ListDocuments).Methods(http.MethodGet)
 	api.HandleFunc("/documents/upload", docHandler.UploadDocument).Methods(http.MethodPost)
+	api.HandleFunc("/documents/compare", docHandler.CompareDocuments).Methods(http.MethodPost)
+	api.HandleFunc("/documents/search/semantic", docHandler.SemanticSearch).Methods(http.MethodGet)
+	api.HandleFunc("/documents/review-queue", docHandler.ReviewQueue).Methods(http.MethodGet)
 	api.HandleFunc("/documents/{id}/analyze", docHandler.AnalyzeDocument).Methods(http.MethodPost)
+	api.HandleFunc("/documents/{id}/analyze/stream", docHandler.AnalyzeDocumentStream).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/analyses", docHandler.ListAnalyses).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/analysis", docHandler.CorrectAnalysis).Methods(http.MethodPatch)
+	api.HandleFunc("/documents/{id}/corrections", docHandler.ListCorrections).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/ask", docHandler.AskDocument).Methods(http.MethodPost)
+	api.HandleFunc("/documents/{id}/extract", docHandler.ExtractDocument).Methods(http.MethodPost)
+	api.HandleFunc("/documents/{id}/extractions", docHandler.ListExtractions).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/redactions", docHandler.ListRedactions).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/entities", docHandler.ListEntities).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/structure", docHandler.GetDocumentStructure).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/similar", docHandler.SimilarDocuments).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/translate", docHandler.TranslateDocument).Methods(http.MethodPost)
+	api.HandleFunc("/documents/{id}/versions", docHandler.ListTextVersions).Methods(http.MethodGet)
+	api.HandleFunc("/documents/{id}/versions/{versionId}/download", docHandler.DownloadTextVersion).Methods(http.MethodGet)
 	api.HandleFunc("/documents/{id}", docHandler.GetDocument).Methods(http.MethodGet)
 
 	return r
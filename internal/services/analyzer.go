@@ -0,0 +1,82 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/config"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/repository"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// newAnalyzer builds the model chain: the configured OpenRouter model first,
+// then each fallback model in order. OpenRouter models are skipped without
+// an API key, and the builtin analyzer is used when nothing else is left.
+// Analyses by a model are cached in the repository when the cache is on.
+func newAnalyzer(cfg *config.Config, repo repository.Repository, logger *utils.Logger) analyzer.Analyzer {
+	prompts, err := analyzer.LoadPrompts(cfg.PromptsDir)
+	if err != nil {
+		logger.Fatal("Failed to load prompt templates", "error", err, "dir", cfg.PromptsDir)
+	}
+	for _, docType := range prompts.Types() {
+		if !cfg.Taxonomy.Has(docType) {
+			logger.Warn("Prompt template is for a type missing from DOCUMENT_TYPES and will not be used", "type", docType)
+		}
+	}
+
+	chain := append([]config.ModelRef{{Provider: "openrouter", Model: cfg.OpenRouterModel}}, cfg.LLMFallbackModels...)
+
+	analyzers := make([]analyzer.Analyzer, 0, len(chain))
+	var names []string
+	for _, ref := range chain {
+		switch ref.Provider {
+		case "openrouter":
+			if cfg.OpenRouterAPIKey == "" {
+				logger.Warn("OPENROUTER_API_KEY is not set, skipping model", "model", ref.Model)
+				continue
+			}
+			analyzers = append(analyzers, analyzer.NewOpenRouterAnalyzer(analyzer.OpenRouterConfig{
+				APIKey:  cfg.OpenRouterAPIKey,
+				Model:   ref.Model,
+				BaseURL: cfg.OpenRouterBaseURL,
+				Retry: analyzer.RetryPolicy{
+					MaxRetries: cfg.LLMMaxRetries,
+					BaseDelay:  cfg.LLMRetryBaseDelay,
+					MaxDelay:   cfg.LLMRetryMaxDelay,
+				},
+				BreakerThreshold: cfg.LLMBreakerThreshold,
+				BreakerCooldown:  cfg.LLMBreakerCooldown,
+				Prompts:          prompts,
+				Taxonomy:         cfg.Taxonomy,
+			}, logger))
+			names = append(names, ref.Provider+":"+ref.Model)
+		case "builtin":
+			analyzers = append(analyzers, analyzer.NewBuiltinAnalyzer())
+			names = append(names, ref.Provider)
+		default:
+			logger.Fatal("Unsupported LLM provider", "provider", ref.Provider, "model", ref.Model)
+		}
+	}
+
+	if len(analyzers) == 0 {
+		logger.Warn("No LLM provider configured, using the builtin offline analyzer")
+		analyzers = append(analyzers, analyzer.NewBuiltinAnalyzer())
+	}
+
+	chained := analyzer.NewFallbackAnalyzer(analyzers, logger)
+	if analyzer.IsOffline(chained) || cfg.AnalysisCacheTTL <= 0 ||
+		cfg.AnalysisCacheMaxEntries <= 0 || cfg.AnalysisCacheMaxBytes <= 0 {
+		return chained
+	}
+
+	// The document types are part of the classification prompt, so a new
+	// taxonomy invalidates cached results like a new template does
+	return analyzer.NewCachingAnalyzer(chained, analyzer.CacheConfig{
+		Store:         repo,
+		Model:         strings.Join(names, ","),
+		PromptVersion: prompts.AnalysisVersion() + ";types=" + strings.Join(cfg.Taxonomy.Types(), ","),
+		TTL:           cfg.AnalysisCacheTTL,
+		MaxEntries:    cfg.AnalysisCacheMaxEntries,
+		MaxBytes:      cfg.AnalysisCacheMaxBytes,
+	}, logger)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+const (
+	// askChunkSize is the size in bytes of the passages questions are
+	// answered from
+	askChunkSize = 1000
+	// DefaultAskTopK and MaxAskTopK bound how many passages are sent
+	DefaultAskTopK = 4
+	MaxAskTopK     = 10
+	// maxQuestionLength bounds the question in characters
+	maxQuestionLength = 1000
+)
+
+func (s *documentService) AskDocument(ctx context.Context, id string, req *models.AskRequest) (*models.AskResponse, error) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, utils.NewBadRequestError("question is required")
+	}
+	if utf8.RuneCountInString(question) > maxQuestionLength {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("question must be at most %d characters", maxQuestionLength))
+	}
+
+	topK := req.TopK
+	if topK == 0 {
+		topK = DefaultAskTopK
+	}
+	if topK < 1 || topK > MaxAskTopK {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("top_k must be between 1 and %d", MaxAskTopK))
+	}
+
+	policy, err := s.redactPolicy(req.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	chunks := retrieval.Split(doc.ExtractedText, askChunkSize)
+	selected := selectPassages(chunks, question, topK)
+
+	// The question and passages share placeholders, so a question about an
+	// email address still matches the passage that holds it
+	redactor := redact.New(policy)
+	passages := make([]models.Passage, len(selected))
+	for i, chunk := range selected {
+		passages[i] = models.Passage{Number: i + 1, Text: redactor.Redact(chunk.Text)}
+	}
+	redactedQuestion := redactor.Redact(question)
+	if err := s.recordRedaction(ctx, id, "ask", policy, redactor); err != nil {
+		return nil, err
+	}
+
+	result, err := s.analyzer.Answer(ctx, redactedQuestion, passages)
+	if err != nil {
+		s.logger.Error("Failed to answer question", "error", err, "id", id)
+		if errors.Is(err, analyzer.ErrCircuitOpen) {
+			return nil, utils.NewServiceUnavailableError("LLM provider is temporarily unavailable, try again later")
+		}
+		return nil, utils.NewInternalError("Failed to answer question with LLM")
+	}
+
+	result.Answer = redactor.Restore(result.Answer)
+
+	citations := []models.Citation{}
+	for _, quote := range result.Quotes {
+		quote.Text = redactor.Restore(quote.Text)
+
+		var within retrieval.Chunk
+		if quote.Passage >= 1 && quote.Passage <= len(selected) {
+			within = selected[quote.Passage-1]
+		}
+
+		// Quotes that are not in the document are dropped rather than
+		// returned as if they were supporting evidence
+		start, end, ok := retrieval.Locate(doc.ExtractedText, quote.Text, within)
+		if !ok {
+			s.logger.Warn("Dropping quote not found in document", "id", id, "quote", quote.Text)
+			continue
+		}
+
+		citations = append(citations, models.Citation{
+			Text:  doc.ExtractedText[start:end],
+			Start: utf8.RuneCountInString(doc.ExtractedText[:start]),
+			End:   utf8.RuneCountInString(doc.ExtractedText[:end]),
+		})
+	}
+
+	cost := s.recordLLMCall(ctx, &models.LLMCall{
+		DocumentID:    id,
+		Operation:     models.OperationAsk,
+		Model:         result.Model,
+		PromptVersion: result.PromptVersion,
+	}, result.Usage)
+
+	s.logger.Info("Question answered",
+		"id", id,
+		"passages", len(passages),
+		"citations", len(citations),
+		"found", result.Found,
+		"model", result.Model)
+
+	return &models.AskResponse{
+		DocumentID:    id,
+		Question:      question,
+		Answer:        result.Answer,
+		Found:         result.Found,
+		Citations:     citations,
+		Model:         result.Model,
+		PromptVersion: result.PromptVersion,
+		Usage:         result.Usage,
+		CostUSD:       cost,
+		Redacted:      redactor.Counts(),
+	}, nil
+}
+
+// selectPassages ranks chunks against the question with BM25 and returns
+// the best topK in document order. When no chunk shares a keyword with the
+// question the opening chunks are used, so paraphrased questions still get
+// some context.
+func selectPassages(chunks []retrieval.Chunk, question string, topK int) []retrieval.Chunk {
+	if len(chunks) <= topK {
+		return chunks
+	}
+
+	results := retrieval.NewIndex(chunks).Search(question, topK)
+	if len(results) == 0 {
+		return chunks[:topK]
+	}
+
+	selected := make([]retrieval.Chunk, len(results))
+	for i, result := range results {
+		selected[i] = result.Chunk
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Index < selected[j].Index
+	})
+
+	return selected
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/diff"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// CompareDocuments diffs the extracted text of two documents and has the
+// analyzer summarize the material differences. Only the differing
+// paragraphs are sent, so long documents with few changes stay cheap.
+func (s *documentService) CompareDocuments(ctx context.Context, req *models.CompareRequest) (*models.CompareResponse, error) {
+	originalID, revisedID := strings.TrimSpace(req.OriginalID), strings.TrimSpace(req.RevisedID)
+	if originalID == "" || revisedID == "" {
+		return nil, utils.NewBadRequestError("original_id and revised_id are required")
+	}
+	if originalID == revisedID {
+		return nil, utils.NewBadRequestError("original_id and revised_id must be different documents")
+	}
+
+	policy, err := s.redactPolicy(req.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs [2]*models.Document
+	for i, id := range []string{originalID, revisedID} {
+		doc, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to get document", "error", err, "id", id)
+			return nil, utils.NewInternalError("Failed to retrieve document")
+		}
+		if doc == nil {
+			return nil, utils.NewNotFoundError("Document not found: " + id)
+		}
+		docs[i] = doc
+	}
+
+	blocks := diff.Compare(docs[0].ExtractedText, docs[1].ExtractedText)
+	stats := diff.Count(blocks)
+	resp := &models.CompareResponse{
+		OriginalID: originalID,
+		RevisedID:  revisedID,
+		Identical:  diff.Identical(blocks),
+		Summary:    "The documents have the same text.",
+		Changes:    []models.MaterialChange{},
+		Stats: models.DiffStats{
+			Unchanged: stats.Unchanged,
+			Added:     stats.Added,
+			Removed:   stats.Removed,
+			Changed:   stats.Changed,
+			Moved:     stats.Moved,
+		},
+		Diff: diffBlocks(blocks, nil),
+	}
+	if resp.Identical {
+		return resp, nil
+	}
+
+	// Both versions share one redactor, so a value that appears in both
+	// gets the same placeholder and is not reported as a change
+	redactor := redact.New(policy)
+	redacted := diffBlocks(blocks, redactor)
+	for _, id := range []string{originalID, revisedID} {
+		if err := s.recordRedaction(ctx, id, "compare", policy, redactor); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.analyzer.Compare(ctx, redacted)
+	if err != nil {
+		s.logger.Error("Failed to compare documents", "error", err, "original_id", originalID, "revised_id", revisedID)
+		if errors.Is(err, analyzer.ErrCircuitOpen) {
+			return nil, utils.NewServiceUnavailableError("LLM provider is temporarily unavailable, try again later")
+		}
+		return nil, utils.NewInternalError("Failed to summarize differences with LLM")
+	}
+
+	resp.Summary = redactor.Restore(result.Summary)
+	for _, change := range result.Changes {
+		change.Description = redactor.Restore(change.Description)
+		resp.Changes = append(resp.Changes, change)
+	}
+	resp.Model = result.Model
+	resp.PromptVersion = result.PromptVersion
+	resp.Usage = &result.Usage
+	resp.CostUSD = s.recordLLMCall(ctx, &models.LLMCall{
+		DocumentID:        originalID,
+		RelatedDocumentID: revisedID,
+		Operation:         models.OperationCompare,
+		Model:             result.Model,
+		PromptVersion:     result.PromptVersion,
+	}, result.Usage)
+	resp.Redacted = redactor.Counts()
+
+	s.logger.Info("Documents compared",
+		"original_id", originalID,
+		"revised_id", revisedID,
+		"changed", stats.Changed,
+		"added", stats.Added,
+		"removed", stats.Removed,
+		"moved", stats.Moved,
+		"material_changes", len(resp.Changes),
+		"model", result.Model)
+
+	return resp, nil
+}
+
+// diffBlocks converts a diff for the response, masking paragraphs with
+// redactor when it is not nil
+func diffBlocks(blocks []diff.Block, redactor *redact.Redactor) []models.DiffBlock {
+	converted := make([]models.DiffBlock, len(blocks))
+	for i, block := range blocks {
+		b := models.DiffBlock{Op: string(block.Op), Old: block.Old, New: block.New}
+		if redactor != nil {
+			b.Old, b.New = redactor.Redact(block.Old), redactor.Redact(block.New)
+		}
+		if block.OldIndex >= 0 {
+			b.OldIndex = &block.OldIndex
+		}
+		if block.NewIndex >= 0 {
+			b.NewIndex = &block.NewIndex
+		}
+		converted[i] = b
+	}
+	return converted
+}
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,24 +18,65 @@ import (
 	"github.com/BerylCAtieno/document-summarizer-api/internal/storage"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/extractor"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/language"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/config"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/embedding"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
 	"github.com/BerylCAtieno/document-summarizer-api/internal/repository"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
 )
 
 type DocumentService interface {
 	UploadDocument(ctx context.Context, req *models.UploadRequest) (*models.UploadResponse, error)
-	AnalyzeDocument(ctx context.Context, id string) (*models.AnalysisResponse, error)
+	AnalyzeDocument(ctx context.Context, id string, req *models.AnalyzeRequest) (*models.AnalysisResponse, error)
+	AnalyzeDocumentStream(ctx context.Context, id string, req *models.AnalyzeRequest, sink analyzer.TokenSink) (*models.AnalysisResponse, error)
 	GetDocument(ctx context.Context, id string) (*models.Document, error)
+	ListDocuments(ctx context.Context, filter models.DocumentFilter) (*models.DocumentListResponse, error)
+	ListAnalyses(ctx context.Context, id string) (*models.AnalysisListResponse, error)
+	GetDocumentStructure(ctx context.Context, id string) (*models.StructureResponse, error)
+	GetUsage(ctx context.Context, filter models.UsageFilter) (*models.UsageResponse, error)
+	CreateSchema(ctx context.Context, req *models.CreateSchemaRequest) (*models.ExtractionSchema, error)
+	GetSchema(ctx context.Context, name string) (*models.ExtractionSchema, error)
+	ListSchemas(ctx context.Context) (*models.SchemaListResponse, error)
+	ExtractDocument(ctx context.Context, id, schemaName, redactPolicy string) (*models.Extraction, error)
+	ListExtractions(ctx context.Context, id string) (*models.ExtractionListResponse, error)
+	ListRedactions(ctx context.Context, id string) (*models.RedactionListResponse, error)
+	ListEntities(ctx context.Context, id string) (*models.EntityListResponse, error)
+	SearchEntities(ctx context.Context, filter models.EntityFilter) (*models.EntitySearchResponse, error)
+	CompareDocuments(ctx context.Context, req *models.CompareRequest) (*models.CompareResponse, error)
+	AskDocument(ctx context.Context, id string, req *models.AskRequest) (*models.AskResponse, error)
+	TranslateDocument(ctx context.Context, id string, req *models.TranslateRequest) (*models.TranslateResponse, error)
+	ListTextVersions(ctx context.Context, id string) (*models.TextVersionListResponse, error)
+	DownloadTextVersion(ctx context.Context, id, versionID, format string) (*models.FileDownload, error)
+	SemanticSearch(ctx context.Context, query string, limit, offset int) (*models.SemanticSearchResponse, error)
+	SimilarDocuments(ctx context.Context, id string, limit, offset int) (*models.SimilarDocumentsResponse, error)
+	Health(ctx context.Context) *models.HealthResponse
+	Metrics(ctx context.Context) (*models.MetricsResponse, error)
+	CorrectAnalysis(ctx context.Context, id string, req *models.CorrectionRequest) (*models.CorrectionResponse, error)
+	ListCorrections(ctx context.Context, id string) (*models.CorrectionListResponse, error)
+	ReviewQueue(ctx context.Context, limit, offset int) (*models.ReviewQueueResponse, error)
 }
 
 type documentService struct {
-	repo     repository.Repository
-	storage  storage.Storage
-	analyzer analyzer.Analyzer
-	logger   *utils.Logger
+	repo       repository.Repository
+	storage    storage.Storage
+	analyzer   analyzer.Analyzer
+	normalizer *extractor.Normalizer
+	prices     map[string]config.ModelPrice
+	redaction  redact.Policy
+	taxonomy   *taxonomy.Taxonomy
+	// reviewBelow is the classification confidence under which a document
+	// needs human review
+	reviewBelow float64
+	// extractEntities runs entity extraction after each analysis
+	extractEntities bool
+	embedder        embedding.Embedder
+	// vectors holds the chunk vectors of every document made by embedder
+	vectors embedding.Index
+	logger  *utils.Logger
 }
 
 func NewService(repo repository.Repository, cfg *config.Config, logger *utils.Logger) DocumentService {
@@ -38,30 +85,86 @@ func NewService(repo repository.Repository, cfg *config.Config, logger *utils.Lo
 		logger.Fatal("Failed to initialize S3 storage", "error", err)
 	}
 
-	llmAnalyzer := analyzer.NewOpenRouterAnalyzer(cfg.OpenRouterAPIKey, cfg.OpenRouterModel, logger)
+	llmAnalyzer := newAnalyzer(cfg, repo, logger)
+
+	normalizer := extractor.NewNormalizer(extractor.NormalizeOptions{
+		UnicodeNFC:        cfg.NormalizeUnicode,
+		ExpandLigatures:   cfg.NormalizeLigatures,
+		Dehyphenate:       cfg.NormalizeDehyphenate,
+		RemoveHeaders:     cfg.NormalizeHeaders,
+		StripControlChars: cfg.NormalizeControlChars,
+		CleanWhitespace:   cfg.NormalizeWhitespace,
+	})
+
+	s := &documentService{
+		repo:            repo,
+		storage:         s3Storage,
+		analyzer:        llmAnalyzer,
+		normalizer:      normalizer,
+		prices:          cfg.LLMPrices,
+		redaction:       cfg.RedactPolicy,
+		taxonomy:        cfg.Taxonomy,
+		reviewBelow:     cfg.ReviewConfidence,
+		extractEntities: cfg.ExtractEntities,
+		embedder:        newEmbedder(cfg),
+		vectors:         newVectorIndex(cfg),
+		logger:          logger,
+	}
 
-	return &documentService{
-		repo:     repo,
-		storage:  s3Storage,
-		analyzer: llmAnalyzer,
-		logger:   logger,
+	if err := s.loadVectors(context.Background()); err != nil {
+		logger.Fatal("Failed to load embeddings", "error", err)
 	}
+	go s.backfillEmbeddings(context.Background())
+
+	return s
 }
 
 func (s *documentService) UploadDocument(ctx context.Context, req *models.UploadRequest) (*models.UploadResponse, error) {
+	contentHash, err := hashContent(req.File, req.Size)
+	if err != nil {
+		s.logger.Error("Failed to hash document", "error", err, "filename", req.Filename)
+		return nil, utils.NewInternalError("Failed to read document")
+	}
+
+	// Re-uploads of identical content reuse the stored file, text and any cached analysis
+	if req.Dedupe {
+		existing, err := s.repo.GetByContentHash(ctx, contentHash)
+		if err != nil {
+			s.logger.Error("Failed to look up document by hash", "error", err, "content_hash", contentHash)
+			return nil, utils.NewInternalError("Failed to check for duplicate documents")
+		}
+		if existing != nil {
+			s.logger.Info("Duplicate upload, returning existing document",
+				"id", existing.ID,
+				"filename", req.Filename,
+				"content_hash", contentHash)
+
+			return &models.UploadResponse{
+				ID:          existing.ID,
+				Filename:    existing.Filename,
+				FileSize:    existing.FileSize,
+				ContentType: existing.ContentType,
+				Language:    stringValue(existing.Language),
+				Duplicate:   true,
+				CreatedAt:   existing.CreatedAt,
+				Message:     "An identical document was already uploaded; returning the existing document. Upload with dedupe=false to store a separate copy.",
+			}, nil
+		}
+	}
+
 	docID := utils.GenerateID()
 
 	var extractedText string
-	var err error
+	var pages []string
 
 	// Normalize content type and extract text
 	switch {
 	case req.ContentType == "application/pdf":
-		extractedText, err = extractor.ExtractPDF(req.File)
+		pages, err = extractor.ExtractPDFPagesReader(req.File, req.Size)
 	case isDOCXContentType(req.ContentType):
-		extractedText, err = extractor.ExtractDOCX(req.File)
+		extractedText, err = extractor.ExtractDOCXReader(req.File, req.Size)
 	case isTXTContentType(req.ContentType):
-		extractedText, err = extractor.ExtractTXT(req.File)
+		extractedText, err = extractor.ExtractTXTReader(req.File, req.Size)
 	default:
 		s.logger.Warn("Unsupported content type", "content_type", req.ContentType, "filename", req.Filename)
 		return nil, utils.NewBadRequestError(fmt.Sprintf("Unsupported file type '%s'. Only PDF and DOCX files are allowed", req.ContentType))
@@ -72,14 +175,34 @@ func (s *documentService) UploadDocument(ctx context.Context, req *models.Upload
 		return nil, utils.NewInternalError(fmt.Sprintf("Failed to extract text from document: %v", err))
 	}
 
+	// PDF pages are normalized together so repeated headers and footers can be found
+	if pages == nil {
+		pages = []string{extractedText}
+	}
+	extractedText = s.normalizer.NormalizePages(pages)
+
 	// Validate extracted text is not empty
 	if strings.TrimSpace(extractedText) == "" {
 		s.logger.Warn("No text extracted from document", "filename", req.Filename)
 		return nil, utils.NewBadRequestError("No text could be extracted from the document. The file may be empty or corrupted")
 	}
 
+	var docLanguage *string
+	if code, _ := language.Detect(extractedText); code != "" {
+		docLanguage = &code
+	}
+
+	// Structure is best effort; the plain text alone is enough to analyze
+	structure, err := extractStructure(req.ContentType, req.File, req.Size)
+	if err != nil {
+		s.logger.Warn("Failed to extract document structure", "error", err, "filename", req.Filename)
+		structure = &models.DocumentStructure{Error: err.Error()}
+	}
+	s.normalizer.NormalizeStructure(structure)
+
 	s3Key := fmt.Sprintf("documents/%s/%s", docID, req.Filename)
-	if err := s.storage.Upload(ctx, s3Key, req.File, req.ContentType); err != nil {
+	body := io.NewSectionReader(req.File, 0, req.Size)
+	if err := s.storage.Upload(ctx, s3Key, body, req.Size, req.ContentType); err != nil {
 		s.logger.Error("Failed to upload to S3", "error", err, "s3_key", s3Key)
 		return nil, utils.NewInternalError("Failed to store document")
 	}
@@ -88,10 +211,13 @@ func (s *documentService) UploadDocument(ctx context.Context, req *models.Upload
 	doc := &models.Document{
 		ID:            docID,
 		Filename:      req.Filename,
-		FileSize:      int64(len(req.File)),
+		FileSize:      req.Size,
 		ContentType:   normalizeContentType(req.ContentType),
 		S3Key:         s3Key,
+		ContentHash:   contentHash,
 		ExtractedText: extractedText,
+		Language:      docLanguage,
+		Structure:     structure,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -109,17 +235,52 @@ func (s *documentService) UploadDocument(ctx context.Context, req *models.Upload
 		"content_type", req.ContentType,
 		"text_length", len(extractedText))
 
+	// Semantic search is an extra, so the upload does not wait on the
+	// embedding provider. A document that fails to embed is embedded again
+	// at the next start.
+	go func() {
+		if _, err := s.embedDocument(context.Background(), doc); err != nil {
+			s.logger.Warn("Failed to embed document", "error", err, "id", docID)
+		}
+	}()
+
 	return &models.UploadResponse{
 		ID:          docID,
 		Filename:    req.Filename,
 		FileSize:    doc.FileSize,
 		ContentType: doc.ContentType,
+		Language:    stringValue(doc.Language),
 		CreatedAt:   now,
 		Message:     "Document uploaded successfully. Use /documents/{id}/analyze to analyze it.",
 	}, nil
 }
 
-func (s *documentService) AnalyzeDocument(ctx context.Context, id string) (*models.AnalysisResponse, error) {
+func (s *documentService) AnalyzeDocument(ctx context.Context, id string, req *models.AnalyzeRequest) (*models.AnalysisResponse, error) {
+	return s.analyzeDocument(ctx, id, req, nil)
+}
+
+// AnalyzeDocumentStream analyzes a document like AnalyzeDocument, passing
+// model output to sink as it is generated. Cached results are returned
+// without calling sink.
+func (s *documentService) AnalyzeDocumentStream(ctx context.Context, id string, req *models.AnalyzeRequest, sink analyzer.TokenSink) (*models.AnalysisResponse, error) {
+	return s.analyzeDocument(ctx, id, req, sink)
+}
+
+func (s *documentService) analyzeDocument(ctx context.Context, id string, req *models.AnalyzeRequest, sink analyzer.TokenSink) (*models.AnalysisResponse, error) {
+	if req.TargetLanguage != "" && !language.IsSupported(req.TargetLanguage) {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unsupported target language '%s'", req.TargetLanguage))
+	}
+
+	style, length, err := summaryVariant(req.Style, req.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.redactPolicy(req.Redact)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get document from database
 	doc, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -130,43 +291,225 @@ func (s *documentService) AnalyzeDocument(ctx context.Context, id string) (*mode
 		return nil, utils.NewNotFoundError("Document not found")
 	}
 
-	// Check if already analyzed
-	if doc.AnalyzedAt != nil {
-		s.logger.Info("Document already analyzed, returning cached results", "id", id)
+	sourceLanguage := stringValue(doc.Language)
+	if sourceLanguage == "" {
+		// Documents uploaded before language detection was added
+		sourceLanguage, _ = language.Detect(doc.ExtractedText)
+	}
+
+	// Instruction-like text is sent like any other document text, since
+	// the prompt keeps it apart from the instructions, but the result is
+	// flagged so it can be reviewed
+	signals := analyzer.DetectInjection(doc.ExtractedText)
+
+	summaryLanguage := req.TargetLanguage
+	if summaryLanguage == "" {
+		summaryLanguage = sourceLanguage
+	}
+
+	// Each style and length is cached separately; a summary in another
+	// language needs a new run
+	var cached *models.Summary
+	if !req.Force && doc.AnalyzedAt != nil {
+		if cached, err = s.repo.GetSummary(ctx, id, style, length); err != nil {
+			s.logger.Error("Failed to get summary", "error", err, "id", id)
+			return nil, utils.NewInternalError("Failed to retrieve document")
+		}
+	}
+	if cached != nil && (req.TargetLanguage == "" || req.TargetLanguage == cached.SummaryLanguage) {
+		s.logger.Info("Document already analyzed, returning cached results", "id", id, "style", style, "length", length)
 		return &models.AnalysisResponse{
-			ID:           doc.ID,
-			Summary:      *doc.Summary,
-			DocumentType: *doc.DocumentType,
-			Metadata:     doc.Metadata,
-			AnalyzedAt:   *doc.AnalyzedAt,
+			ID:               doc.ID,
+			Summary:          cached.Summary,
+			SummaryLanguage:  cached.SummaryLanguage,
+			SummaryStyle:     style,
+			SummaryLength:    length,
+			DocumentType:     *doc.DocumentType,
+			Metadata:         doc.Metadata,
+			TypeConfidence:   doc.TypeConfidence,
+			TypeAlternatives: doc.TypeAlternatives,
+			NeedsReview:      s.needsReview(doc.TypeConfidence, doc.VerifiedFields),
+			Suspicious:       len(signals) > 0,
+			InjectionSignals: signals,
+			AnalyzedAt:       *doc.AnalyzedAt,
 		}, nil
 	}
 
 	// Analyze with LLM
-	s.logger.Info("Starting document analysis", "id", id, "text_length", len(doc.ExtractedText))
-	result, err := s.analyzer.Analyze(ctx, doc.ExtractedText)
+	s.logger.Info("Starting document analysis",
+		"id", id,
+		"text_length", len(doc.ExtractedText),
+		"summary_language", summaryLanguage,
+		"style", style,
+		"length", length,
+		"force", req.Force)
+
+	opts := analyzer.Options{
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: req.TargetLanguage,
+		Style:          style,
+		Length:         length,
+		NoCache:        req.Force,
+	}
+
+	// Personal data is masked before the text leaves the server and put
+	// back into the result afterwards
+	redactor := redact.New(policy)
+	text := redactor.Redact(doc.ExtractedText)
+	if err := s.recordRedaction(ctx, id, "analyze", policy, redactor); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var result *models.LLMAnalysisResult
+	if streamer, ok := s.analyzer.(analyzer.StreamAnalyzer); ok && sink != nil {
+		result, err = streamer.AnalyzeStream(ctx, text, opts, sink)
+	} else {
+		result, err = s.analyzer.Analyze(ctx, text, opts)
+	}
+	latency := time.Since(start)
 	if err != nil {
 		s.logger.Error("Failed to analyze document", "error", err, "id", id)
+		s.recordFailedUsage(ctx, id, models.OperationAnalyze, err)
+		if errors.Is(err, analyzer.ErrCircuitOpen) {
+			return nil, utils.NewServiceUnavailableError("LLM provider is temporarily unavailable, try again later")
+		}
+		if errors.Is(err, analyzer.ErrTranslationUnsupported) {
+			return nil, utils.NewBadRequestError("Summaries in another language need an LLM provider; set OPENROUTER_API_KEY")
+		}
 		return nil, utils.NewInternalError("Failed to analyze document with LLM")
 	}
 
+	result.Summary = redactor.Restore(result.Summary)
+	result.Metadata = redactor.RestoreValue(result.Metadata).(map[string]interface{})
+
+	if len(signals) > 0 {
+		s.logger.Warn("Document contains instruction-like text, analysis flagged as suspicious",
+			"id", id,
+			"signals", signals)
+	}
+
+	// Every analyzer's type is mapped onto the taxonomy, so document_type
+	// only ever holds allowed values
+	class, alternatives := analyzer.MapClassification(s.taxonomy,
+		models.TypeScore{DocumentType: result.DocumentType, Confidence: result.Confidence},
+		result.Alternatives)
+	if class.DocumentType != result.DocumentType {
+		s.logger.Info("Mapped document type onto the taxonomy",
+			"id", id,
+			"label", result.DocumentType,
+			"type", class.DocumentType)
+	}
+	result.DocumentType = class.DocumentType
+
+	// Fields a reviewer corrected or confirmed keep their values
+	keepVerified(doc, result)
+	if s.needsReview(&class.Confidence, doc.VerifiedFields) {
+		s.logger.Info("Document classification needs review",
+			"id", id,
+			"type", class.DocumentType,
+			"confidence", class.Confidence)
+	}
+
+	// Entities are extracted from the same redacted text, and their tokens
+	// are billed with the analysis. A failure leaves the previous entities
+	// in place rather than failing the analysis.
+	var entities []*models.Entity
+	if s.extractEntities {
+		var usage models.TokenUsage
+		if entities, usage, err = s.findEntities(ctx, doc, text, redactor); err != nil {
+			s.logger.Error("Failed to extract entities", "error", err, "id", id)
+		}
+		result.Usage.Add(usage)
+	}
+
+	analysis := &models.Analysis{
+		ID:               utils.GenerateID(),
+		DocumentID:       id,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		Summary:          result.Summary,
+		SummaryLanguage:  summaryLanguage,
+		SummaryStyle:     style,
+		SummaryLength:    length,
+		DocumentType:     result.DocumentType,
+		Metadata:         result.Metadata,
+		LatencyMS:        latency.Milliseconds(),
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		CostUSD:          s.analysisCost(result.Model, result.Usage),
+		TypeConfidence:   &class.Confidence,
+		TypeAlternatives: alternatives,
+		Suspicious:       len(signals) > 0,
+		InjectionSignals: signals,
+		CreatedAt:        time.Now(),
+	}
+
 	// Update database with analysis results
-	if err := s.repo.UpdateAnalysis(ctx, id, result.Summary, result.DocumentType, result.Metadata); err != nil {
-		s.logger.Error("Failed to update analysis", "error", err, "id", id)
-		return nil, utils.NewInternalError("Failed to save analysis results")
+	if doc, err = s.saveAnalysis(ctx, doc, result, analysis); err != nil {
+		return nil, err
+	}
+
+	// The document already holds the result, so a lost history entry is not fatal
+	if err := s.repo.CreateAnalysis(ctx, analysis); err != nil {
+		s.logger.Error("Failed to record analysis history", "error", err, "id", id)
+	}
+
+	summary := &models.Summary{
+		ID:              utils.GenerateID(),
+		DocumentID:      id,
+		Style:           style,
+		Length:          length,
+		Summary:         analysis.Summary,
+		SummaryLanguage: summaryLanguage,
+		AnalysisID:      analysis.ID,
+		Model:           analysis.Model,
+		PromptVersion:   analysis.PromptVersion,
+		CreatedAt:       analysis.CreatedAt,
+	}
+	if err := s.repo.UpsertSummary(ctx, summary); err != nil {
+		s.logger.Error("Failed to cache summary", "error", err, "id", id, "style", style, "length", length)
+	}
+
+	if entities != nil {
+		if err := s.repo.ReplaceEntities(ctx, id, entities); err != nil {
+			s.logger.Error("Failed to save entities", "error", err, "id", id)
+		}
 	}
 
 	s.logger.Info("Document analyzed successfully",
 		"id", id,
 		"type", result.DocumentType,
-		"summary_length", len(result.Summary))
+		"type_confidence", class.Confidence,
+		"model", result.Model,
+		"cache_hit", result.CacheHit,
+		"latency", latency,
+		"prompt_tokens", result.Usage.PromptTokens,
+		"completion_tokens", result.Usage.CompletionTokens,
+		"summary_length", len(result.Summary),
+		"entities", len(entities))
 
 	return &models.AnalysisResponse{
-		ID:           id,
-		Summary:      result.Summary,
-		DocumentType: result.DocumentType,
-		Metadata:     result.Metadata,
-		AnalyzedAt:   time.Now(),
+		ID:               id,
+		Summary:          result.Summary,
+		SummaryLanguage:  summaryLanguage,
+		SummaryStyle:     style,
+		SummaryLength:    length,
+		DocumentType:     result.DocumentType,
+		Metadata:         result.Metadata,
+		AnalysisID:       analysis.ID,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		Usage:            &result.Usage,
+		CostUSD:          analysis.CostUSD,
+		Redacted:         redactor.Counts(),
+		TypeConfidence:   analysis.TypeConfidence,
+		TypeAlternatives: alternatives,
+		NeedsReview:      s.needsReview(analysis.TypeConfidence, doc.VerifiedFields),
+		Suspicious:       analysis.Suspicious,
+		InjectionSignals: signals,
+		CacheHit:         result.CacheHit,
+		AnalyzedAt:       analysis.CreatedAt,
 	}, nil
 }
 
@@ -180,9 +523,135 @@ func (s *documentService) GetDocument(ctx context.Context, id string) (*models.D
 		return nil, utils.NewNotFoundError("Document not found")
 	}
 
+	summaries, err := s.repo.ListSummaries(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list summaries", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if len(summaries) > 0 {
+		doc.Summaries = make(map[string]*models.Summary, len(summaries))
+		for _, summary := range summaries {
+			doc.Summaries[models.SummaryKey(summary.Style, summary.Length)] = summary
+		}
+	}
+
 	return doc, nil
 }
 
+func (s *documentService) ListAnalyses(ctx context.Context, id string) (*models.AnalysisListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	analyses, err := s.repo.ListAnalyses(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list analyses", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve analyses")
+	}
+
+	return &models.AnalysisListResponse{
+		DocumentID: id,
+		Analyses:   analyses,
+	}, nil
+}
+
+func (s *documentService) ListDocuments(ctx context.Context, filter models.DocumentFilter) (*models.DocumentListResponse, error) {
+	// Documents only have the languages detection knows, so filtering by
+	// any other target language would always find nothing
+	if filter.Language != "" && !language.IsDetectable(filter.Language) {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unsupported language '%s'; detected languages are %s",
+			filter.Language, strings.Join(language.Detectable(), ", ")))
+	}
+
+	if filter.DocumentType != "" {
+		docType, ok := s.taxonomy.Map(filter.DocumentType)
+		if !ok {
+			return nil, utils.NewBadRequestError(fmt.Sprintf("Unknown document type '%s'; use one of %s",
+				filter.DocumentType, strings.Join(s.taxonomy.Types(), ", ")))
+		}
+		filter.DocumentType = docType
+	}
+	filter.ReviewBelow = s.reviewBelow
+
+	docs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list documents", "error", err)
+		return nil, utils.NewInternalError("Failed to list documents")
+	}
+
+	return &models.DocumentListResponse{
+		Documents: docs,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}, nil
+}
+
+func (s *documentService) GetDocumentStructure(ctx context.Context, id string) (*models.StructureResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	structure, err := s.repo.GetStructure(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document structure", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document structure")
+	}
+
+	// Documents uploaded before structures were recorded are rebuilt from the stored file
+	if structure == nil {
+		data, err := s.storage.Download(ctx, doc.S3Key)
+		if err != nil {
+			s.logger.Error("Failed to download document", "error", err, "s3_key", doc.S3Key)
+			return nil, utils.NewInternalError("Failed to retrieve document file")
+		}
+
+		structure, err = extractStructure(doc.ContentType, bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			s.logger.Warn("Failed to extract document structure", "error", err, "id", id)
+			structure = &models.DocumentStructure{Error: err.Error()}
+		}
+		s.normalizer.NormalizeStructure(structure)
+
+		if err := s.repo.UpdateStructure(ctx, id, structure); err != nil {
+			s.logger.Warn("Failed to save document structure", "error", err, "id", id)
+		}
+	}
+
+	if structure.Error != "" {
+		return nil, utils.NewUnprocessableEntityError("The structure of this document could not be extracted")
+	}
+
+	return &models.StructureResponse{
+		ID:        doc.ID,
+		PageCount: structure.PageCount,
+		Blocks:    structure.Blocks,
+	}, nil
+}
+
+// extractStructure runs the structure extractor matching the content type
+func extractStructure(contentType string, r io.ReaderAt, size int64) (*models.DocumentStructure, error) {
+	switch {
+	case contentType == "application/pdf":
+		return extractor.ExtractPDFStructureReader(r, size)
+	case isDOCXContentType(contentType):
+		return extractor.ExtractDOCXStructureReader(r, size)
+	case isTXTContentType(contentType):
+		return extractor.ExtractTXTStructureReader(r, size)
+	default:
+		return nil, fmt.Errorf("unsupported content type '%s'", contentType)
+	}
+}
+
 // isDOCXContentType checks if the content type is a DOCX file
 // Handles various DOCX MIME type variations
 func isDOCXContentType(contentType string) bool {
@@ -221,6 +690,22 @@ func isTXTContentType(contentType string) bool {
 	return false
 }
 
+// hashContent returns the hex encoded SHA-256 of the uploaded file
+func hashContent(r io.ReaderAt, size int64) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, io.NewSectionReader(r, 0, size)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // normalizeContentType normalizes content type to standard MIME types
 func normalizeContentType(contentType string) string {
 	if isDOCXContentType(contentType) {
@@ -231,3 +716,10 @@ func normalizeContentType(contentType string) string {
 	}
 	return contentType
 }
+
+// needsReview reports whether a classification is too uncertain to rely on.
+// Documents analyzed before confidence was recorded have none and are not
+// flagged, nor are those whose type a reviewer verified.
+func (s *documentService) needsReview(confidence *float64, verified []string) bool {
+	return confidence != nil && *confidence < s.reviewBelow && !slices.Contains(verified, models.FieldDocumentType)
+}
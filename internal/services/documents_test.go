@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/db"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/embedding"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/extractor"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/repository"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/taxonomy"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// newTestService returns a service over a migrated database in a temporary
+// directory that analyzes with a
+func newTestService(t *testing.T, a analyzer.Analyzer) (*documentService, repository.Repository) {
+	t.Helper()
+	t.Chdir("../..")
+
+	path := t.TempDir() + "/test.db"
+	if err := db.RunMigrations(path); err != nil {
+		t.Fatalf("RunMigrations returned error: %v", err)
+	}
+	conn, err := db.NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB returned error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	repo := repository.NewRepository(conn)
+	return &documentService{
+		repo:       repo,
+		storage:    &memoryStorage{files: map[string][]byte{}},
+		analyzer:   a,
+		normalizer: extractor.NewNormalizer(extractor.DefaultNormalizeOptions()),
+		taxonomy:   taxonomy.Default(),
+		embedder:   embedding.NewHashingEmbedder(0),
+		vectors:    embedding.NewFlat(),
+		logger:     utils.NewLogger("error"),
+	}, repo
+}
+
+// memoryStorage keeps uploaded files in memory
+type memoryStorage struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	uploads int
+}
+
+func (m *memoryStorage) Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[key] = data
+	m.uploads++
+	return nil
+}
+
+func (m *memoryStorage) Download(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[key]
+	if !ok {
+		return nil, fmt.Errorf("no file %s", key)
+	}
+	return data, nil
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, key)
+	return nil
+}
+
+// stubAnalyzer returns result from Analyze after running during, which
+// stands in for whatever happens while the model runs
+type stubAnalyzer struct {
+	analyzer.Analyzer
+	result *models.LLMAnalysisResult
+	err    error
+	during func()
+}
+
+func (a *stubAnalyzer) Analyze(ctx context.Context, text string, opts analyzer.Options) (*models.LLMAnalysisResult, error) {
+	if a.during != nil {
+		a.during()
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	result := *a.result
+	result.Metadata = map[string]interface{}{}
+	for key, value := range a.result.Metadata {
+		result.Metadata[key] = value
+	}
+	return &result, nil
+}
+
+// TestAnalyzeKeepsCorrectionMadeDuringAnalysis checks a correction stored
+// while the model runs is not overwritten by the analysis
+func TestAnalyzeKeepsCorrectionMadeDuringAnalysis(t *testing.T) {
+	stub := &stubAnalyzer{
+		Analyzer: analyzer.NewBuiltinAnalyzer(),
+		result: &models.LLMAnalysisResult{
+			Summary:      "An invoice from ACME.",
+			DocumentType: "invoice",
+			Metadata:     map[string]interface{}{"vendor": "ACME", "amount": "10.00"},
+			Confidence:   0.9,
+			Model:        "test/model",
+		},
+	}
+	s, repo := newTestService(t, stub)
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &models.Document{ID: "doc", Filename: "invoice.txt", ContentType: "text/plain", S3Key: "k",
+		ExtractedText: "Invoice from ACME, amount due 10.00", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := s.AnalyzeDocument(ctx, "doc", &models.AnalyzeRequest{}); err != nil {
+		t.Fatalf("AnalyzeDocument returned error: %v", err)
+	}
+
+	memo := "memo"
+	stub.during = func() {
+		stub.during = nil
+		_, err := s.CorrectAnalysis(ctx, "doc", &models.CorrectionRequest{
+			Reviewer:     "alice",
+			DocumentType: &memo,
+			Metadata:     map[string]interface{}{"vendor": "Acme Ltd"},
+		})
+		if err != nil {
+			t.Errorf("CorrectAnalysis returned error: %v", err)
+		}
+	}
+
+	resp, err := s.AnalyzeDocument(ctx, "doc", &models.AnalyzeRequest{Force: true})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument returned error: %v", err)
+	}
+	if resp.DocumentType != "memo" || resp.Metadata["vendor"] != "Acme Ltd" || resp.Metadata["amount"] != "10.00" {
+		t.Errorf("response = %s %v, want the corrected type and vendor", resp.DocumentType, resp.Metadata)
+	}
+
+	stored, err := repo.GetByID(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if *stored.DocumentType != "memo" || stored.Metadata["vendor"] != "Acme Ltd" {
+		t.Errorf("stored = %s %v, want the correction kept", *stored.DocumentType, stored.Metadata)
+	}
+}
+
+func TestUploadDocumentReturnsExistingDocumentForSameContent(t *testing.T) {
+	s, repo := newTestService(t, analyzer.NewBuiltinAnalyzer())
+	storage := s.storage.(*memoryStorage)
+	ctx := context.Background()
+
+	content := "Invoice 42. Payment is due within thirty days of the invoice date."
+	uploadText := func(filename string, dedupe bool) *models.UploadResponse {
+		t.Helper()
+		resp, err := s.UploadDocument(ctx, &models.UploadRequest{
+			File:        strings.NewReader(content),
+			Size:        int64(len(content)),
+			Filename:    filename,
+			ContentType: "text/plain",
+			Dedupe:      dedupe,
+		})
+		if err != nil {
+			t.Fatalf("UploadDocument returned error: %v", err)
+		}
+		return resp
+	}
+
+	first := uploadText("invoice.txt", true)
+	second := uploadText("invoice-copy.txt", true)
+	if first.Duplicate || !second.Duplicate {
+		t.Errorf("duplicate = %v, %v, want only the second upload marked", first.Duplicate, second.Duplicate)
+	}
+	if second.ID != first.ID || second.Filename != "invoice.txt" {
+		t.Errorf("second upload = %s %s, want the first document %s", second.ID, second.Filename, first.ID)
+	}
+	if storage.uploads != 1 {
+		t.Errorf("stored %d files, want 1", storage.uploads)
+	}
+	if docs, _ := repo.List(ctx, models.DocumentFilter{Limit: 10}); len(docs) != 1 {
+		t.Errorf("got %d documents, want 1", len(docs))
+	}
+
+	// Opting out stores a separate copy
+	copied := uploadText("invoice-copy.txt", false)
+	if copied.Duplicate || copied.ID == first.ID || storage.uploads != 2 {
+		t.Errorf("upload with dedupe off = %+v after %d stored files, want a new document", copied, storage.uploads)
+	}
+
+	// Uploads are embedded in the background; wait for them before the
+	// database is closed
+	for i := 0; s.vectors.Len() < 2 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAnalyzeRecordsUsageOfFailedAnalysis(t *testing.T) {
+	stub := &stubAnalyzer{
+		Analyzer: analyzer.NewBuiltinAnalyzer(),
+		err: &analyzer.UsageError{
+			Err:   errors.New("failed to parse LLM response"),
+			Model: "test/model",
+			Usage: models.TokenUsage{PromptTokens: 300, CompletionTokens: 40},
+		},
+	}
+	s, repo := newTestService(t, stub)
+	ctx := context.Background()
+
+	now := time.Now()
+	doc := &models.Document{ID: "doc", Filename: "letter.txt", ContentType: "text/plain", S3Key: "k",
+		ExtractedText: "Dear Sir, thank you for your letter.", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := s.AnalyzeDocument(ctx, "doc", &models.AnalyzeRequest{}); err == nil {
+		t.Fatal("AnalyzeDocument succeeded with a failing analyzer")
+	}
+
+	usage, err := s.GetUsage(ctx, models.UsageFilter{})
+	if err != nil {
+		t.Fatalf("GetUsage returned error: %v", err)
+	}
+	total := usage.Total
+	if total.Analyses != 0 || total.FailedCalls != 1 || total.PromptTokens != 300 || total.CompletionTokens != 40 {
+		t.Errorf("usage = %+v, want one failed call with its tokens", total)
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/config"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/embedding"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+const (
+	// embedChunkSize is the size in bytes of the chunks that are embedded
+	embedChunkSize = 1000
+	// chunkHitsPerDocument is how many chunk hits are fetched for each
+	// document asked for, since several chunks of one document often rank
+	// next to each other
+	chunkHitsPerDocument = 8
+	// maxQueryLength bounds the search query in characters
+	maxQueryLength = 1000
+)
+
+func newEmbedder(cfg *config.Config) embedding.Embedder {
+	if cfg.EmbeddingProvider == "openai" {
+		return embedding.NewOpenAIEmbedder(embedding.OpenAIConfig{
+			APIKey:  cfg.EmbeddingAPIKey,
+			Model:   cfg.EmbeddingModel,
+			BaseURL: cfg.EmbeddingBaseURL,
+		})
+	}
+	return embedding.NewHashingEmbedder(cfg.EmbeddingDimensions)
+}
+
+func newVectorIndex(cfg *config.Config) embedding.Index {
+	if cfg.VectorIndex == "hnsw" {
+		return embedding.NewHNSW()
+	}
+	return embedding.NewFlat()
+}
+
+// loadVectors fills the vector index with the stored vectors of the
+// current embedding model
+func (s *documentService) loadVectors(ctx context.Context) error {
+	embeddings, err := s.repo.ListEmbeddings(ctx, s.embedder.Model(), "")
+	if err != nil {
+		return err
+	}
+	for _, e := range embeddings {
+		s.vectors.Add(embedding.Key{DocumentID: e.DocumentID, Start: e.Start, End: e.End}, e.Vector)
+	}
+
+	s.logger.Info("Vector index loaded", "model", s.embedder.Model(), "chunks", len(embeddings))
+	return nil
+}
+
+// backfillEmbeddings embeds documents uploaded before embeddings were
+// configured or under another embedding model. Failures are logged and the
+// document is tried again at the next start.
+func (s *documentService) backfillEmbeddings(ctx context.Context) {
+	ids, err := s.repo.ListUnembeddedDocuments(ctx, s.embedder.Model())
+	if err != nil {
+		s.logger.Error("Failed to list documents without embeddings", "error", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	s.logger.Info("Embedding documents", "model", s.embedder.Model(), "documents", len(ids))
+	embedded := 0
+	for _, id := range ids {
+		doc, err := s.repo.GetByID(ctx, id)
+		if err != nil || doc == nil {
+			s.logger.Error("Failed to get document", "error", err, "id", id)
+			continue
+		}
+		if _, err := s.embedDocument(ctx, doc); err != nil {
+			s.logger.Warn("Failed to embed document", "error", err, "id", id)
+			continue
+		}
+		embedded++
+	}
+	s.logger.Info("Documents embedded", "model", s.embedder.Model(), "embedded", embedded, "failed", len(ids)-embedded)
+}
+
+// embedDocument embeds the chunks of a document's text, stores the vectors
+// and replaces the document's chunks in the index. Text sent to a provider
+// is redacted with the configured policy first.
+func (s *documentService) embedDocument(ctx context.Context, doc *models.Document) ([]*models.Embedding, error) {
+	chunks := retrieval.Split(doc.ExtractedText, embedChunkSize)
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	if !embedding.IsLocal(s.embedder) {
+		redactor := redact.New(s.redaction)
+		for i := range texts {
+			texts[i] = redactor.Redact(texts[i])
+		}
+		if err := s.recordRedaction(ctx, doc.ID, "embed", s.redaction, redactor); err != nil {
+			return nil, err
+		}
+	}
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	model := s.embedder.Model()
+	embeddings := make([]*models.Embedding, len(chunks))
+	for i, chunk := range chunks {
+		embeddings[i] = &models.Embedding{
+			ID:         utils.GenerateID(),
+			DocumentID: doc.ID,
+			ChunkIndex: chunk.Index,
+			Start:      chunk.Start,
+			End:        chunk.End,
+			Model:      model,
+			Vector:     vectors[i],
+			CreatedAt:  now,
+		}
+	}
+
+	if err := s.repo.ReplaceEmbeddings(ctx, doc.ID, model, embeddings); err != nil {
+		return nil, fmt.Errorf("failed to save embeddings: %w", err)
+	}
+
+	entries := make([]embedding.Entry, len(embeddings))
+	for i, e := range embeddings {
+		entries[i] = embedding.Entry{Key: embedding.Key{DocumentID: doc.ID, Start: e.Start, End: e.End}, Vector: e.Vector}
+	}
+	s.vectors.Replace(doc.ID, entries)
+	return embeddings, nil
+}
+
+// SemanticSearch finds the documents whose text is closest in meaning to
+// a query, ranked by their best matching chunk
+func (s *documentService) SemanticSearch(ctx context.Context, query string, limit, offset int) (*models.SemanticSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, utils.NewBadRequestError("q is required")
+	}
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("q must be at most %d characters", maxQueryLength))
+	}
+
+	// Queries are not stored against a document, so the redaction has
+	// nothing to be audited with
+	text := query
+	if !embedding.IsLocal(s.embedder) {
+		text = redact.New(s.redaction).Redact(query)
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		s.logger.Error("Failed to embed query", "error", err)
+		return nil, utils.NewServiceUnavailableError("Embedding provider is unavailable, try again later")
+	}
+
+	hits := s.vectors.Search(vectors[0], (offset+limit)*chunkHitsPerDocument, nil)
+	results, err := s.documentMatches(ctx, hits, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SemanticSearchResponse{
+		Query:   query,
+		Model:   s.embedder.Model(),
+		Results: results,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// SimilarDocuments finds the documents closest in meaning to a document,
+// searching with the average of its chunk vectors. A document without
+// vectors for the current model is embedded first.
+func (s *documentService) SimilarDocuments(ctx context.Context, id string, limit, offset int) (*models.SimilarDocumentsResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	embeddings, err := s.repo.ListEmbeddings(ctx, s.embedder.Model(), id)
+	if err != nil {
+		s.logger.Error("Failed to list embeddings", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve embeddings")
+	}
+	if len(embeddings) == 0 {
+		if embeddings, err = s.embedDocument(ctx, doc); err != nil {
+			s.logger.Error("Failed to embed document", "error", err, "id", id)
+			return nil, utils.NewServiceUnavailableError("Embedding provider is unavailable, try again later")
+		}
+	}
+
+	vectors := make([][]float32, len(embeddings))
+	for i, e := range embeddings {
+		vectors[i] = e.Vector
+	}
+	hits := s.vectors.Search(embedding.Mean(vectors), (offset+limit)*chunkHitsPerDocument, func(key embedding.Key) bool {
+		return key.DocumentID != id
+	})
+	documents, err := s.documentMatches(ctx, hits, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SimilarDocumentsResponse{
+		DocumentID: id,
+		Model:      s.embedder.Model(),
+		Documents:  documents,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// documentMatches groups chunk hits by document, keeping each document's
+// best chunk as its passage, and returns one page of documents
+func (s *documentService) documentMatches(ctx context.Context, hits []embedding.Hit, limit, offset int) ([]models.SemanticMatch, error) {
+	var best []embedding.Hit
+	seen := map[string]bool{}
+	for _, hit := range hits {
+		if !seen[hit.DocumentID] {
+			seen[hit.DocumentID] = true
+			best = append(best, hit)
+		}
+	}
+	if offset >= len(best) {
+		return []models.SemanticMatch{}, nil
+	}
+	best = best[offset:min(offset+limit, len(best))]
+
+	matches := make([]models.SemanticMatch, 0, len(best))
+	for _, hit := range best {
+		doc, err := s.repo.GetByID(ctx, hit.DocumentID)
+		if err != nil {
+			s.logger.Error("Failed to get document", "error", err, "id", hit.DocumentID)
+			return nil, utils.NewInternalError("Failed to retrieve document")
+		}
+		if doc == nil || hit.End > len(doc.ExtractedText) {
+			continue
+		}
+
+		matches = append(matches, models.SemanticMatch{
+			DocumentID:   doc.ID,
+			Filename:     doc.Filename,
+			DocumentType: doc.DocumentType,
+			Score:        math.Round(float64(hit.Score)*1e4) / 1e4,
+			Passage: models.MatchedPassage{
+				Text:  doc.ExtractedText[hit.Start:hit.End],
+				Start: utf8.RuneCountInString(doc.ExtractedText[:hit.Start]),
+				End:   utf8.RuneCountInString(doc.ExtractedText[:hit.End]),
+			},
+		})
+	}
+	return matches, nil
+}
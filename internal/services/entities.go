@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// findEntities extracts the entities of a document from its redacted text.
+// The analyzer names each entity once, so every mention of it is located in
+// the extracted text; entities that cannot be found there are dropped, since
+// the analyzer made them up or changed them beyond recognition.
+func (s *documentService) findEntities(ctx context.Context, doc *models.Document, text string, redactor *redact.Redactor) ([]*models.Entity, models.TokenUsage, error) {
+	result, err := s.analyzer.Entities(ctx, text)
+	if err != nil {
+		return nil, models.TokenUsage{}, err
+	}
+
+	now := time.Now()
+	entities := []*models.Entity{}
+	seen := map[string]bool{}
+	for _, candidate := range result.Entities {
+		mention := strings.TrimSpace(redactor.Restore(candidate.Text))
+		value, ok := analyzer.NormalizeEntity(candidate.Type, mention)
+		if !ok {
+			continue
+		}
+
+		for _, span := range locateMentions(doc.ExtractedText, mention) {
+			key := fmt.Sprintf("%s:%d:%d", candidate.Type, span[0], span[1])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			entities = append(entities, &models.Entity{
+				ID:         utils.GenerateID(),
+				DocumentID: doc.ID,
+				Type:       candidate.Type,
+				Value:      value,
+				Text:       doc.ExtractedText[span[0]:span[1]],
+				Start:      utf8.RuneCountInString(doc.ExtractedText[:span[0]]),
+				End:        utf8.RuneCountInString(doc.ExtractedText[:span[1]]),
+				CreatedAt:  now,
+			})
+		}
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		return entities[i].Start < entities[j].Start
+	})
+	return entities, result.Usage, nil
+}
+
+// locateMentions returns the byte offsets of every whole-word occurrence of
+// mention in text. When there is none, the first occurrence that differs
+// only in case or whitespace is used instead.
+func locateMentions(text, mention string) [][2]int {
+	var spans [][2]int
+	for from := 0; from < len(text); {
+		i := strings.Index(text[from:], mention)
+		if i < 0 {
+			break
+		}
+		start, end := from+i, from+i+len(mention)
+		if isWordBoundary(text, start, end) {
+			spans = append(spans, [2]int{start, end})
+		}
+		from = end
+	}
+
+	if len(spans) == 0 {
+		if start, end, ok := retrieval.Locate(text, mention, retrieval.Chunk{}); ok && isWordBoundary(text, start, end) {
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	return spans
+}
+
+// isWordBoundary reports whether text[start:end] does not start or end in
+// the middle of a word, so "Acme" is not found inside "Acmeville"
+func isWordBoundary(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	return (start == 0 || !isWord(before)) && (end == len(text) || !isWord(after))
+}
+
+func (s *documentService) ListEntities(ctx context.Context, id string) (*models.EntityListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	entities, err := s.repo.ListEntities(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list entities", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve entities")
+	}
+
+	return &models.EntityListResponse{
+		DocumentID: id,
+		Entities:   entities,
+	}, nil
+}
+
+// SearchEntities finds the documents mentioning an entity. The value is
+// normalized the way entities are stored, so "ACME Ltd." finds mentions of
+// "Acme". Without a type the value is normalized as an organization name,
+// which also matches people and locations.
+func (s *documentService) SearchEntities(ctx context.Context, filter models.EntityFilter) (*models.EntitySearchResponse, error) {
+	filter.Type = strings.ToLower(strings.TrimSpace(filter.Type))
+	if filter.Type != "" && !slices.Contains(models.EntityTypes, filter.Type) {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unknown entity type '%s'; use one of %s",
+			filter.Type, strings.Join(models.EntityTypes, ", ")))
+	}
+	if strings.TrimSpace(filter.Value) == "" {
+		return nil, utils.NewBadRequestError("value is required")
+	}
+
+	normalizeAs := filter.Type
+	if normalizeAs == "" {
+		normalizeAs = models.EntityOrganization
+	}
+	value, ok := analyzer.NormalizeEntity(normalizeAs, filter.Value)
+	if !ok {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("'%s' is not a valid %s", filter.Value, normalizeAs))
+	}
+	filter.Value = value
+
+	documents, err := s.repo.SearchEntities(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to search entities", "error", err, "type", filter.Type, "value", value)
+		return nil, utils.NewInternalError("Failed to search entities")
+	}
+
+	return &models.EntitySearchResponse{
+		Type:      filter.Type,
+		Value:     value,
+		Documents: documents,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+// Health reports overall status along with the circuit breaker state of
+// each LLM provider. The service stays up while a provider is unavailable,
+// so an open circuit degrades rather than fails the check.
+func (s *documentService) Health(ctx context.Context) *models.HealthResponse {
+	resp := &models.HealthResponse{Status: "healthy"}
+
+	reporter, ok := s.analyzer.(analyzer.StatusReporter)
+	if !ok {
+		return resp
+	}
+
+	resp.Providers = reporter.ProviderStatus()
+	for _, provider := range resp.Providers {
+		if provider.CircuitState != string(analyzer.CircuitClosed) {
+			resp.Status = "degraded"
+		}
+	}
+
+	return resp
+}
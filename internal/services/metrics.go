@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// Metrics reports the analysis cache's hits and misses since the server
+// started, along with how much it holds. The cache is disabled with the
+// offline analyzer or when ANALYSIS_CACHE_TTL or ANALYSIS_CACHE_MAX_ENTRIES
+// is zero.
+func (s *documentService) Metrics(ctx context.Context) (*models.MetricsResponse, error) {
+	resp := &models.MetricsResponse{}
+
+	reporter, ok := s.analyzer.(analyzer.CacheReporter)
+	if !ok {
+		return resp, nil
+	}
+
+	cache := &resp.AnalysisCache
+	cache.Enabled = true
+	cache.Hits, cache.Misses = reporter.CacheStats()
+	if lookups := cache.Hits + cache.Misses; lookups > 0 {
+		cache.HitRate = math.Round(float64(cache.Hits)/float64(lookups)*10000) / 10000
+	}
+
+	entries, size, err := s.repo.AnalysisCacheSize(ctx)
+	if err != nil {
+		s.logger.Error("Failed to measure analysis cache", "error", err)
+		return nil, utils.NewInternalError("Failed to retrieve metrics")
+	}
+	cache.Entries, cache.SizeBytes = entries, size
+
+	return resp, nil
+}
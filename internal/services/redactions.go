@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// redactPolicy resolves the redaction policy of a request, falling back to
+// the configured default when the request does not set one. Nothing is
+// masked when only the builtin analyzer runs, since no text leaves the
+// server and masking would hide values from its rules.
+func (s *documentService) redactPolicy(value string) (redact.Policy, error) {
+	policy := s.redaction
+	if value != "" {
+		var err error
+		if policy, err = redact.ParsePolicy(value); err != nil {
+			return nil, utils.NewBadRequestError(err.Error())
+		}
+	}
+
+	if analyzer.IsOffline(s.analyzer) {
+		return redact.Policy{}, nil
+	}
+	return policy, nil
+}
+
+// recordRedaction audits what was masked before text is sent to the
+// analyzer. It fails the request when the audit cannot be written, so
+// nothing is sent that the audit does not account for.
+func (s *documentService) recordRedaction(ctx context.Context, documentID, operation string, policy redact.Policy, redactor *redact.Redactor) error {
+	redaction := &models.Redaction{
+		ID:         utils.GenerateID(),
+		DocumentID: documentID,
+		Operation:  operation,
+		Policy:     policy.String(),
+		Counts:     redactor.Counts(),
+		Total:      len(redactor.Matches()),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.repo.CreateRedaction(ctx, redaction); err != nil {
+		s.logger.Error("Failed to record redaction", "error", err, "id", documentID, "operation", operation)
+		return utils.NewInternalError("Failed to record redaction audit")
+	}
+
+	if redaction.Total > 0 {
+		s.logger.Info("Personal data masked", "id", documentID, "operation", operation, "counts", redaction.Counts)
+	}
+	return nil
+}
+
+func (s *documentService) ListRedactions(ctx context.Context, id string) (*models.RedactionListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	redactions, err := s.repo.ListRedactions(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list redactions", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve redactions")
+	}
+
+	return &models.RedactionListResponse{
+		DocumentID: id,
+		Redactions: redactions,
+	}, nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/repository"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// maxReviewerLength bounds the reviewer name stored with each correction
+const maxReviewerLength = 100
+
+// CorrectAnalysis applies a reviewer's corrections to a document's type and
+// metadata. Each field the request sets or verifies is recorded in the
+// audit trail and marked as verified, so re-analysis keeps its value.
+// Setting a field to the value it already has verifies it.
+func (s *documentService) CorrectAnalysis(ctx context.Context, id string, req *models.CorrectionRequest) (*models.CorrectionResponse, error) {
+	reviewer := strings.TrimSpace(req.Reviewer)
+	if reviewer == "" {
+		return nil, utils.NewBadRequestError("reviewer is required")
+	}
+	if len(reviewer) > maxReviewerLength {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("reviewer must be at most %d characters", maxReviewerLength))
+	}
+	if req.DocumentType == nil && len(req.Metadata) == 0 && len(req.Verify) == 0 {
+		return nil, utils.NewBadRequestError("Nothing to correct; set document_type, metadata or verify")
+	}
+
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+	if doc.AnalyzedAt == nil || doc.DocumentType == nil {
+		return nil, utils.NewBadRequestError("Document has not been analyzed yet")
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = map[string]interface{}{}
+	}
+
+	now := time.Now()
+	var corrections []*models.Correction
+	record := func(field string, oldValue, newValue interface{}) {
+		corrections = append(corrections, &models.Correction{
+			ID:         utils.GenerateID(),
+			DocumentID: id,
+			Field:      field,
+			Action:     correctionAction(oldValue, newValue),
+			OldValue:   oldValue,
+			NewValue:   newValue,
+			Reviewer:   reviewer,
+			Note:       strings.TrimSpace(req.Note),
+			CreatedAt:  now,
+		})
+		if !slices.Contains(doc.VerifiedFields, field) {
+			doc.VerifiedFields = append(doc.VerifiedFields, field)
+		}
+	}
+
+	if req.DocumentType != nil {
+		docType, ok := s.taxonomy.Map(*req.DocumentType)
+		if !ok {
+			return nil, utils.NewBadRequestError(fmt.Sprintf("Unknown document type '%s'; use one of %s",
+				*req.DocumentType, strings.Join(s.taxonomy.Types(), ", ")))
+		}
+		record(models.FieldDocumentType, *doc.DocumentType, docType)
+		doc.DocumentType = &docType
+	}
+
+	keys := make([]string, 0, len(req.Metadata))
+	for key := range req.Metadata {
+		if strings.TrimSpace(key) == "" {
+			return nil, utils.NewBadRequestError("Metadata field names must not be empty")
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := req.Metadata[key]
+		record(models.MetadataField+key, doc.Metadata[key], value)
+		if value == nil {
+			delete(doc.Metadata, key)
+		} else {
+			doc.Metadata[key] = value
+		}
+	}
+
+	for _, field := range req.Verify {
+		if slices.ContainsFunc(corrections, func(c *models.Correction) bool { return c.Field == field }) {
+			continue
+		}
+		value, err := analysisField(doc, field)
+		if err != nil {
+			return nil, err
+		}
+		record(field, value, value)
+	}
+
+	sort.Strings(doc.VerifiedFields)
+	doc.ReviewedBy = &reviewer
+	doc.ReviewedAt = &now
+
+	if err := s.repo.CorrectAnalysis(ctx, doc, corrections); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, utils.NewConflictError("The analysis changed while it was being corrected; review it again")
+		}
+		s.logger.Error("Failed to save corrections", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to save corrections")
+	}
+
+	s.logger.Info("Analysis corrected",
+		"id", id,
+		"reviewer", reviewer,
+		"corrections", len(corrections),
+		"verified_fields", len(doc.VerifiedFields))
+
+	return &models.CorrectionResponse{
+		ID:             id,
+		DocumentType:   *doc.DocumentType,
+		Metadata:       doc.Metadata,
+		VerifiedFields: doc.VerifiedFields,
+		TypeConfidence: doc.TypeConfidence,
+		NeedsReview:    s.needsReview(doc.TypeConfidence, doc.VerifiedFields),
+		ReviewedBy:     reviewer,
+		ReviewedAt:     now,
+		Corrections:    corrections,
+	}, nil
+}
+
+// correctionAction is corrected when a field's value changed and verified
+// when the reviewer confirmed the value it had
+func correctionAction(oldValue, newValue interface{}) string {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return models.CorrectionVerified
+	}
+	return models.CorrectionCorrected
+}
+
+// analysisField returns the current value of a field named as in
+// CorrectionRequest.Verify
+func analysisField(doc *models.Document, field string) (interface{}, error) {
+	if field == models.FieldDocumentType {
+		return *doc.DocumentType, nil
+	}
+	key, ok := strings.CutPrefix(field, models.MetadataField)
+	if !ok || key == "" {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unknown field '%s'; use %s or %s<key>",
+			field, models.FieldDocumentType, models.MetadataField))
+	}
+	value, ok := doc.Metadata[key]
+	if !ok {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Document has no metadata field '%s'", key))
+	}
+	return value, nil
+}
+
+// maxSaveAttempts bounds how often an analysis is stored again after the
+// document was corrected while the model ran
+const maxSaveAttempts = 3
+
+// saveAnalysis stores an analysis made from doc and returns the document it
+// was stored on. When a reviewer corrected the document while the model
+// ran, the document is read again and its verified fields are put back into
+// the analysis, so the correction is not overwritten.
+func (s *documentService) saveAnalysis(ctx context.Context, doc *models.Document, result *models.LLMAnalysisResult, analysis *models.Analysis) (*models.Document, error) {
+	for attempt := 1; ; attempt++ {
+		err := s.repo.UpdateAnalysis(ctx, analysis, doc.Revision)
+		if err == nil {
+			return doc, nil
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			s.logger.Error("Failed to update analysis", "error", err, "id", doc.ID)
+			return nil, utils.NewInternalError("Failed to save analysis results")
+		}
+		if attempt == maxSaveAttempts {
+			return nil, utils.NewConflictError("The document kept changing while it was being analyzed; try again")
+		}
+
+		s.logger.Info("Document changed during analysis, keeping its verified fields", "id", doc.ID)
+		id := doc.ID
+		if doc, err = s.repo.GetByID(ctx, id); err != nil {
+			s.logger.Error("Failed to get document", "error", err, "id", id)
+			return nil, utils.NewInternalError("Failed to retrieve document")
+		}
+		if doc == nil {
+			return nil, utils.NewNotFoundError("Document not found")
+		}
+		keepVerified(doc, result)
+		analysis.DocumentType = result.DocumentType
+		analysis.Metadata = result.Metadata
+	}
+}
+
+// keepVerified puts the values of the fields a reviewer verified back into
+// a new analysis result. A verified metadata field that was removed stays
+// removed.
+func keepVerified(doc *models.Document, result *models.LLMAnalysisResult) {
+	for _, field := range doc.VerifiedFields {
+		if field == models.FieldDocumentType {
+			if doc.DocumentType != nil {
+				result.DocumentType = *doc.DocumentType
+			}
+			continue
+		}
+		key, ok := strings.CutPrefix(field, models.MetadataField)
+		if !ok {
+			continue
+		}
+		if result.Metadata == nil {
+			result.Metadata = map[string]interface{}{}
+		}
+		if value, ok := doc.Metadata[key]; ok {
+			result.Metadata[key] = value
+		} else {
+			delete(result.Metadata, key)
+		}
+	}
+}
+
+func (s *documentService) ListCorrections(ctx context.Context, id string) (*models.CorrectionListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	corrections, err := s.repo.ListCorrections(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list corrections", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve corrections")
+	}
+
+	return &models.CorrectionListResponse{
+		DocumentID:  id,
+		Corrections: corrections,
+	}, nil
+}
+
+// ReviewQueue lists the analyzed documents that nobody has reviewed yet and
+// those whose classification is below the review threshold until a
+// reviewer verifies their type
+func (s *documentService) ReviewQueue(ctx context.Context, limit, offset int) (*models.ReviewQueueResponse, error) {
+	docs, err := s.repo.ListReviewQueue(ctx, models.ReviewFilter{
+		ReviewBelow: s.reviewBelow,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list review queue", "error", err)
+		return nil, utils.NewInternalError("Failed to retrieve review queue")
+	}
+
+	items := make([]*models.ReviewItem, 0, len(docs))
+	for _, doc := range docs {
+		item := &models.ReviewItem{
+			ID:             doc.ID,
+			Filename:       doc.Filename,
+			DocumentType:   stringValue(doc.DocumentType),
+			TypeConfidence: doc.TypeConfidence,
+			VerifiedFields: doc.VerifiedFields,
+			Reasons:        []string{},
+			AnalyzedAt:     *doc.AnalyzedAt,
+			ReviewedAt:     doc.ReviewedAt,
+		}
+		if doc.ReviewedAt == nil {
+			item.Reasons = append(item.Reasons, models.ReviewUnreviewed)
+		}
+		if s.needsReview(doc.TypeConfidence, doc.VerifiedFields) {
+			item.Reasons = append(item.Reasons, models.ReviewLowConfidence)
+		}
+		items = append(items, item)
+	}
+
+	return &models.ReviewQueueResponse{
+		Documents: items,
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
+}
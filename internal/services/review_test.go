@@ -0,0 +1,161 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+)
+
+func TestKeepVerified(t *testing.T) {
+	docType := "invoice"
+
+	tests := []struct {
+		name     string
+		verified []string
+		metadata map[string]interface{}
+		result   map[string]interface{}
+		wantType string
+		want     map[string]interface{}
+	}{
+		{
+			name:     "nothing verified",
+			metadata: map[string]interface{}{"vendor": "Acme"},
+			result:   map[string]interface{}{"vendor": "ACME Corp"},
+			wantType: "receipt",
+			want:     map[string]interface{}{"vendor": "ACME Corp"},
+		},
+		{
+			name:     "verified type",
+			verified: []string{models.FieldDocumentType},
+			result:   map[string]interface{}{"vendor": "ACME Corp"},
+			wantType: "invoice",
+			want:     map[string]interface{}{"vendor": "ACME Corp"},
+		},
+		{
+			name:     "verified metadata value",
+			verified: []string{"metadata.vendor"},
+			metadata: map[string]interface{}{"vendor": "Acme", "amount": "10.00"},
+			result:   map[string]interface{}{"vendor": "ACME Corp", "amount": "12.00"},
+			wantType: "receipt",
+			want:     map[string]interface{}{"vendor": "Acme", "amount": "12.00"},
+		},
+		{
+			name:     "verified metadata value missing from the result",
+			verified: []string{"metadata.vendor"},
+			metadata: map[string]interface{}{"vendor": "Acme"},
+			result:   nil,
+			wantType: "receipt",
+			want:     map[string]interface{}{"vendor": "Acme"},
+		},
+		{
+			name:     "verified then deleted key",
+			verified: []string{"metadata.po_number"},
+			metadata: map[string]interface{}{"vendor": "Acme"},
+			result:   map[string]interface{}{"vendor": "ACME Corp", "po_number": "PO-1"},
+			wantType: "receipt",
+			want:     map[string]interface{}{"vendor": "ACME Corp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &models.Document{DocumentType: &docType, Metadata: tt.metadata, VerifiedFields: tt.verified}
+			result := &models.LLMAnalysisResult{DocumentType: "receipt", Metadata: tt.result}
+
+			keepVerified(doc, result)
+
+			if result.DocumentType != tt.wantType {
+				t.Errorf("DocumentType = %q, want %q", result.DocumentType, tt.wantType)
+			}
+			if !reflect.DeepEqual(result.Metadata, tt.want) {
+				t.Errorf("Metadata = %v, want %v", result.Metadata, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalysisField(t *testing.T) {
+	docType := "invoice"
+	doc := &models.Document{
+		DocumentType: &docType,
+		Metadata:     map[string]interface{}{"vendor": "Acme", "date": nil},
+	}
+
+	tests := []struct {
+		field   string
+		want    interface{}
+		wantErr bool
+	}{
+		{field: "document_type", want: "invoice"},
+		{field: "metadata.vendor", want: "Acme"},
+		{field: "metadata.date", want: nil},
+		{field: "metadata.amount", wantErr: true},
+		{field: "metadata.", wantErr: true},
+		{field: "summary", wantErr: true},
+		{field: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := analysisField(doc, tt.field)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("analysisField(%q) = %v, want an error", tt.field, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("analysisField(%q) returned error: %v", tt.field, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("analysisField(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestCorrectionAction(t *testing.T) {
+	tests := []struct {
+		oldValue, newValue interface{}
+		want               string
+	}{
+		{"Acme", "Acme", models.CorrectionVerified},
+		{"Acme", "Acme Ltd", models.CorrectionCorrected},
+		{nil, "Acme", models.CorrectionCorrected},
+		{"Acme", nil, models.CorrectionCorrected},
+		{nil, nil, models.CorrectionVerified},
+		{1392.0, 1392.0, models.CorrectionVerified},
+		{"1392.00", 1392.0, models.CorrectionCorrected},
+		{[]interface{}{"a"}, []interface{}{"a"}, models.CorrectionVerified},
+	}
+
+	for _, tt := range tests {
+		if got := correctionAction(tt.oldValue, tt.newValue); got != tt.want {
+			t.Errorf("correctionAction(%v, %v) = %s, want %s", tt.oldValue, tt.newValue, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsReview(t *testing.T) {
+	s := &documentService{reviewBelow: 0.7}
+	low, high := 0.4, 0.9
+
+	tests := []struct {
+		name       string
+		confidence *float64
+		verified   []string
+		want       bool
+	}{
+		{"no confidence recorded", nil, nil, false},
+		{"confident", &high, nil, false},
+		{"uncertain", &low, nil, true},
+		{"uncertain with verified metadata", &low, []string{"metadata.vendor"}, true},
+		{"uncertain with verified type", &low, []string{models.FieldDocumentType}, false},
+	}
+
+	for _, tt := range tests {
+		if got := s.needsReview(tt.confidence, tt.verified); got != tt.want {
+			t.Errorf("%s: needsReview = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// maxSchemaFields bounds how many fields one extraction prompt asks for
+const maxSchemaFields = 50
+
+var identifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+func (s *documentService) CreateSchema(ctx context.Context, req *models.CreateSchemaRequest) (*models.ExtractionSchema, error) {
+	if err := validateSchema(req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetSchemaByName(ctx, req.Name)
+	if err != nil {
+		s.logger.Error("Failed to look up schema", "error", err, "name", req.Name)
+		return nil, utils.NewInternalError("Failed to save schema")
+	}
+	if existing != nil {
+		return nil, utils.NewConflictError(fmt.Sprintf("Schema %q already exists", req.Name))
+	}
+
+	schema := &models.ExtractionSchema{
+		ID:          utils.GenerateID(),
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Fields:      req.Fields,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.repo.CreateSchema(ctx, schema); err != nil {
+		s.logger.Error("Failed to save schema", "error", err, "name", req.Name)
+		return nil, utils.NewInternalError("Failed to save schema")
+	}
+
+	s.logger.Info("Schema created", "name", schema.Name, "fields", len(schema.Fields))
+
+	return schema, nil
+}
+
+// validateSchema checks names are identifiers the model can use as JSON
+// keys and that every field has a supported type
+func validateSchema(req *models.CreateSchemaRequest) error {
+	if !identifierPattern.MatchString(req.Name) {
+		return utils.NewBadRequestError("name must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	if len(req.Fields) == 0 {
+		return utils.NewBadRequestError("at least one field is required")
+	}
+	if len(req.Fields) > maxSchemaFields {
+		return utils.NewBadRequestError(fmt.Sprintf("a schema may have at most %d fields", maxSchemaFields))
+	}
+
+	seen := map[string]bool{}
+	for i := range req.Fields {
+		field := &req.Fields[i]
+		if !identifierPattern.MatchString(field.Name) {
+			return utils.NewBadRequestError(fmt.Sprintf("field %q: name must start with a letter and contain only lowercase letters, digits and underscores", field.Name))
+		}
+		if seen[field.Name] {
+			return utils.NewBadRequestError(fmt.Sprintf("field %q is defined twice", field.Name))
+		}
+		seen[field.Name] = true
+
+		field.Type = models.FieldType(strings.ToLower(string(field.Type)))
+		if !isFieldType(field.Type) {
+			return utils.NewBadRequestError(fmt.Sprintf("field %q: unsupported type %q", field.Name, field.Type))
+		}
+		field.Description = strings.TrimSpace(field.Description)
+	}
+
+	return nil
+}
+
+func isFieldType(fieldType models.FieldType) bool {
+	for _, t := range models.FieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *documentService) GetSchema(ctx context.Context, name string) (*models.ExtractionSchema, error) {
+	schema, err := s.repo.GetSchemaByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to get schema", "error", err, "name", name)
+		return nil, utils.NewInternalError("Failed to retrieve schema")
+	}
+	if schema == nil {
+		return nil, utils.NewNotFoundError("Schema not found")
+	}
+
+	return schema, nil
+}
+
+func (s *documentService) ListSchemas(ctx context.Context) (*models.SchemaListResponse, error) {
+	schemas, err := s.repo.ListSchemas(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list schemas", "error", err)
+		return nil, utils.NewInternalError("Failed to list schemas")
+	}
+
+	return &models.SchemaListResponse{Schemas: schemas}, nil
+}
+
+func (s *documentService) ExtractDocument(ctx context.Context, id, schemaName, redactPolicy string) (*models.Extraction, error) {
+	policy, err := s.redactPolicy(redactPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	schema, err := s.GetSchema(ctx, schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor := redact.New(policy)
+	text := redactor.Redact(doc.ExtractedText)
+	if err := s.recordRedaction(ctx, id, "extract", policy, redactor); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := s.analyzer.Extract(ctx, text, schema)
+	latency := time.Since(start)
+	if err != nil {
+		s.logger.Error("Failed to extract schema", "error", err, "id", id, "schema", schemaName)
+		if errors.Is(err, analyzer.ErrCircuitOpen) {
+			return nil, utils.NewServiceUnavailableError("LLM provider is temporarily unavailable, try again later")
+		}
+		return nil, utils.NewInternalError("Failed to extract fields with LLM")
+	}
+
+	// Restored values are not re-validated, since the validated placeholder
+	// stood in for the same value
+	result.Data = redactor.RestoreValue(result.Data).(map[string]interface{})
+
+	extraction := &models.Extraction{
+		ID:               utils.GenerateID(),
+		DocumentID:       id,
+		SchemaID:         schema.ID,
+		SchemaName:       schema.Name,
+		Data:             result.Data,
+		Model:            result.Model,
+		PromptVersion:    result.PromptVersion,
+		LatencyMS:        latency.Milliseconds(),
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		CostUSD:          s.analysisCost(result.Model, result.Usage),
+		Redacted:         redactor.Counts(),
+		CreatedAt:        time.Now(),
+	}
+
+	if err := s.repo.CreateExtraction(ctx, extraction); err != nil {
+		s.logger.Error("Failed to save extraction", "error", err, "id", id, "schema", schemaName)
+		return nil, utils.NewInternalError("Failed to save extraction results")
+	}
+
+	s.logger.Info("Document fields extracted",
+		"id", id,
+		"schema", schemaName,
+		"model", result.Model,
+		"latency", latency)
+
+	return extraction, nil
+}
+
+func (s *documentService) ListExtractions(ctx context.Context, id string) (*models.ExtractionListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	extractions, err := s.repo.ListExtractions(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list extractions", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve extractions")
+	}
+
+	return &models.ExtractionListResponse{
+		DocumentID:  id,
+		Extractions: extractions,
+	}, nil
+}
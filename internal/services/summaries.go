@@ -0,0 +1,34 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// summaryVariant validates the summary style and length of a request,
+// defaulting to the standard medium summary
+func summaryVariant(style, length string) (string, string, error) {
+	style = strings.ToLower(strings.TrimSpace(style))
+	if style == "" {
+		style = models.SummaryStandard
+	}
+	if !slices.Contains(models.SummaryStyles, style) {
+		return "", "", utils.NewBadRequestError(fmt.Sprintf("Unknown summary style '%s'; use one of %s",
+			style, strings.Join(models.SummaryStyles, ", ")))
+	}
+
+	length = strings.ToLower(strings.TrimSpace(length))
+	if length == "" {
+		length = models.SummaryMedium
+	}
+	if !slices.Contains(models.SummaryLengths, length) {
+		return "", "", utils.NewBadRequestError(fmt.Sprintf("Unknown summary length '%s'; use one of %s",
+			length, strings.Join(models.SummaryLengths, ", ")))
+	}
+
+	return style, length, nil
+}
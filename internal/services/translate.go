@@ -0,0 +1,303 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/docx"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/language"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/redact"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/retrieval"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// translateChunkSize bounds the text sent in one translation request, so
+// the translation fits in the model's answer
+const translateChunkSize = 3000
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+// translationPiece is a paragraph, or part of one, to translate
+type translationPiece struct {
+	paragraph int
+	text      string
+}
+
+// TranslateDocument translates the extracted text of a document chunk by
+// chunk and stores it as a text version. Paragraphs are translated one for
+// one, so the translation has the same paragraphs as the original. The
+// latest translation into a language is returned unless req.Force is set.
+func (s *documentService) TranslateDocument(ctx context.Context, id string, req *models.TranslateRequest) (*models.TranslateResponse, error) {
+	target := strings.ToLower(strings.TrimSpace(req.TargetLanguage))
+	if target == "" {
+		return nil, utils.NewBadRequestError("target is required")
+	}
+	if !language.IsSupported(target) {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unsupported target language '%s'", target))
+	}
+	if analyzer.IsOffline(s.analyzer) {
+		return nil, utils.NewBadRequestError("Translation needs an LLM provider; set OPENROUTER_API_KEY")
+	}
+
+	policy, err := s.redactPolicy(req.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	paragraphs := translationParagraphs(doc.ExtractedText)
+	if len(paragraphs) == 0 {
+		return nil, utils.NewBadRequestError("Document has no text to translate")
+	}
+
+	sourceLanguage := stringValue(doc.Language)
+	if sourceLanguage == "" {
+		sourceLanguage, _ = language.Detect(doc.ExtractedText)
+	}
+	if sourceLanguage == target {
+		name, _ := language.Name(target)
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Document is already in %s", name))
+	}
+
+	if !req.Force {
+		cached, err := s.repo.GetLatestTextVersion(ctx, id, models.TextVersionTranslation, target)
+		if err != nil {
+			s.logger.Error("Failed to get text version", "error", err, "id", id)
+			return nil, utils.NewInternalError("Failed to retrieve translation")
+		}
+		if cached != nil {
+			s.logger.Info("Document already translated, returning cached translation", "id", id, "language", target)
+			return translateResponse(cached, true, nil), nil
+		}
+	}
+
+	redactor := redact.New(policy)
+	batches := translationBatches(paragraphs, translateChunkSize)
+	for _, batch := range batches {
+		for i := range batch {
+			batch[i].text = redactor.Redact(batch[i].text)
+		}
+	}
+	if err := s.recordRedaction(ctx, id, "translate", policy, redactor); err != nil {
+		return nil, err
+	}
+
+	version := &models.TextVersion{
+		ID:             utils.GenerateID(),
+		DocumentID:     id,
+		Kind:           models.TextVersionTranslation,
+		Language:       target,
+		SourceLanguage: sourceLanguage,
+	}
+	translated := make([][]string, len(paragraphs))
+	var usedModels []string
+	var cost float64
+	priced := true
+	opts := analyzer.Options{SourceLanguage: sourceLanguage, TargetLanguage: target}
+
+	for n, batch := range batches {
+		texts := make([]string, len(batch))
+		for i, piece := range batch {
+			texts[i] = piece.text
+		}
+
+		result, err := s.analyzer.Translate(ctx, texts, opts)
+		if err != nil {
+			s.logger.Error("Failed to translate document", "error", err, "id", id, "chunk", n+1, "chunks", len(batches))
+			if errors.Is(err, analyzer.ErrCircuitOpen) {
+				return nil, utils.NewServiceUnavailableError("LLM provider is temporarily unavailable, try again later")
+			}
+			return nil, utils.NewInternalError("Failed to translate document with LLM")
+		}
+
+		for i, piece := range batch {
+			translated[piece.paragraph] = append(translated[piece.paragraph], redactor.Restore(result.Paragraphs[i]))
+		}
+
+		// A fallback model may take over part way, so the version records
+		// every model and is only priced when all of them are
+		if !slices.Contains(usedModels, result.Model) {
+			usedModels = append(usedModels, result.Model)
+		}
+		if version.PromptVersion == "" {
+			version.PromptVersion = result.PromptVersion
+		}
+		version.PromptTokens += result.Usage.PromptTokens
+		version.CompletionTokens += result.Usage.CompletionTokens
+		if chunkCost := s.analysisCost(result.Model, result.Usage); chunkCost != nil {
+			cost += *chunkCost
+		} else {
+			priced = false
+		}
+	}
+
+	text := make([]string, len(translated))
+	for i, pieces := range translated {
+		text[i] = strings.Join(pieces, " ")
+	}
+	version.Text = strings.Join(text, "\n\n")
+	version.Model = strings.Join(usedModels, ",")
+	if priced {
+		version.CostUSD = &cost
+	}
+	version.CreatedAt = time.Now()
+
+	if err := s.repo.CreateTextVersion(ctx, version); err != nil {
+		s.logger.Error("Failed to save translation", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to save translation")
+	}
+
+	s.logger.Info("Document translated", "id", id, "language", target, "chunks", len(batches),
+		"prompt_tokens", version.PromptTokens, "completion_tokens", version.CompletionTokens)
+	return translateResponse(version, false, redactor.Counts()), nil
+}
+
+func translateResponse(version *models.TextVersion, cached bool, redacted map[string]int) *models.TranslateResponse {
+	downloads := map[string]string{}
+	for _, format := range []string{models.FormatTXT, models.FormatDOCX} {
+		downloads[format] = fmt.Sprintf("/api/v1/documents/%s/versions/%s/download?format=%s", version.DocumentID, version.ID, format)
+	}
+
+	return &models.TranslateResponse{
+		TextVersion: version,
+		Cached:      cached,
+		Downloads:   downloads,
+		Redacted:    redacted,
+	}
+}
+
+// translationParagraphs splits text at blank lines, keeping the line breaks
+// within each paragraph
+func translationParagraphs(text string) []string {
+	var paragraphs []string
+	for _, part := range paragraphBreak.Split(text, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			paragraphs = append(paragraphs, part)
+		}
+	}
+	return paragraphs
+}
+
+// translationBatches groups paragraphs into batches of at most size bytes.
+// A paragraph longer than size is cut into pieces, which are translated
+// separately and joined again with a space.
+func translationBatches(paragraphs []string, size int) [][]translationPiece {
+	var batches [][]translationPiece
+	var batch []translationPiece
+	length := 0
+
+	add := func(piece translationPiece) {
+		if len(batch) > 0 && length+len(piece.text) > size {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, piece)
+		length += len(piece.text)
+	}
+
+	for i, paragraph := range paragraphs {
+		if len(paragraph) <= size {
+			add(translationPiece{paragraph: i, text: paragraph})
+			continue
+		}
+		for _, chunk := range retrieval.Split(paragraph, size) {
+			add(translationPiece{paragraph: i, text: chunk.Text})
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func (s *documentService) ListTextVersions(ctx context.Context, id string) (*models.TextVersionListResponse, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	versions, err := s.repo.ListTextVersions(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list text versions", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve text versions")
+	}
+
+	return &models.TextVersionListResponse{
+		DocumentID: id,
+		Versions:   versions,
+	}, nil
+}
+
+// DownloadTextVersion renders a text version as a TXT or DOCX file named
+// after the document and the version's language, e.g. contract.fr.docx
+func (s *documentService) DownloadTextVersion(ctx context.Context, id, versionID, format string) (*models.FileDownload, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = models.FormatTXT
+	}
+	if format != models.FormatTXT && format != models.FormatDOCX {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Unknown format '%s'; use txt or docx", format))
+	}
+
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get document", "error", err, "id", id)
+		return nil, utils.NewInternalError("Failed to retrieve document")
+	}
+	if doc == nil {
+		return nil, utils.NewNotFoundError("Document not found")
+	}
+
+	version, err := s.repo.GetTextVersion(ctx, id, versionID)
+	if err != nil {
+		s.logger.Error("Failed to get text version", "error", err, "id", id, "version_id", versionID)
+		return nil, utils.NewInternalError("Failed to retrieve text version")
+	}
+	if version == nil {
+		return nil, utils.NewNotFoundError("Text version not found")
+	}
+
+	name := strings.TrimSuffix(doc.Filename, path.Ext(doc.Filename))
+	if name == "" {
+		name = doc.ID
+	}
+	download := &models.FileDownload{
+		Filename: fmt.Sprintf("%s.%s.%s", name, version.Language, format),
+	}
+
+	switch format {
+	case models.FormatDOCX:
+		var buf bytes.Buffer
+		if err := docx.Write(&buf, translationParagraphs(version.Text), version.Language); err != nil {
+			s.logger.Error("Failed to write DOCX", "error", err, "id", id, "version_id", versionID)
+			return nil, utils.NewInternalError("Failed to render text version")
+		}
+		download.ContentType = docx.ContentType
+		download.Data = buf.Bytes()
+	default:
+		download.ContentType = "text/plain; charset=utf-8"
+		download.Data = []byte(version.Text + "\n")
+	}
+	return download, nil
+}
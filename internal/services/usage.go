@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/BerylCAtieno/document-summarizer-api/internal/analyzer"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/models"
+	"github.com/BerylCAtieno/document-summarizer-api/internal/utils"
+)
+
+// analysisCost prices token usage with the configured table. It returns nil
+// for models without a price so unknown spend is not reported as free.
+func (s *documentService) analysisCost(model string, usage models.TokenUsage) *float64 {
+	if model == analyzer.BuiltinModel {
+		free := 0.0
+		return &free
+	}
+
+	price, ok := s.prices[model]
+	if !ok {
+		s.logger.Warn("No price configured for model, cost not recorded", "model", model)
+		return nil
+	}
+
+	cost := (float64(usage.PromptTokens)*price.PromptPerMillion +
+		float64(usage.CompletionTokens)*price.CompletionPerMillion) / 1_000_000
+	return &cost
+}
+
+// recordLLMCall stores the usage of a question, comparison or failed call
+// and returns its cost. The answer stands without the record, so a failure
+// is logged rather than returned.
+func (s *documentService) recordLLMCall(ctx context.Context, call *models.LLMCall, usage models.TokenUsage) *float64 {
+	call.ID = utils.GenerateID()
+	call.PromptTokens = usage.PromptTokens
+	call.CompletionTokens = usage.CompletionTokens
+	call.CostUSD = s.analysisCost(call.Model, usage)
+	call.CreatedAt = time.Now()
+
+	if err := s.repo.CreateLLMCall(ctx, call); err != nil {
+		s.logger.Error("Failed to record usage", "error", err, "id", call.DocumentID, "operation", call.Operation)
+	}
+	return call.CostUSD
+}
+
+// recordFailedUsage stores the tokens billed for calls that failed with
+// err, so the usage report covers what the provider charged for them
+func (s *documentService) recordFailedUsage(ctx context.Context, documentID, operation string, err error) {
+	for _, failed := range analyzer.FailedUsage(err) {
+		s.recordLLMCall(ctx, &models.LLMCall{
+			DocumentID: documentID,
+			Operation:  operation,
+			Model:      failed.Model,
+			Failed:     true,
+		}, failed.Usage)
+	}
+}
+
+func (s *documentService) GetUsage(ctx context.Context, filter models.UsageFilter) (*models.UsageResponse, error) {
+	buckets, err := s.repo.AggregateUsage(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to aggregate usage", "error", err)
+		return nil, utils.NewInternalError("Failed to retrieve usage")
+	}
+
+	resp := &models.UsageResponse{
+		From:    filter.From,
+		To:      filter.To,
+		ByDay:   []models.DailyUsage{},
+		ByModel: []models.ModelUsage{},
+	}
+
+	// Buckets arrive ordered by day, so days are appended in order while
+	// models are collected in first-seen order
+	modelIndex := map[string]int{}
+	for _, bucket := range buckets {
+		resp.Total.AddBucket(bucket)
+
+		if n := len(resp.ByDay); n == 0 || resp.ByDay[n-1].Date != bucket.Day {
+			resp.ByDay = append(resp.ByDay, models.DailyUsage{Date: bucket.Day})
+		}
+		resp.ByDay[len(resp.ByDay)-1].AddBucket(bucket)
+
+		i, ok := modelIndex[bucket.Model]
+		if !ok {
+			i = len(resp.ByModel)
+			modelIndex[bucket.Model] = i
+			resp.ByModel = append(resp.ByModel, models.ModelUsage{Model: bucket.Model})
+		}
+		resp.ByModel[i].AddBucket(bucket)
+	}
+
+	return resp, nil
+}
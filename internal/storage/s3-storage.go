@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/BerylCAtieno/document-summarizer-api/internal/config"
 
@@ -12,7 +13,7 @@ import (
 )
 
 type Storage interface {
-	Upload(ctx context.Context, key string, data []byte, contentType string) error
+	Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
 	Download(ctx context.Context, key string) ([]byte, error)
 	Delete(ctx context.Context, key string) error
 }
@@ -51,15 +52,14 @@ func NewS3Storage(cfg *config.Config) (Storage, error) {
 	}, nil
 }
 
-func (s *s3Storage) Upload(ctx context.Context, key string, data []byte, contentType string) error {
-	reader := bytes.NewReader(data)
-
+// Upload streams size bytes from r to the bucket without buffering the whole object
+func (s *s3Storage) Upload(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
 	_, err := s.client.PutObject(
 		ctx,
 		s.bucketName,
 		key,
-		reader,
-		int64(len(data)),
+		r,
+		size,
 		minio.PutObjectOptions{
 			ContentType: contentType,
 		},
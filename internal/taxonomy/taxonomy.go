@@ -0,0 +1,138 @@
+package taxonomy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Other is the bucket for documents that fit none of the types
+const Other = "other"
+
+// DefaultSpec is the taxonomy used when DOCUMENT_TYPES is not set
+const DefaultSpec = "invoice=bill,contract=agreement|lease|nda,cv=resume|curriculum vitae,report,letter,memo=memorandum,email=e-mail,form"
+
+// Taxonomy is the controlled set of document types. Free text from a
+// classifier is mapped onto it, so document_type can be filtered on.
+type Taxonomy struct {
+	types []string
+	// labels maps each type and alias to its type
+	labels map[string]string
+}
+
+var typePattern = regexp.MustCompile(`^[a-z][a-z0-9_\-]*$`)
+
+// Default returns the built-in taxonomy
+func Default() *Taxonomy {
+	t, err := Parse(DefaultSpec)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Parse reads a comma separated list of types, each optionally followed by
+// = and a |-separated list of aliases, e.g. "invoice=bill|receipt,report".
+// Other is always part of the taxonomy.
+func Parse(spec string) (*Taxonomy, error) {
+	t := &Taxonomy{labels: map[string]string{}}
+	add := func(label, docType string) error {
+		label = normalize(label)
+		if label == "" {
+			return nil
+		}
+		if existing, ok := t.labels[label]; ok && existing != docType {
+			return fmt.Errorf("%q is listed for both %s and %s", label, existing, docType)
+		}
+		t.labels[label] = docType
+		return nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, aliases, _ := strings.Cut(entry, "=")
+		docType := strings.ToLower(strings.TrimSpace(name))
+		if !typePattern.MatchString(docType) {
+			return nil, fmt.Errorf("invalid document type %q; use lowercase letters, digits, - and _", name)
+		}
+		if !slices.Contains(t.types, docType) {
+			t.types = append(t.types, docType)
+		}
+		if err := add(docType, docType); err != nil {
+			return nil, err
+		}
+		for _, alias := range strings.Split(aliases, "|") {
+			if err := add(alias, docType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !slices.Contains(t.types, Other) {
+		t.types = append(t.types, Other)
+		t.labels[Other] = Other
+	}
+	return t, nil
+}
+
+// Types returns the types in the order they were listed, with other last
+// unless it was listed explicitly
+func (t *Taxonomy) Types() []string {
+	return t.types
+}
+
+// Has reports whether docType is one of the types
+func (t *Taxonomy) Has(docType string) bool {
+	return slices.Contains(t.types, docType)
+}
+
+// Map returns the type a classifier label stands for and whether it was
+// recognized. Labels are matched case-insensitively on the type, its
+// aliases and their plurals, then on the longest type or alias contained in
+// the label as whole words, so "Tax Invoice" and "employment contract" are
+// found. Anything else is Other.
+func (t *Taxonomy) Map(label string) (string, bool) {
+	label = normalize(label)
+	if label == "" {
+		return Other, false
+	}
+	if docType, ok := t.lookup(label); ok {
+		return docType, true
+	}
+
+	words := strings.Fields(label)
+	for n := len(words) - 1; n > 0; n-- {
+		// Prefer matches at the end, where English puts the head noun
+		for start := len(words) - n; start >= 0; start-- {
+			if docType, ok := t.lookup(strings.Join(words[start:start+n], " ")); ok {
+				return docType, true
+			}
+		}
+	}
+	return Other, false
+}
+
+func (t *Taxonomy) lookup(label string) (string, bool) {
+	if docType, ok := t.labels[label]; ok {
+		return docType, true
+	}
+	if singular, ok := strings.CutSuffix(label, "s"); ok {
+		if docType, ok := t.labels[singular]; ok {
+			return docType, true
+		}
+	}
+	return "", false
+}
+
+var separators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalize lowercases a label and reduces punctuation, including the
+// hyphens and underscores of types such as purchase_order, to single spaces
+func normalize(label string) string {
+	return strings.TrimSpace(separators.ReplaceAllString(strings.ToLower(label), " "))
+}
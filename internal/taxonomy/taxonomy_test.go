@@ -0,0 +1,65 @@
+package taxonomy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	tax := Default()
+
+	tests := []struct {
+		label string
+		want  string
+		ok    bool
+	}{
+		{"invoice", "invoice", true},
+		{"Invoice", "invoice", true},
+		{"  INVOICES ", "invoice", true},
+		{"tax invoice", "invoice", true},
+		{"Resume", "cv", true},
+		{"curriculum-vitae", "cv", true},
+		{"employment agreement", "contract", true},
+		{"letter of intent", "letter", true},
+		{"E-mail", "email", true},
+		{"other", "other", true},
+		{"bank statement", "other", false},
+		{"approved", "other", false},
+		{"", "other", false},
+	}
+	for _, tt := range tests {
+		got, ok := tax.Map(tt.label)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Map(%q) = %q, %v; want %q, %v", tt.label, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tax, err := Parse("purchase_order=po|purchase order, Invoice = bill, invoice")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if got := strings.Join(tax.Types(), ","); got != "purchase_order,invoice,other" {
+		t.Errorf("types = %q, want purchase_order,invoice,other", got)
+	}
+	if got, _ := tax.Map("PO"); got != "purchase_order" {
+		t.Errorf("Map(PO) = %q, want purchase_order", got)
+	}
+	if got, _ := tax.Map("purchase_order"); got != "purchase_order" {
+		t.Errorf("Map(purchase_order) = %q, want purchase_order", got)
+	}
+	if got, ok := tax.Map("contract"); got != Other || ok {
+		t.Errorf("Map(contract) = %q, %v; want other, false", got, ok)
+	}
+	if !tax.Has("purchase_order") || tax.Has("contract") {
+		t.Error("Has does not match the listed types")
+	}
+
+	for _, spec := range []string{"Tax Invoice", "invoice=bill,contract=bill"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
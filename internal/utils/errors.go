@@ -38,3 +38,24 @@ func NewUnauthorizedError(message string) *AppError {
 		Message:    message,
 	}
 }
+
+func NewServiceUnavailableError(message string) *AppError {
+	return &AppError{
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    message,
+	}
+}
+
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		StatusCode: http.StatusConflict,
+		Message:    message,
+	}
+}
+
+func NewUnprocessableEntityError(message string) *AppError {
+	return &AppError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    message,
+	}
+}